@@ -0,0 +1,146 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrBulkUnsupported is returned by a BulkLoader that can not load a table,
+// such as when the connection does not support the bulk protocol. Copy and
+// FixtureLoader fall back to insert statements when a BulkLoader returns an
+// error matching it before reading any rows.
+var ErrBulkUnsupported = errors.New("bulk load not supported")
+
+// RowSource supplies rows to a BulkLoader. It has the same methods as
+// pgx.CopyFromSource, so it may be passed to pgx.Conn.CopyFrom directly.
+type RowSource interface {
+	// Next advances to the next row, returning false at the end or on error.
+	Next() bool
+	// Values returns the fields of the current row. They are only valid
+	// until the next call to Next.
+	Values() ([]any, error)
+	// Err returns the error, if any, that stopped the source.
+	Err() error
+}
+
+// BulkLoader inserts rows into a table with a driver specific bulk protocol,
+// such as COPY for Postgres or bulk copy for SQL Server, returning the number
+// of rows inserted.
+//
+// Copy and FixtureLoader use a BulkLoader when the Execer they are given also
+// implements it. Use BulkExecer to add one to an Execer.
+type BulkLoader interface {
+	BulkLoad(ctx context.Context, table string, columns []string, rows RowSource) (int64, error)
+}
+
+// BulkLoaderFunc adapts a function to a BulkLoader. With pgx:
+//
+//	loader := table.BulkLoaderFunc(func(ctx context.Context, name string, columns []string, rows table.RowSource) (int64, error) {
+//		return conn.CopyFrom(ctx, pgx.Identifier{name}, columns, rows)
+//	})
+type BulkLoaderFunc func(ctx context.Context, table string, columns []string, rows RowSource) (int64, error)
+
+func (fn BulkLoaderFunc) BulkLoad(ctx context.Context, table string, columns []string, rows RowSource) (int64, error) {
+	return fn(ctx, table, columns, rows)
+}
+
+// BulkExecer returns an Execer that also implements BulkLoader with bl.
+func BulkExecer(e Execer, bl BulkLoader) Execer {
+	return bulkExecer{Execer: e, BulkLoader: bl}
+}
+
+type bulkExecer struct {
+	Execer
+	BulkLoader
+}
+
+// StatementLoader returns a BulkLoader for drivers that bulk load by running
+// a prepared statement once per row, then once without arguments, in a
+// transaction. copyIn returns the statement text for a table and columns.
+// With lib/pq and go-mssqldb:
+//
+//	loader := table.StatementLoader(db, pq.CopyIn)
+//	loader := table.StatementLoader(db, func(name string, columns ...string) string {
+//		return mssql.CopyIn(name, mssql.BulkOptions{}, columns...)
+//	})
+func StatementLoader(db *sql.DB, copyIn func(table string, columns ...string) string) BulkLoader {
+	return BulkLoaderFunc(func(ctx context.Context, table string, columns []string, rows RowSource) (int64, error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+
+		stmt, err := tx.PrepareContext(ctx, copyIn(table, columns...))
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+
+		var n int64
+		for rows.Next() {
+			v, err := rows.Values()
+			if err != nil {
+				return 0, err
+			}
+			if _, err := stmt.ExecContext(ctx, v...); err != nil {
+				return 0, rowError(int(n), err)
+			}
+			n++
+		}
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			return 0, err
+		}
+		if err := stmt.Close(); err != nil {
+			return 0, err
+		}
+		return n, tx.Commit()
+	})
+}
+
+// bulkLoad loads the rows with e if it implements BulkLoader. It reports
+// false if e does not, or if it returned ErrBulkUnsupported without
+// advancing past the first row.
+func bulkLoad(ctx context.Context, e Execer, table string, columns []string, rows *restartSource) (int64, bool, error) {
+	bl, ok := e.(BulkLoader)
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := bl.BulkLoad(ctx, table, columns, rows)
+	if errors.Is(err, ErrBulkUnsupported) && !rows.advanced {
+		return 0, false, nil
+	}
+	return n, true, err
+}
+
+// restartSource is a RowSource positioned before a row that has already been
+// read, so the row is still available if a BulkLoader stops without
+// advancing past it.
+type restartSource struct {
+	next     func() bool
+	values   func() []any
+	err      func() error
+	pending  bool // The current row has not been returned by Next yet.
+	advanced bool
+}
+
+func (rs *restartSource) Next() bool {
+	if rs.pending {
+		rs.pending = false
+		return true
+	}
+	rs.advanced = true
+	return rs.next()
+}
+
+func (rs *restartSource) Values() ([]any, error) {
+	return rs.values(), nil
+}
+
+func (rs *restartSource) Err() error {
+	return rs.err()
+}
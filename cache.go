@@ -0,0 +1,521 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores query results for CachedQueryer.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key, if present and not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the value for key until ttl has passed.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value for key, if present.
+	Delete(key string)
+}
+
+// MemoryCache is an in-process Cache. Expired entries are removed when read,
+// and by Set once the number of entries has doubled since they were last
+// removed, so entries that are never read again do not grow it without bound.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+	prune   int // Size at which Set removes expired entries.
+}
+
+// minPrune is the smallest size at which MemoryCache removes expired entries.
+const minPrune = 64
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+		prune:   minPrune,
+	}
+}
+
+func (mc *MemoryCache) Get(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	e, ok := mc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !mc.now().Before(e.expires) {
+		delete(mc.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (mc *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	now := mc.now()
+	if len(mc.entries) >= mc.prune {
+		for k, e := range mc.entries {
+			if !now.Before(e.expires) {
+				delete(mc.entries, k)
+			}
+		}
+		mc.prune = max(2*len(mc.entries), minPrune)
+	}
+	mc.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+}
+
+func (mc *MemoryCache) Delete(key string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	delete(mc.entries, key)
+}
+
+// CachedQueryer returns a QueryCache that serves repeated queries from cache.
+//
+// Queries are keyed on the SQL text, with runs of white space collapsed, and
+// the parameters, as by CacheKey. On a miss every result set is read from q and stored in the
+// cache for ttl as snapshots. Both hits and misses return rows read from the
+// stored result, with the original database type names, so fill options and
+// type converters apply as usual. Values must be types supported by
+// WriteSnapshot, which includes every driver.Value type.
+func CachedQueryer(q Queryer, cache Cache, ttl time.Duration, opts ...CacheOption) *QueryCache {
+	qc := &QueryCache{
+		q:       q,
+		cache:   cache,
+		ttl:     ttl,
+		now:     time.Now,
+		tags:    make(map[string]uint64),
+		loading: make(map[string]bool),
+	}
+	for _, o := range opts {
+		o(qc)
+	}
+	return qc
+}
+
+// CacheOption configures a QueryCache.
+type CacheOption func(*QueryCache)
+
+// StaleWhileRevalidate keeps entries for d after they expire. A query that
+// finds an expired entry is answered from it while the entry is refreshed in
+// the background. Invalidated entries are never served.
+func StaleWhileRevalidate(d time.Duration) CacheOption {
+	return func(qc *QueryCache) {
+		qc.stale = d
+	}
+}
+
+// CacheTags attaches tags, such as the names of the tables read, to the cache
+// entry of a query run through a QueryCache. QueryCache.InvalidateTags removes
+// every entry with any of the tags.
+func CacheTags(tags ...string) Option {
+	return func(c *fillConfig) {
+		c.cacheTags = append(c.cacheTags, tags...)
+	}
+}
+
+// QueryCache is a Queryer that serves repeated queries from a Cache.
+// It is safe for concurrent use.
+//
+// Invalidation by tag and Flush are recorded in the QueryCache, so they do not
+// apply to other QueryCache values sharing the same Cache.
+type QueryCache struct {
+	q     Queryer
+	cache Cache
+	ttl   time.Duration
+	stale time.Duration
+	now   func() time.Time
+
+	mu         sync.Mutex
+	generation uint64            // Incremented by Flush.
+	tags       map[string]uint64 // Incremented by InvalidateTags.
+	loading    map[string]bool   // Keys being refreshed in the background.
+}
+
+func (qc *QueryCache) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	key := CacheKey(text, params...)
+	if len(key) == 0 {
+		return qc.q.QueryContext(ctx, text, params...)
+	}
+	var tags []string
+	if qi, ok := QueryInfoFrom(ctx); ok {
+		tags = qi.c.cacheTags
+	}
+
+	var cs *cachedSet
+	if value, ok := qc.cache.Get(key); ok {
+		entry, err := decodeCacheEntry(value)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !qc.current(entry):
+			qc.cache.Delete(key)
+		case qc.now().Before(entry.fresh):
+			cs = entry.set
+		case qc.stale > 0:
+			cs = entry.set
+			qc.refresh(ctx, key, tags, text, params)
+		}
+	}
+	if cs == nil {
+		var err error
+		cs, err = qc.load(ctx, key, tags, text, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cacheDB().QueryContext(ctx, "", cs)
+}
+
+// Invalidate removes the entry for a key returned by CacheKey.
+func (qc *QueryCache) Invalidate(key string) {
+	qc.cache.Delete(key)
+}
+
+// InvalidateTags invalidates every entry stored with any of the tags.
+func (qc *QueryCache) InvalidateTags(tags ...string) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	for _, t := range tags {
+		qc.tags[t]++
+	}
+}
+
+// Flush invalidates every entry stored by the QueryCache.
+func (qc *QueryCache) Flush() {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	qc.generation++
+}
+
+// versions returns the current generation and tag versions to store with
+// a new entry.
+func (qc *QueryCache) versions(tags []string) (uint64, []uint64) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	tv := make([]uint64, len(tags))
+	for i, t := range tags {
+		tv[i] = qc.tags[t]
+	}
+	return qc.generation, tv
+}
+
+// current reports if the entry has not been invalidated since it was stored.
+func (qc *QueryCache) current(e *cacheEntry) bool {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	if e.generation != qc.generation {
+		return false
+	}
+	for i, t := range e.tags {
+		if e.tagVersions[i] != qc.tags[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// load reads every result set of the query and stores it.
+// Versions are taken before the query runs, so an invalidation during the
+// query also invalidates the result.
+func (qc *QueryCache) load(ctx context.Context, key string, tags []string, text string, params []any) (*cachedSet, error) {
+	e := &cacheEntry{
+		fresh: qc.now().Add(qc.ttl),
+		tags:  tags,
+	}
+	e.generation, e.tagVersions = qc.versions(tags)
+
+	rows, err := qc.q.QueryContext(ctx, text, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set, err := FillSetOpt(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	e.set = newCachedSet(set)
+	value, err := encodeCacheEntry(e)
+	if err != nil {
+		return nil, err
+	}
+	qc.cache.Set(key, value, qc.ttl+qc.stale)
+	return e.set, nil
+}
+
+// refresh loads the query in the background, unless it is already loading.
+// The refresh is not canceled with ctx and is not reported to the QueryInfo
+// of the query that started it.
+func (qc *QueryCache) refresh(ctx context.Context, key string, tags []string, text string, params []any) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	if qc.loading[key] {
+		return
+	}
+	qc.loading[key] = true
+	ctx = context.WithValue(context.WithoutCancel(ctx), queryInfoKey{}, nil)
+	go func() {
+		qc.load(ctx, key, tags, text, params)
+
+		qc.mu.Lock()
+		defer qc.mu.Unlock()
+		delete(qc.loading, key)
+	}()
+}
+
+// CacheKey returns the key used by CachedQueryer for a query. Parameters are
+// converted as database/sql does by default, calling driver.Valuer and
+// following pointers, then encoded by type and value, so the key changes when
+// the value a pointer refers to changes. It returns an empty string if a
+// parameter can not be converted, such as a struct or sql.Out; such queries
+// are not cached.
+func CacheKey(text string, params ...any) string {
+	field := make([]any, 0, 1+2*len(params))
+	field = append(field, strings.Join(strings.Fields(text), " "))
+	for _, p := range params {
+		var name string
+		if na, ok := p.(sql.NamedArg); ok {
+			name, p = na.Name, na.Value
+		}
+		v, err := driver.DefaultParameterConverter.ConvertValue(p)
+		if err != nil {
+			return ""
+		}
+		field = append(field, name, v)
+	}
+	all := make([]int, len(field))
+	for i := range all {
+		all[i] = i
+	}
+	k, err := newKeyEncoder().key(field, all)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(k))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedSet is the result of a cached query.
+type cachedSet struct {
+	buffers []*Buffer
+	types   [][]string // Database type names for each buffer column.
+}
+
+func newCachedSet(set Set) *cachedSet {
+	cs := &cachedSet{buffers: set}
+	for _, b := range set {
+		types := make([]string, len(b.columnTypes))
+		for i, ct := range b.columnTypes {
+			if ct != nil {
+				types[i] = ct.DatabaseTypeName()
+			}
+		}
+		cs.types = append(cs.types, types)
+	}
+	return cs
+}
+
+// cacheEntry is a cache value.
+type cacheEntry struct {
+	fresh       time.Time // Expiry, not including any stale period.
+	generation  uint64
+	tags        []string
+	tagVersions []uint64
+	set         *cachedSet
+}
+
+// encodeCacheEntry writes the entry header, then the number of buffers,
+// then for each buffer its column database type names followed by its snapshot.
+func encodeCacheEntry(e *cacheEntry) ([]byte, error) {
+	var out []byte
+	putString := func(s string) {
+		out = binary.AppendUvarint(out, uint64(len(s)))
+		out = append(out, s...)
+	}
+	out = binary.AppendVarint(out, e.fresh.UnixNano())
+	out = binary.AppendUvarint(out, e.generation)
+	out = binary.AppendUvarint(out, uint64(len(e.tags)))
+	for i, t := range e.tags {
+		putString(t)
+		out = binary.AppendUvarint(out, e.tagVersions[i])
+	}
+	out = binary.AppendUvarint(out, uint64(len(e.set.buffers)))
+	for i, b := range e.set.buffers {
+		out = binary.AppendUvarint(out, uint64(len(e.set.types[i])))
+		for _, name := range e.set.types[i] {
+			putString(name)
+		}
+		w := bytes.NewBuffer(out)
+		if err := b.WriteSnapshot(w); err != nil {
+			return nil, err
+		}
+		out = w.Bytes()
+	}
+	return out, nil
+}
+
+func decodeCacheEntry(value []byte) (*cacheEntry, error) {
+	r := bytes.NewReader(value)
+	var bad bool
+	number := func() uint64 {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			bad = true
+		}
+		return n
+	}
+	count := func() int {
+		n := number()
+		if n > uint64(r.Len()) {
+			bad = true
+			return 0
+		}
+		return int(n)
+	}
+	getString := func() string {
+		b := make([]byte, count())
+		io.ReadFull(r, b)
+		return string(b)
+	}
+	errBad := fmt.Errorf("%w: bad cache value", ErrSnapshotFormat)
+
+	fresh, err := binary.ReadVarint(r)
+	if err != nil {
+		return nil, errBad
+	}
+	e := &cacheEntry{
+		fresh:      time.Unix(0, fresh),
+		generation: number(),
+		set:        &cachedSet{},
+	}
+	for n := count(); n > 0; n-- {
+		e.tags = append(e.tags, getString())
+		e.tagVersions = append(e.tagVersions, number())
+	}
+	for n := count(); n > 0; n-- {
+		types := make([]string, count())
+		for i := range types {
+			types[i] = getString()
+		}
+		if bad {
+			return nil, errBad
+		}
+		b, err := ReadSnapshot(r)
+		if err != nil {
+			return nil, err
+		}
+		e.set.buffers = append(e.set.buffers, b)
+		e.set.types = append(e.set.types, types)
+	}
+	if bad {
+		return nil, errBad
+	}
+	return e, nil
+}
+
+// cacheDB returns the database serving a *cachedSet, passed as the only
+// query parameter, as rows. It is opened on first use, so importing the
+// package does not start the database/sql connection opener.
+var cacheDB = sync.OnceValue(func() *sql.DB {
+	return sql.OpenDB(cacheConnector{})
+})
+
+type cacheConnector struct{}
+
+func (cacheConnector) Connect(context.Context) (driver.Conn, error) { return cacheConn{}, nil }
+func (cacheConnector) Driver() driver.Driver                        { return cacheDriver{} }
+
+type cacheDriver struct{}
+
+func (cacheDriver) Open(string) (driver.Conn, error) { return cacheConn{}, nil }
+
+type cacheConn struct{}
+
+var errCacheConn = errors.New("cache connection only serves cached rows")
+
+func (cacheConn) Prepare(string) (driver.Stmt, error) { return nil, errCacheConn }
+func (cacheConn) Close() error                        { return nil }
+func (cacheConn) Begin() (driver.Tx, error)           { return nil, errCacheConn }
+
+func (cacheConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (cacheConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 1 {
+		return nil, errCacheConn
+	}
+	cs, ok := args[0].Value.(*cachedSet)
+	if !ok {
+		return nil, errCacheConn
+	}
+	return &cacheRows{cs: cs}, nil
+}
+
+type cacheRows struct {
+	cs  *cachedSet
+	set int
+	row int
+}
+
+func (cr *cacheRows) Columns() []string { return cr.cs.buffers[cr.set].Columns }
+func (cr *cacheRows) Close() error      { return nil }
+
+func (cr *cacheRows) Next(dest []driver.Value) error {
+	b := cr.cs.buffers[cr.set]
+	if cr.row >= len(b.Rows) {
+		return io.EOF
+	}
+	for i, v := range b.Rows[cr.row].Field {
+		dest[i] = v
+	}
+	cr.row++
+	return nil
+}
+
+func (cr *cacheRows) HasNextResultSet() bool {
+	return cr.set+1 < len(cr.cs.buffers)
+}
+
+func (cr *cacheRows) NextResultSet() error {
+	if !cr.HasNextResultSet() {
+		return io.EOF
+	}
+	cr.set++
+	cr.row = 0
+	return nil
+}
+
+func (cr *cacheRows) ColumnTypeDatabaseTypeName(i int) string {
+	if types := cr.cs.types[cr.set]; i < len(types) {
+		return types[i]
+	}
+	return ""
+}
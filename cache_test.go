@@ -0,0 +1,421 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedQueryer(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls++
+		return []fakeResult{
+			{
+				Columns: []string{"ID", "Name"},
+				Types:   []string{"INT", "TEXT"},
+				Rows:    [][]driver.Value{{int64(1), "a"}, {args[0].Value, nil}},
+			},
+			{
+				Columns: []string{"At"},
+				Rows:    [][]driver.Value{{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}},
+			},
+		}, nil
+	})
+	now := time.Unix(0, 0)
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+	q := CachedQueryer(db, cache, time.Minute)
+
+	list := []struct {
+		Name  string
+		SQL   string
+		Param any
+		After time.Duration // Advance the clock before the query.
+		Calls int
+	}{
+		{Name: "miss", SQL: "select ID, Name", Param: int64(2), Calls: 1},
+		{Name: "hit", SQL: "select ID, Name", Param: int64(2), Calls: 1},
+		{Name: "whitespace", SQL: "select\n\tID,  Name ", Param: int64(2), Calls: 1},
+		{Name: "param", SQL: "select ID, Name", Param: int64(3), Calls: 2},
+		{Name: "param-type", SQL: "select ID, Name", Param: "3", Calls: 3},
+		{Name: "expired", SQL: "select ID, Name", Param: int64(2), After: time.Minute, Calls: 4},
+	}
+	var want Set
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			now = now.Add(item.After)
+			set, err := NewSet(ctx, q, item.SQL, item.Param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := calls, item.Calls; g != w {
+				t.Fatalf("database calls got %d want %d", g, w)
+			}
+			if len(set) != 2 {
+				t.Fatalf("expected 2 result sets, got %d", len(set))
+			}
+			if g, w := set[0].Schema()[0].DatabaseTypeName, "INT"; g != w {
+				t.Fatalf("type name got %q want %q", g, w)
+			}
+			if g, w := set[0].Rows[1].Field[0], item.Param; g != w {
+				t.Fatalf("param field got %#v want %#v", g, w)
+			}
+			if item.Name == "miss" {
+				want = set
+				return
+			}
+			if item.Name == "hit" && !reflect.DeepEqual(set[1].Rows[0].Field, want[1].Rows[0].Field) {
+				t.Fatalf("cached row got %v want %v", set[1].Rows[0].Field, want[1].Rows[0].Field)
+			}
+		})
+	}
+}
+
+type testValuer string
+
+func (v testValuer) Value() (driver.Value, error) { return "v:" + string(v), nil }
+
+func TestMemoryCachePrune(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+
+	for i := 1; i < minPrune; i++ {
+		cache.Set(fmt.Sprint("old", i), nil, time.Minute)
+	}
+	cache.Set("kept", nil, time.Hour)
+	now = now.Add(2 * time.Minute)
+	cache.Set("new", nil, time.Minute)
+	if g, w := len(cache.entries), 2; g != w {
+		t.Fatalf("expected %d entries after pruning, got %d", w, g)
+	}
+	if _, ok := cache.Get("kept"); !ok {
+		t.Fatal("expected unexpired entry to be kept")
+	}
+	if g, w := cache.prune, minPrune; g != w {
+		t.Fatalf("expected next prune at %d, got %d", w, g)
+	}
+}
+
+func TestCacheConnArgs(t *testing.T) {
+	ctx := context.Background()
+	for _, args := range [][]any{nil, {1}, {&cachedSet{}, 2}} {
+		_, err := cacheDB().QueryContext(ctx, "", args...)
+		if !errors.Is(err, errCacheConn) {
+			t.Fatalf("args %v: expected error: %v, got error: %v", args, errCacheConn, err)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	id := int64(1)
+	other := int64(1)
+	list := []struct {
+		Name  string
+		A, B  []any
+		Equal bool
+	}{
+		{Name: "param-split", A: []any{"a\x00int64:1"}, B: []any{"a", int64(1)}},
+		{Name: "string-int", A: []any{"1"}, B: []any{int64(1)}},
+		{Name: "name", A: []any{sql.Named("A", int64(1))}, B: []any{sql.Named("B", int64(1))}},
+		{Name: "named-positional", A: []any{sql.Named("A", int64(1))}, B: []any{int64(1)}},
+		{Name: "int-kind", A: []any{1}, B: []any{int64(1)}, Equal: true},
+		{Name: "pointer", A: []any{&id}, B: []any{&other}, Equal: true},
+		{Name: "pointer-value", A: []any{&id}, B: []any{int64(1)}, Equal: true},
+		{Name: "valuer", A: []any{testValuer("x")}, B: []any{"v:x"}, Equal: true},
+		{Name: "nil-pointer", A: []any{(*int64)(nil)}, B: []any{nil}, Equal: true},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			a, b := CacheKey("select ?", item.A...), CacheKey("select ?", item.B...)
+			if g, w := a == b, item.Equal; g != w {
+				t.Fatalf("keys equal got %t want %t", g, w)
+			}
+			if len(a) == 0 || len(b) == 0 {
+				t.Fatal("expected keys")
+			}
+		})
+	}
+	if k := CacheKey("select ?", struct{ A int }{1}); len(k) != 0 {
+		t.Fatalf("expected no key for a struct parameter, got %q", k)
+	}
+}
+
+func TestCachedQueryerParams(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls++
+		v, err := driver.DefaultParameterConverter.ConvertValue(args[0].Value)
+		return []fakeResult{{Columns: []string{"N"}, Rows: [][]driver.Value{{v}}}}, err
+	})
+	q := CachedQueryer(db, NewMemoryCache(), time.Minute)
+
+	id := int64(1)
+	for _, want := range []int64{1, 2} {
+		id = want
+		v, err := NewScaler(ctx, q, "select N", &id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != want {
+			t.Fatalf("got %v, want %d", v, want)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("database calls got %d want 2", calls)
+	}
+
+	// Parameters that can not be encoded are not cached.
+	type S struct{ A int }
+	db = openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls++
+		return []fakeResult{{Columns: []string{"N"}, Rows: [][]driver.Value{{int64(1)}}}}, nil
+	})
+	q = CachedQueryer(db, NewMemoryCache(), time.Minute)
+	calls = 0
+	for i := 0; i < 2; i++ {
+		if _, err := NewScaler(ctx, q, "select N", S{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("database calls got %d want 2", calls)
+	}
+}
+
+func TestQueryCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls++
+		return []fakeResult{{Columns: []string{"N"}, Rows: [][]driver.Value{{int64(calls)}}}}, nil
+	})
+	run := func(q Queryer, text string, tags ...string) int64 {
+		t.Helper()
+		buf, err := NewBuffer(ctx, q, text, CacheTags(tags...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.Rows[0].Field[0].(int64)
+	}
+
+	list := []struct {
+		Name  string
+		Run   func(qc *QueryCache) int64
+		Calls int
+	}{
+		{
+			Name: "key",
+			Run: func(qc *QueryCache) int64 {
+				run(qc, "select 1")
+				run(qc, "select 2")
+				qc.Invalidate(CacheKey("select 1"))
+				run(qc, "select 2")
+				return run(qc, "select 1")
+			},
+			Calls: 3,
+		},
+		{
+			Name: "tags",
+			Run: func(qc *QueryCache) int64 {
+				run(qc, "select 1", "a")
+				run(qc, "select 2", "a", "b")
+				run(qc, "select 3", "c")
+				qc.InvalidateTags("b", "d")
+				run(qc, "select 1", "a")
+				run(qc, "select 3", "c")
+				return run(qc, "select 2", "a", "b")
+			},
+			Calls: 4,
+		},
+		{
+			Name: "flush",
+			Run: func(qc *QueryCache) int64 {
+				run(qc, "select 1")
+				run(qc, "select 2", "a")
+				qc.Flush()
+				run(qc, "select 2", "a")
+				return run(qc, "select 1")
+			},
+			Calls: 4,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			calls = 0
+			qc := CachedQueryer(db, NewMemoryCache(), time.Minute)
+			if g, w := item.Run(qc), int64(item.Calls); g != w {
+				t.Fatalf("last result got %d want %d", g, w)
+			}
+			if g, w := calls, item.Calls; g != w {
+				t.Fatalf("database calls got %d want %d", g, w)
+			}
+		})
+	}
+}
+
+func TestQueryCacheStale(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	refreshed := make(chan struct{}, 1)
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls++
+		if calls > 1 {
+			defer func() { refreshed <- struct{}{} }()
+		}
+		return []fakeResult{{Columns: []string{"N"}, Rows: [][]driver.Value{{int64(calls)}}}}, nil
+	})
+	now := time.Unix(0, 0)
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+	qc := CachedQueryer(db, cache, time.Minute, StaleWhileRevalidate(time.Hour))
+	qc.now = cache.now
+
+	get := func() int64 {
+		t.Helper()
+		buf, err := NewBuffer(ctx, qc, "select N")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.Rows[0].Field[0].(int64)
+	}
+	if g := get(); g != 1 {
+		t.Fatalf("first query got %d want 1", g)
+	}
+	now = now.Add(2 * time.Minute)
+	if g := get(); g != 1 {
+		t.Fatalf("stale query got %d want 1", g)
+	}
+	<-refreshed
+	for {
+		qc.mu.Lock()
+		loading := len(qc.loading)
+		qc.mu.Unlock()
+		if loading == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if g := get(); g != 2 {
+		t.Fatalf("refreshed query got %d want 2", g)
+	}
+}
+
+func TestSharedQueryer(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls.Add(1)
+		<-release
+		return []fakeResult{{Columns: []string{"N"}, Rows: [][]driver.Value{{args[0].Value}}}}, nil
+	})
+	q := SharedQueryer(db)
+
+	list := []struct {
+		Param int64
+	}{{1}, {1}, {1}, {2}, {2}}
+	results := make([]int64, len(list))
+	var wg sync.WaitGroup
+	for i, item := range list {
+		wg.Add(1)
+		go func(i int, param int64) {
+			defer wg.Done()
+			buf, err := NewBuffer(ctx, q, "select N", param)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = buf.Rows[0].Field[0].(int64)
+		}(i, item.Param)
+	}
+	for calls.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if g, w := calls.Load(), int32(2); g != w {
+		t.Fatalf("database calls got %d want %d", g, w)
+	}
+	for i, item := range list {
+		if results[i] != item.Param {
+			t.Fatalf("result %d got %d want %d", i, results[i], item.Param)
+		}
+	}
+}
+
+func TestSharedQueryerKey(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		calls.Add(1)
+		<-release
+		var values []any
+		for _, a := range args {
+			v, err := driver.DefaultParameterConverter.ConvertValue(a.Value)
+			if err != nil {
+				v = fmt.Sprint(a.Value)
+			}
+			values = append(values, v)
+		}
+		return []fakeResult{{Columns: []string{"P"}, Rows: [][]driver.Value{{fmt.Sprintf("%#v", values)}}}}, nil
+	})
+	q := SharedQueryer(db)
+
+	a, b := int64(1), int64(2)
+	type S struct{ A int }
+	list := []struct {
+		Params []any
+		Want   string
+	}{
+		{[]any{"a\x00int64:1"}, `[]interface {}{"a\x00int64:1"}`},
+		{[]any{"a", int64(1)}, `[]interface {}{"a", 1}`},
+		{[]any{&a}, `[]interface {}{1}`},
+		{[]any{&b}, `[]interface {}{2}`},
+		{[]any{S{1}}, `[]interface {}{"{1}"}`},
+		{[]any{S{1}}, `[]interface {}{"{1}"}`},
+	}
+	results := make([]string, len(list))
+	var wg sync.WaitGroup
+	for i, item := range list {
+		wg.Add(1)
+		go func(i int, params []any) {
+			defer wg.Done()
+			v, err := NewScaler(ctx, q, "select P", params...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = v.(string)
+		}(i, item.Params)
+	}
+	// Every query runs on its own, so wait for all of them to reach the database.
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < int32(len(list)) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if g, w := calls.Load(), int32(len(list)); g != w {
+		t.Fatalf("database calls got %d want %d", g, w)
+	}
+	for i, item := range list {
+		if results[i] != item.Want {
+			t.Fatalf("result %d got %s want %s", i, results[i], item.Want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package table
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Canonical writes the snapshot in a canonical form, so buffers holding
+// semantically equal values always write identical bytes, such as for content
+// addressed caching or golden files. Before writing, each value is normalized:
+//
+//   - Other integer and float kinds become int64 and float64. An unsigned
+//     value larger then math.MaxInt64 is an error.
+//   - Negative zero becomes zero and every NaN the same NaN.
+//   - Times are converted to UTC, dropping the zone and monotonic reading.
+//   - NULL is always written the same way: a nil []byte, a nil pointer, and a
+//     driver.Valuer returning nil, such as an invalid sql.NullString, are nil.
+//   - A driver.Valuer is replaced by its value and a non-nil pointer by the
+//     value it points to.
+//
+// Values of different types, such as int64(1) and float64(1), remain distinct.
+// Row order is kept; use SortColumns to also ignore column order.
+func Canonical() SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.canonical = true
+	}
+}
+
+// SortColumns writes the columns of the snapshot sorted by name, so buffers
+// with the same columns in a different order write identical bytes.
+func SortColumns() SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.sortColumns = true
+	}
+}
+
+// sortedColumns returns the column names sorted and the index of each in columns.
+func sortedColumns(columns []string) ([]string, []int) {
+	order := make([]int, len(columns))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return columns[order[i]] < columns[order[j]]
+	})
+	sorted := make([]string, len(columns))
+	for i, ci := range order {
+		sorted[i] = columns[ci]
+	}
+	return sorted, order
+}
+
+// canonicalFields fills field from the row, in column order if set,
+// normalizing each value if canonical is set.
+func canonicalFields(field []any, columns []string, order []int, canonical bool, ri int, row Row) ([]any, error) {
+	if len(row.Field) != len(columns) {
+		return nil, fmt.Errorf("row %d has %d fields, expected %d", ri, len(row.Field), len(columns))
+	}
+	field = append(field[:0], row.Field...)
+	if order != nil {
+		for i, ci := range order {
+			field[i] = row.Field[ci]
+		}
+	}
+	if !canonical {
+		return field, nil
+	}
+	for i, v := range field {
+		cv, err := canonicalValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("row %d, column %q: %w", ri, columns[i], err)
+		}
+		field[i] = cv
+	}
+	return field, nil
+}
+
+// canonicalValue returns the normalized value of v, as described by Canonical.
+// Unsupported types are returned unchanged.
+func canonicalValue(v any) (any, error) {
+	if vr, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		dv, err := vr.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = dv
+	}
+	switch v := v.(type) {
+	case nil, int64, bool, string:
+		return v, nil
+	case float64:
+		return canonicalFloat(v), nil
+	case []byte:
+		if v == nil {
+			return nil, nil
+		}
+		return v, nil
+	case time.Time:
+		return v.UTC(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return canonicalValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return nil, fmt.Errorf("%T value %d overflows int64", v, u)
+		}
+		return int64(u), nil
+	case reflect.Float32, reflect.Float64:
+		return canonicalFloat(rv.Float()), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	}
+	return v, nil
+}
+
+func canonicalFloat(f float64) float64 {
+	switch {
+	case f == 0:
+		return 0
+	case math.IsNaN(f):
+		return math.NaN()
+	}
+	return f
+}
@@ -0,0 +1,62 @@
+package table
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCanonical(t *testing.T) {
+	ts := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	name := "R1"
+	a := &Buffer{
+		Columns: []string{"ID", "Name", "Price", "At", "Data"},
+	}
+	a.AddRow([]any{int64(1), "R1", float64(0), ts, nil})
+	a.AddRow([]any{int64(2), nil, math.NaN(), nil, []byte("x")})
+	b := &Buffer{
+		Columns: []string{"At", "Data", "ID", "Name", "Price"},
+	}
+	b.AddRow([]any{ts.In(time.FixedZone("", 3600)), []byte(nil), int32(1), &name, math.Copysign(0, -1)})
+	b.AddRow([]any{sql.NullTime{}, []byte("x"), uint8(2), sql.NullString{}, float32(math.NaN())})
+
+	write := func(b *Buffer, opts ...SnapshotOption) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := b.WriteSnapshot(&buf, opts...); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	ca := write(a, Canonical(), SortColumns())
+	cb := write(b, Canonical(), SortColumns())
+	if !bytes.Equal(ca, cb) {
+		t.Fatalf("canonical snapshots differ:\n%x\n%x", ca, cb)
+	}
+	if bytes.Equal(write(a, Canonical()), cb) {
+		t.Fatal("expected different snapshots without SortColumns")
+	}
+
+	got, err := ReadSnapshot(bytes.NewReader(cb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got.Columns), "[At Data ID Name Price]"; g != w {
+		t.Fatalf("columns got %s want %s", g, w)
+	}
+	if g, w := fmt.Sprintf("%#v", got.Rows[0].Field[2:]), `[]interface {}{1, "R1", 0}`; g != w {
+		t.Fatalf("row got %s want %s", g, w)
+	}
+
+	c := &Buffer{
+		Columns: []string{"ID"},
+	}
+	c.AddRow([]any{uint64(math.MaxUint64)})
+	err = c.WriteSnapshot(&bytes.Buffer{}, Canonical())
+	if g, w := fmt.Sprint(err), `row 0, column "ID": uint64 value 18446744073709551615 overflows int64`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+}
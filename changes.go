@@ -0,0 +1,189 @@
+package table
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ChangeKind is the operation recorded for a changed row.
+type ChangeKind byte
+
+const (
+	// ChangeInsert is a row added with AddRow or InsertRow.
+	ChangeInsert ChangeKind = iota + 1
+	// ChangeUpdate is a row with fields changed by Set.
+	ChangeUpdate
+	// ChangeDelete is a row removed with DeleteRow.
+	ChangeDelete
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	default:
+		return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+	case ChangeInsert:
+		return "insert"
+	case ChangeUpdate:
+		return "update"
+	case ChangeDelete:
+		return "delete"
+	}
+}
+
+// Change is a row inserted, updated, or deleted since change tracking began
+// or the changes were last accepted.
+type Change struct {
+	Kind ChangeKind
+	Row  Row // Current row, or the row as it was deleted.
+
+	// Original field values, before the first change. Nil for ChangeInsert.
+	Original []any
+	// Columns changed by Set, in column order. Only set for ChangeUpdate.
+	Columns []string
+}
+
+// changeLog records the changes to a buffer. A row is only considered
+// changed when its rowChange belongs to the current changeLog, so accepting
+// the changes only needs to start a new log.
+type changeLog struct {
+	deleted []Change
+}
+
+// rowChange is the change recorded for a single row.
+type rowChange struct {
+	log      *changeLog
+	kind     ChangeKind
+	original []any
+	changed  []bool // Columns changed by Set.
+}
+
+// inserted returns the change for a new row, or nil if changes are not tracked.
+func (l *changeLog) inserted() *rowChange {
+	if l == nil {
+		return nil
+	}
+	return &rowChange{log: l, kind: ChangeInsert}
+}
+
+// TrackChanges starts recording the rows changed by Set, AddRow, InsertRow,
+// and DeleteRow, so they can be read with Changes, such as to write the edits
+// back to the database. Changes made by other means, such as assigning to
+// Row.Field, are not recorded. Calling TrackChanges again has no effect.
+func (b *Buffer) TrackChanges() {
+	if b.changes == nil {
+		b.changes = &changeLog{}
+	}
+}
+
+// Changes returns the recorded changes: the deleted rows in the order they
+// were deleted, then the inserted and updated rows in row order.
+// It returns nil if changes are not tracked.
+func (b *Buffer) Changes() []Change {
+	l := b.changes
+	if l == nil {
+		return nil
+	}
+	changes := append([]Change(nil), l.deleted...)
+	for _, row := range b.Rows {
+		rc := row.change
+		if rc == nil || rc.log != l {
+			continue
+		}
+		c := Change{Kind: rc.kind, Row: row, Original: rc.original}
+		for i, changed := range rc.changed {
+			if changed && i < len(b.Columns) {
+				c.Columns = append(c.Columns, b.Columns[i])
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// AcceptChanges clears the recorded changes, such as after they have been
+// written to the database, and continues tracking from the current rows.
+func (b *Buffer) AcceptChanges() {
+	if b.changes != nil {
+		b.changes = &changeLog{}
+	}
+}
+
+// Set the field at the row index and named column.
+func (b *Buffer) Set(rowIndex int, columnName string, v any) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	ci, err := b.lookupColumn(columnName)
+	if err != nil {
+		return err
+	}
+	if rowIndex < 0 || rowIndex >= len(b.Rows) {
+		return &IndexError{subject: SubjectRow, length: len(b.Rows), requested: rowIndex}
+	}
+	b.unshare()
+	row := &b.Rows[rowIndex]
+	if l := b.changes; l != nil {
+		rc := row.change
+		if rc == nil || rc.log != l {
+			rc = &rowChange{
+				log:      l,
+				kind:     ChangeUpdate,
+				original: append([]any(nil), row.Field...),
+				changed:  make([]bool, len(row.Field)),
+			}
+			row.change = rc
+		}
+		if rc.kind == ChangeUpdate {
+			rc.changed[ci] = true
+		}
+	}
+	row.Field[ci] = v
+	return nil
+}
+
+// InsertRow inserts a new row before the row index. An index equal to the
+// number of rows adds the row at the end, like AddRow.
+func (b *Buffer) InsertRow(rowIndex int, row []any) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	if rowIndex < 0 || rowIndex > len(b.Rows) {
+		return &IndexError{subject: SubjectRow, length: len(b.Rows), requested: rowIndex}
+	}
+	if r, c := len(row), len(b.Columns); r != c {
+		return fmt.Errorf("row count %d is different then column schema count %d", r, c)
+	}
+	b.AddRow(row)
+	if rowIndex < len(b.Rows)-1 {
+		r := b.Rows[len(b.Rows)-1]
+		copy(b.Rows[rowIndex+1:], b.Rows[rowIndex:len(b.Rows)-1])
+		b.Rows[rowIndex] = r
+	}
+	return nil
+}
+
+// DeleteRow removes the row at the row index.
+func (b *Buffer) DeleteRow(rowIndex int) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	if rowIndex < 0 || rowIndex >= len(b.Rows) {
+		return &IndexError{subject: SubjectRow, length: len(b.Rows), requested: rowIndex}
+	}
+	row := b.Rows[rowIndex]
+	n := len(b.Rows) - 1
+	copy(b.Rows[rowIndex:], b.Rows[rowIndex+1:])
+	b.Rows[n] = Row{}
+	b.Rows = b.Rows[:n]
+	if l := b.changes; l != nil {
+		rc := row.change
+		switch {
+		case rc == nil || rc.log != l:
+			l.deleted = append(l.deleted, Change{Kind: ChangeDelete, Row: row, Original: row.Field})
+		case rc.kind == ChangeUpdate:
+			l.deleted = append(l.deleted, Change{Kind: ChangeDelete, Row: row, Original: rc.original})
+		}
+		// Deleting an inserted row leaves no change.
+	}
+	return nil
+}
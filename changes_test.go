@@ -0,0 +1,105 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrackChanges(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name", "Score"},
+	}
+	for i := int64(1); i <= 4; i++ {
+		b.AddRow([]any{i, fmt.Sprint("R", i), i * 10})
+	}
+	if b.Changes() != nil {
+		t.Fatal("expected no changes before tracking")
+	}
+	b.TrackChanges()
+
+	list := []struct {
+		Name  string
+		Edit  func() error
+		Want  string
+		Error string
+	}{
+		{
+			Name: "update",
+			Edit: func() error {
+				if err := b.Set(1, "Name", "Two"); err != nil {
+					return err
+				}
+				return b.Set(1, "Score", nil)
+			},
+			Want: "update [2 Two <nil>] from [2 R2 20] [Name Score]",
+		},
+		{
+			Name: "insert",
+			Edit: func() error {
+				if err := b.InsertRow(0, []any{int64(0), "R0", nil}); err != nil {
+					return err
+				}
+				b.AddRow([]any{int64(5), "R5", nil})
+				return b.Set(5, "Score", int64(50))
+			},
+			Want: "insert [0 R0 <nil>] from [] []; update [2 Two <nil>] from [2 R2 20] [Name Score]; insert [5 R5 50] from [] []",
+		},
+		{
+			Name: "delete",
+			Edit: func() error {
+				// Inserted row 0, unchanged row 1, then updated row 2 after the shift.
+				for _, i := range []int{0, 0, 0} {
+					if err := b.DeleteRow(i); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Want: "delete [1 R1 10] from [1 R1 10] []; delete [2 Two <nil>] from [2 R2 20] []; insert [5 R5 50] from [] []",
+		},
+		{
+			Name:  "bad row",
+			Edit:  func() error { return b.InsertRow(0, []any{int64(6)}) },
+			Error: "row count 1 is different then column schema count 3",
+		},
+		{
+			Name:  "bad index",
+			Edit:  func() error { return b.DeleteRow(3) },
+			Error: "Table has 3 rows, requested index 3",
+		},
+		{
+			Name: "accept",
+			Edit: func() error {
+				b.AcceptChanges()
+				return b.Set(0, "Score", int64(31))
+			},
+			Want: "update [3 R3 31] from [3 R3 30] [Score]",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			err := item.Edit()
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, c := range b.Changes() {
+				got = append(got, fmt.Sprint(c.Kind, " ", c.Row.Field, " from ", c.Original, " ", c.Columns))
+			}
+			if g, w := strings.Join(got, "; "), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+	if g, w := fmt.Sprint(b.Rows[0].Field, b.Rows[1].Field), "[3 R3 31] [4 R4 40]"; g != w {
+		t.Fatalf("got %s, want %s", g, w)
+	}
+}
@@ -0,0 +1,109 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// FillChunks reads rows into buffers of at most chunkSize rows and passes each
+// to fn. The buffer and its rows are reused for the next chunk, so fn must not
+// retain them after it returns. A chunk only holds rows from a single result set.
+// If fn returns an error, FillChunks stops and returns it.
+// When MaxRows is reached, the rows read so far are passed to fn before the
+// *TruncatedError is returned. The MaxBytes option does not apply to chunks,
+// and the SpillToDisk option is an error.
+func FillChunks(ctx context.Context, rows *sql.Rows, chunkSize int, fn func(*Buffer) error, opts ...Option) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+	if fn == nil {
+		return errors.New("missing chunk func")
+	}
+	f := newFiller(ctx, rows, opts)
+	c := f.c
+	if c.spillBytes > 0 {
+		return errors.New("SpillToDisk is not supported with FillChunks")
+	}
+	table := &Buffer{
+		Rows: make([]Row, 0, chunkSize),
+	}
+	var slab []any // Field storage for every row in a chunk.
+
+	for {
+		first := true
+		f.skipped = 0
+		setRows := 0 // Rows read in the current result set.
+		for rows.Next() {
+			if first {
+				first = false
+				if err := f.setup(table); err != nil {
+					return err
+				}
+				slab = make([]any, chunkSize*len(table.Columns))
+			}
+			if len(table.Rows) == chunkSize {
+				if err := fn(table); err != nil {
+					return err
+				}
+				table.Rows = table.Rows[:0]
+				clear(slab)
+			}
+			if c.maxRows > 0 && f.rowCount >= c.maxRows {
+				if len(table.Rows) > 0 {
+					if err := fn(table); err != nil {
+						return err
+					}
+				}
+				return c.truncated(f.rowCount, 0, false)
+			}
+			if f.rowCount%ctxCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+
+			n := len(table.Columns)
+			i := len(table.Rows) * n
+			out := slab[i : i+n : i+n]
+			if err := f.scan(out); err != nil {
+				err = rowError(setRows+f.skipped, err)
+				if !c.continueOnError {
+					return err
+				}
+				if !f.skip(err) {
+					return &RowErrors{Errors: f.errs, Limit: true}
+				}
+				clear(out)
+				continue
+			}
+			f.rowCount++
+			setRows++
+			table.Rows = append(table.Rows, Row{
+				columnNameIndex: table.columnNameIndex,
+				Field:           out,
+			})
+			if c.progress != nil && f.rowCount%c.progressEvery == 0 {
+				c.progress(f.rowCount, f.resultSet)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return incomplete(f.rowCount, err)
+		}
+		if len(table.Rows) > 0 {
+			if err := fn(table); err != nil {
+				return err
+			}
+			table.Rows = table.Rows[:0]
+		}
+		more, err := f.nextResultSet()
+		if err != nil {
+			return err
+		}
+		if !more {
+			f.done()
+			return f.rowErrors()
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFillChunks(t *testing.T) {
+	a := fakeResult{Columns: []string{"ID"}}
+	for i := 0; i < 5; i++ {
+		a.Rows = append(a.Rows, []driver.Value{int64(i)})
+	}
+	b := fakeResult{Columns: []string{"Name"}, Rows: [][]driver.Value{{"x"}}}
+
+	var got []string
+	err := FillChunks(context.Background(), fakeRows(t, a, b), 2, func(buf *Buffer) error {
+		var s []any
+		for _, row := range buf.Rows {
+			s = append(s, row.Field...)
+		}
+		got = append(got, fmt.Sprint(buf.Columns, s))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got), "[[ID] [0 1] [ID] [2 3] [ID] [4] [Name] [x]]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
+
+func TestFillChunksOptions(t *testing.T) {
+	errBad := errors.New("bad value")
+	conv := func(col Column, v any) (any, error) {
+		if v == int64(-1) {
+			return nil, errBad
+		}
+		return v, nil
+	}
+	a := fakeResult{
+		Columns: []string{"ID"},
+		Rows:    [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+	}
+	b := fakeResult{
+		Columns: []string{"ID"},
+		Rows:    [][]driver.Value{{int64(4)}, {int64(-1)}, {int64(6)}},
+	}
+
+	list := []struct {
+		Name  string
+		Opts  []Option
+		Want  string
+		Error string
+	}{
+		{Name: "row-error", Opts: []Option{WithConverter(conv)}, Want: "[[1 2] [3]]", Error: `row 1, column "ID": bad value`},
+		{Name: "continue-on-error", Opts: []Option{WithConverter(conv), ContinueOnError(0)}, Want: "[[1 2] [3] [4 6]]", Error: `skipped 1 rows, first: row 1, column "ID": bad value`},
+		{Name: "max-rows", Opts: []Option{MaxRows(4)}, Want: "[[1 2] [3] [4]]", Error: "result truncated at 4 rows"},
+		{Name: "spill", Opts: []Option{SpillToDisk(1, t.TempDir())}, Want: "[]", Error: "SpillToDisk is not supported with FillChunks"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var got []string
+			err := FillChunks(context.Background(), fakeRows(t, a, b), 2, func(buf *Buffer) error {
+				var s []any
+				for _, row := range buf.Rows {
+					s = append(s, row.Field...)
+				}
+				got = append(got, fmt.Sprint(s))
+				return nil
+			}, item.Opts...)
+			if g, w := fmt.Sprint(err), item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if g, w := fmt.Sprint(got), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestFillChunksProgress(t *testing.T) {
+	res := fakeResult{Columns: []string{"ID"}}
+	for i := 0; i < 5; i++ {
+		res.Rows = append(res.Rows, []driver.Value{int64(i)})
+	}
+	var got []string
+	progress := func(rows, resultSet int) {
+		got = append(got, fmt.Sprint(rows, resultSet))
+	}
+	err := FillChunks(context.Background(), fakeRows(t, res, res), 3, func(*Buffer) error { return nil }, WithProgress(progress), ProgressEvery(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got), "[4 0 8 1 10 1]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
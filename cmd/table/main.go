@@ -0,0 +1,247 @@
+// Command table runs a query and prints every result set.
+//
+// The query is given as an argument, read from a file with -f, or read from
+// standard input. The data source name may also be set with the TABLE_DSN
+// environment variable, to keep passwords off the command line.
+//
+//	table -driver postgres -dsn "postgres://localhost/shop" -format pretty "select * from orders"
+//	table -driver sqlserver -f report.sql -format markdown
+//
+// Formats are csv, json, ndjson, markdown, and pretty. The postgres, mysql,
+// and sqlserver drivers are included.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-sql/table"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/microsoft/go-mssqldb"
+)
+
+func main() {
+	driver := flag.String("driver", "postgres", "database/sql driver name: postgres, mysql, or sqlserver")
+	dsn := flag.String("dsn", os.Getenv("TABLE_DSN"), "data source name; defaults to $TABLE_DSN")
+	file := flag.String("f", "", "read the query from file")
+	format := flag.String("format", "pretty", "output format: csv, json, ndjson, markdown, or pretty")
+	timeout := flag.Duration("timeout", 0, "stop the query after this duration")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: table [-driver name] [-dsn dsn] [-format f] [-f file | query]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	query, err := readQuery(*file, flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "table: %v\n", err)
+		os.Exit(2)
+	}
+	render, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "table: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	db, err := sql.Open(*driver, *dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "table: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := run(ctx, db, query, render, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// readQuery returns the query from the file, the arguments, or standard input.
+func readQuery(file string, args []string) (string, error) {
+	switch {
+	case len(file) > 0 && len(args) > 0:
+		return "", errors.New("give a query file or a query, not both")
+	case len(file) > 0:
+		bb, err := os.ReadFile(file)
+		return string(bb), err
+	case len(args) > 0:
+		return strings.Join(args, " "), nil
+	}
+	bb, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	if len(strings.TrimSpace(string(bb))) == 0 {
+		return "", errors.New("missing query")
+	}
+	return string(bb), nil
+}
+
+// run executes the query and renders every result set to w.
+func run(ctx context.Context, q table.Queryer, query string, render func(io.Writer, table.Set) error, w io.Writer) error {
+	set, err := table.NewSet(ctx, q, query)
+	if err != nil {
+		return err
+	}
+	return render(w, set)
+}
+
+var formats = map[string]func(io.Writer, table.Set) error{
+	"csv":      renderCSV,
+	"json":     renderJSON,
+	"ndjson":   renderNDJSON,
+	"markdown": renderMarkdown,
+	"pretty":   renderPretty,
+}
+
+// renderCSV writes each result set as CSV, separated by a blank line.
+func renderCSV(w io.Writer, set table.Set) error {
+	for i, b := range set {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		if err := b.WriteCSV(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderJSON writes the set as a JSON array of buffers.
+func renderJSON(w io.Writer, set table.Set) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(set)
+}
+
+// renderNDJSON writes, for each result set, the column names as a JSON
+// array on one line, then each row as a JSON array on its own line.
+func renderNDJSON(w io.Writer, set table.Set) error {
+	enc := json.NewEncoder(w)
+	for _, b := range set {
+		if err := enc.Encode(b.Columns); err != nil {
+			return err
+		}
+		for _, row := range b.Rows {
+			if err := enc.Encode(row.Field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderMarkdown writes each result set as a Markdown table.
+func renderMarkdown(w io.Writer, set table.Set) error {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ", "\r", "")
+	for i, b := range set {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		var sb strings.Builder
+		line := func(cells []string) {
+			sb.WriteString("|")
+			for _, c := range cells {
+				sb.WriteString(" " + escape.Replace(c) + " |")
+			}
+			sb.WriteString("\n")
+		}
+		line(b.Columns)
+		sep := make([]string, len(b.Columns))
+		for i := range sep {
+			sep[i] = "---"
+		}
+		line(sep)
+		for _, row := range b.Rows {
+			line(cells(row.Field))
+		}
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderPretty writes each result set as aligned columns, followed by a count of rows.
+func renderPretty(w io.Writer, set table.Set) error {
+	for i, b := range set {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		rows := make([][]string, 0, len(b.Rows)+1)
+		rows = append(rows, b.Columns)
+		for _, row := range b.Rows {
+			rows = append(rows, cells(row.Field))
+		}
+		widths := make([]int, len(b.Columns))
+		for _, r := range rows {
+			for ci, c := range r {
+				widths[ci] = max(widths[ci], utf8.RuneCountInString(c))
+			}
+		}
+		var sb strings.Builder
+		line := func(r []string) {
+			for ci, c := range r {
+				if ci > 0 {
+					sb.WriteString("  ")
+				}
+				sb.WriteString(c)
+				if ci < len(r)-1 {
+					sb.WriteString(strings.Repeat(" ", widths[ci]-utf8.RuneCountInString(c)))
+				}
+			}
+			sb.WriteString("\n")
+		}
+		line(rows[0])
+		sep := make([]string, len(widths))
+		for ci, n := range widths {
+			sep[ci] = strings.Repeat("-", n)
+		}
+		line(sep)
+		for _, r := range rows[1:] {
+			line(r)
+		}
+		fmt.Fprintf(&sb, "(%d rows)\n", len(b.Rows))
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cells formats fields as text, with NULL as "NULL".
+func cells(field []any) []string {
+	out := make([]string, len(field))
+	for i, f := range field {
+		switch v := f.(type) {
+		case nil:
+			out[i] = "NULL"
+		case []byte:
+			out[i] = string(v)
+		case time.Time:
+			out[i] = v.Format(time.RFC3339Nano)
+		default:
+			out[i] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
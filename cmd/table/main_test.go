@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang-sql/table"
+)
+
+func TestRender(t *testing.T) {
+	people := &table.Buffer{Columns: []string{"id", "name"}}
+	people.AddRow([]any{int64(1), "Ann"})
+	people.AddRow([]any{int64(22), nil})
+	count := &table.Buffer{Columns: []string{"n"}}
+	count.AddRow([]any{int64(2)})
+	set := table.Set{people, count}
+
+	list := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "id,name\n1,Ann\n22,\n\nn\n2\n"},
+		{"ndjson", "[\"id\",\"name\"]\n[1,\"Ann\"]\n[22,null]\n[\"n\"]\n[2]\n"},
+		{"markdown", "| id | name |\n| --- | --- |\n| 1 | Ann |\n| 22 | NULL |\n\n| n |\n| --- |\n| 2 |\n"},
+		{"pretty", "id  name\n--  ----\n1   Ann\n22  NULL\n(2 rows)\n\nn\n-\n2\n(1 rows)\n"},
+	}
+	for _, item := range list {
+		t.Run(item.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := formats[item.format](&buf, set); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != item.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", item.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+// Command tablegen generates reflection free functions that copy a
+// table.Buffer into a slice of structs.
+//
+// For each named struct type, tablegen writes a function
+//
+//	func FillXxx(buf *table.Buffer) ([]Xxx, error)
+//
+// that gives the same result as table.BufferToStruct. Fields are matched to
+// columns as table.ColumnsOf lists them: by the `sql:"Name"` tag or by field
+// name, fields tagged `sql:"-"` and unexported fields are skipped, and struct
+// fields without a column are an error. The -tag and -tagfallback flags read
+// other tags, like the table.StructTag and table.TagFallback options.
+//
+// Values of the field type, or of the pointed to type for a pointer field,
+// are assigned without reflection, as is NULL for a pointer field or a field
+// tagged nullzero. If any other value is found, such as an int64 for an int32
+// field or a NULL that is an error, FillXxx returns table.BufferToStruct of
+// the buffer instead, so the same conversions and errors apply.
+//
+// Embedded fields are not supported, and BufferToStruct options other than
+// the tags, such as NullAsZero, can not be given.
+//
+// It also writes a variable XxxColumns with the table.StructColumns of the
+// type, including the columns of fields tagged with the pk and omitinsert
+// options, like table.ColumnsOf.
+//
+// Typical use is a go:generate directive next to the struct types:
+//
+//	//go:generate go run github.com/golang-sql/table/cmd/tablegen -type Account,Order
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_table.go")
+	var c config
+	flag.StringVar(&c.tag, "tag", "sql", "field tag naming the columns, like table.StructTag")
+	flag.BoolVar(&c.fallback, "tagfallback", false, "also read db and json tags, like table.TagFallback")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: tablegen -type T[,T...] [-tag name] [-tagfallback] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if len(*typeNames) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+	names := strings.Split(*typeNames, ",")
+
+	src, err := generate(dir, names, c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tablegen: %v\n", err)
+		os.Exit(1)
+	}
+	out := *output
+	if len(out) == 0 {
+		out = filepath.Join(dir, strings.ToLower(names[0])+"_table.go")
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "tablegen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// config holds the struct tag flags.
+type config struct {
+	tag      string // Empty is "sql".
+	fallback bool
+}
+
+// tags returns the field tags read, in order, like the tags read by
+// table.BufferToStruct with the StructTag and TagFallback options.
+func (c config) tags() []string {
+	tags := []string{"sql"}
+	if len(c.tag) > 0 {
+		tags[0] = c.tag
+	}
+	if c.fallback {
+		for _, t := range []string{"db", "json"} {
+			if !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// options returns the table options selecting the same tags.
+func (c config) options() []string {
+	var opts []string
+	if len(c.tag) > 0 && c.tag != "sql" {
+		opts = append(opts, fmt.Sprintf("table.StructTag(%q)", c.tag))
+	}
+	if c.fallback {
+		opts = append(opts, "table.TagFallback()")
+	}
+	return opts
+}
+
+// structField is a struct field mapped to a column.
+type structField struct {
+	Name   string // Go field name.
+	Column string // Column name.
+	Type   string // Go type expression.
+	Elem   string // Type pointed to, if a pointer.
+
+	PK         bool // Tagged pk.
+	OmitInsert bool // Tagged omitinsert.
+	NullZero   bool // Tagged nullzero.
+}
+
+// generate parses the non-test Go files in dir and returns the formatted
+// source of the fill functions for the named types.
+func generate(dir string, typeNames []string, c config) ([]byte, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var pkgName string
+	structs := map[string]*ast.StructType{}
+	fileImports := map[string]map[string]string{} // Type name to the file imports.
+	for _, fn := range matches {
+		if strings.HasSuffix(fn, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, fn, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		pkgName = f.Name.Name
+		imports := map[string]string{} // Local name to path.
+		for _, is := range f.Imports {
+			p, err := strconv.Unquote(is.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			local := p[strings.LastIndex(p, "/")+1:]
+			if is.Name != nil {
+				local = is.Name.Name
+			}
+			imports[local] = p
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			ts, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				structs[ts.Name.Name] = st
+				fileImports[ts.Name.Name] = imports
+			}
+			return false
+		})
+	}
+	if len(pkgName) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	body := &bytes.Buffer{}
+	imports := map[string]string{"errors": "errors", "fmt": "fmt", "table": "github.com/golang-sql/table"}
+	sort.Strings(typeNames)
+	for _, name := range typeNames {
+		st, ok := structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found in %s", name, dir)
+		}
+		fields, err := structFields(st, c.tags(), fileImports[name], imports)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		writeFill(body, name, fields, c.options())
+		writeColumns(body, name, fields)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by tablegen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import (\n")
+	locals := make([]string, 0, len(imports))
+	for local := range imports {
+		locals = append(locals, local)
+	}
+	sort.Slice(locals, func(i, j int) bool { return imports[locals[i]] < imports[locals[j]] })
+	for _, local := range locals {
+		p := imports[local]
+		if local == p[strings.LastIndex(p, "/")+1:] {
+			fmt.Fprintf(buf, "\t%q\n", p)
+		} else {
+			fmt.Fprintf(buf, "\t%s %q\n", local, p)
+		}
+	}
+	fmt.Fprintf(buf, ")\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// structFields returns the mapped fields, using the same rules as BufferToStruct.
+// Packages referenced by field types are added to used from the file imports.
+func structFields(st *ast.StructType, tags []string, fileImports, used map[string]string) ([]structField, error) {
+	var list []structField
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("embedded field %s is not supported", types.ExprString(f.Type))
+		}
+		var importErr error
+		ast.Inspect(f.Type, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if x, ok := sel.X.(*ast.Ident); ok {
+				p, ok := fileImports[x.Name]
+				if !ok {
+					importErr = fmt.Errorf("unknown package %s", x.Name)
+				}
+				used[x.Name] = p
+			}
+			return false
+		})
+		if importErr != nil {
+			return nil, importErr
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+		for _, n := range f.Names {
+			// Unexported fields can not be set by BufferToStruct.
+			if !n.IsExported() {
+				continue
+			}
+			sf := structField{
+				Name:   n.Name,
+				Column: n.Name,
+				Type:   types.ExprString(f.Type),
+			}
+			if star, ok := f.Type.(*ast.StarExpr); ok {
+				sf.Elem = types.ExprString(star.X)
+			}
+			name, opts := fieldTag(tag, tags)
+			if name == "-" {
+				continue
+			}
+			if len(name) > 0 {
+				sf.Column = name
+			}
+			if len(opts) > 0 {
+				for _, o := range strings.Split(opts, ",") {
+					switch o {
+					default:
+						return nil, fmt.Errorf("field %s: unknown tag option %q", n.Name, o)
+					case "":
+					case "nullzero":
+						sf.NullZero = true
+					case "pk":
+						sf.PK = true
+					case "omitinsert":
+						sf.OmitInsert = true
+					}
+				}
+			}
+			list = append(list, sf)
+		}
+	}
+	return list, nil
+}
+
+// fieldTag returns the column name and options of the first of the tags
+// found, like table.BufferToStruct. Options are only read from the first tag.
+func fieldTag(tag reflect.StructTag, tags []string) (string, string) {
+	for i, t := range tags {
+		v, ok := tag.Lookup(t)
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(v, ",")
+		if i > 0 || v == "-" {
+			return name, ""
+		}
+		return name, opts
+	}
+	return "", ""
+}
+
+// writeColumns writes the table.StructColumns of the type, as returned by
+// table.ColumnsOf.
+func writeColumns(buf *bytes.Buffer, name string, fields []structField) {
+	var columns, key, omit []string
+	for _, f := range fields {
+		columns = append(columns, strconv.Quote(f.Column))
+		if f.PK {
+			key = append(key, strconv.Quote(f.Column))
+		}
+		if f.OmitInsert {
+			omit = append(omit, strconv.Quote(f.Column))
+		}
+	}
+	list := func(names []string) string {
+		if len(names) == 0 {
+			return "nil"
+		}
+		return "[]string{" + strings.Join(names, ", ") + "}"
+	}
+	fmt.Fprintf(buf, "\n// %sColumns lists the columns of %s, as returned by table.ColumnsOf.\n", name, name)
+	fmt.Fprintf(buf, "var %sColumns = table.StructColumns{\n", name)
+	fmt.Fprintf(buf, "Columns: %s,\n", list(columns))
+	fmt.Fprintf(buf, "Key: %s,\n", list(key))
+	fmt.Fprintf(buf, "OmitInsert: %s,\n", list(omit))
+	fmt.Fprintf(buf, "}\n")
+}
+
+func writeFill(buf *bytes.Buffer, name string, fields []structField, opts []string) {
+	p := func(format string, args ...any) {
+		fmt.Fprintf(buf, format, args...)
+		buf.WriteByte('\n')
+	}
+	p("")
+	p("// Fill%s copies buf into a slice of %s.", name, name)
+	p("func Fill%s(buf *table.Buffer) ([]%s, error) {", name, name)
+	p("index := [%d]int{}", len(fields))
+	p("for i := range index {")
+	p("index[i] = -1")
+	p("}")
+	p("for i, n := range buf.Columns {")
+	p("switch n {")
+	for i, f := range fields {
+		p("case %q:", f.Column)
+		p("index[%d] = i", i)
+	}
+	p("}")
+	p("}")
+	p("var missing []string")
+	for i, f := range fields {
+		p("if index[%d] < 0 {", i)
+		if f.Column == f.Name {
+			p("missing = append(missing, %q)", f.Name)
+		} else {
+			p("missing = append(missing, %q)", fmt.Sprintf("%s(tag=%s)", f.Name, f.Column))
+		}
+		p("}")
+	}
+	p("if len(missing) > 0 {")
+	p("return nil, fmt.Errorf(\"unused fields in struct %%q\", missing)")
+	p("}")
+	p("errConvert := errors.New(\"value needs converting\")")
+	p("list := make([]%s, buf.Len())", name)
+	p("err := buf.Each(func(ri int, row table.Row) error {")
+	p("v := &list[ri]")
+	for i, f := range fields {
+		p("switch fv := row.Field[index[%d]].(type) {", i)
+		if len(f.Elem) > 0 {
+			p("case %s:", f.Elem)
+			p("v.%s = &fv", f.Name)
+		} else {
+			p("case %s:", f.Type)
+			p("v.%s = fv", f.Name)
+		}
+		if len(f.Elem) > 0 || f.NullZero {
+			p("case nil:")
+		}
+		p("default:")
+		p("return errConvert")
+		p("}")
+	}
+	p("return nil")
+	p("})")
+	p("if err == errConvert {")
+	p("return table.BufferToStruct[%s](%s)", name, strings.Join(append([]string{"buf"}, opts...), ", "))
+	p("}")
+	p("if err != nil {")
+	p("return nil, err")
+	p("}")
+	p("return list, nil")
+	p("}")
+}
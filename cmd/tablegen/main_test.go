@@ -0,0 +1,203 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	src := `package acct
+
+import "time"
+
+type Account struct {
+	ID      int64  ` + "`sql:\",pk,omitinsert\"`" + `
+	Name    string ` + "`sql:\"AccountName,nullzero\"`" + `
+	Created time.Time
+	Closed  *time.Time
+	Note    string ` + "`sql:\"-\"`" + `
+	secret  string
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "acct.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := generate(dir, []string{"Account"}, config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"package acct",
+		"func FillAccount(buf *table.Buffer) ([]Account, error) {",
+		`case "AccountName":`,
+		"list := make([]Account, buf.Len())",
+		"err := buf.Each(func(ri int, row table.Row) error {",
+		"case time.Time:",
+		"v.Created = fv",
+		"v.Closed = &fv",
+		`missing = append(missing, "Name(tag=AccountName)")`,
+		"return table.BufferToStruct[Account](buf)",
+		"var AccountColumns = table.StructColumns{",
+		`Columns:    []string{"ID", "AccountName", "Created", "Closed"},`,
+		`Key:        []string{"ID"},`,
+		`OmitInsert: []string{"ID"},`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated code missing %q:\n%s", want, got)
+		}
+	}
+	for _, field := range []string{"Note", "secret"} {
+		if strings.Contains(got, field) {
+			t.Errorf("skipped field %s was mapped:\n%s", field, got)
+		}
+	}
+
+	if _, err := generate(dir, []string{"Missing"}, config{}); err == nil {
+		t.Fatal("expected an error for a missing type")
+	}
+}
+
+func TestGenerateTags(t *testing.T) {
+	dir := t.TempDir()
+	src := `package acct
+
+type Account struct {
+	ID   int64  ` + "`db:\"id\" sql:\",pk\"`" + `
+	Name string ` + "`json:\"name,omitempty\"`" + `
+	Note string ` + "`db:\"-\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "acct.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list := []struct {
+		Name   string
+		Config config
+		Want   []string
+	}{
+		{Name: "struct-tag", Config: config{tag: "db"}, Want: []string{
+			`Columns:    []string{"id", "Name"},`,
+			`return table.BufferToStruct[Account](buf, table.StructTag("db"))`,
+		}},
+		{Name: "tag-fallback", Config: config{fallback: true}, Want: []string{
+			`Columns:    []string{"ID", "name"},`,
+			`Key:        []string{"ID"},`,
+			`return table.BufferToStruct[Account](buf, table.TagFallback())`,
+		}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			out, err := generate(dir, []string{"Account"}, item.Config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range item.Want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("generated code missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+// TestGenerateBuild builds the generated code and checks it gives the same
+// result as table.BufferToStruct.
+func TestGenerateBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module acct\n\ngo 1.21\n\nrequire github.com/golang-sql/table v0.0.0\n\nreplace github.com/golang-sql/table => " + root + "\n",
+		"acct.go": `package acct
+
+type Account struct {
+	ID     int64  ` + "`sql:\",pk\"`" + `
+	Level  int32
+	Name   string ` + "`sql:\",nullzero\"`" + `
+	Closed *int64
+	secret string
+}
+`,
+		"acct_test.go": `package acct
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang-sql/table"
+)
+
+func TestFill(t *testing.T) {
+	columns := []string{"ID", "Level", "Name", "Closed", "secret"}
+	list := []struct {
+		Name string
+		Row  []any
+	}{
+		{Name: "exact", Row: []any{int64(1), int32(2), "R1", int64(3), "s"}},
+		{Name: "nulls", Row: []any{int64(1), int32(2), nil, nil, nil}},
+		{Name: "convert", Row: []any{int64(1), int64(2), "R1", int32(3), nil}},
+		{Name: "null-error", Row: []any{nil, int32(2), "R1", nil, nil}},
+		{Name: "overflow", Row: []any{int64(1), int64(1) << 40, "R1", nil, nil}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := &table.Buffer{Columns: columns}
+			b.AddRow(item.Row)
+			got, err := FillAccount(b)
+			want, werr := table.BufferToStruct[Account](b)
+			if g, w := fmt.Sprint(err), fmt.Sprint(werr); g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if werr != nil && errors.Is(werr, table.ErrNull) != errors.Is(err, table.ErrNull) {
+				t.Fatalf("expected ErrNull to match, got error: %v", err)
+			}
+			if g, w := format(got), format(want); g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func format(list []Account) string {
+	var s string
+	for _, a := range list {
+		var closed any
+		if a.Closed != nil {
+			closed = *a.Closed
+		}
+		s += fmt.Sprint(a.ID, a.Level, a.Name, closed, a.secret)
+	}
+	return s
+}
+`,
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := generate(dir, []string{"Account"}, config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "account_table.go"), out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, b)
+	}
+}
@@ -0,0 +1,74 @@
+// Command tablestruct prints a Go struct type matching the columns of a
+// saved table.Buffer, with `sql` tags for table.BufferToStruct.
+//
+// The input is a file written by Buffer.WriteSnapshot, or a Buffer encoded
+// as JSON. Snapshots keep the value types, while JSON numbers are read as
+// float64. With no file, standard input is read.
+//
+//	tablestruct -type Order -package store orders.snap > order.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang-sql/table"
+)
+
+func main() {
+	typeName := flag.String("type", "", "struct type name; required")
+	pkg := flag.String("package", "main", "package name")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: tablestruct -type T [-package p] [file]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if len(*typeName) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	in := io.Reader(os.Stdin)
+	if args := flag.Args(); len(args) > 0 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tablestruct: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	src, err := generate(in, *pkg, *typeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tablestruct: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(src)
+}
+
+// generate reads a snapshot or JSON buffer from r and returns the struct source.
+func generate(r io.Reader, pkg, typeName string) ([]byte, error) {
+	br := bufio.NewReader(r)
+	var buf *table.Buffer
+	head, _ := br.Peek(len(snapshotMagic))
+	if bytes.Equal(head, snapshotMagic) {
+		var err error
+		buf, err = table.ReadSnapshot(br)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		buf = &table.Buffer{}
+		if err := json.NewDecoder(br).Decode(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.GoStruct(pkg, typeName)
+}
+
+// snapshotMagic starts every file written by Buffer.WriteSnapshot.
+var snapshotMagic = []byte("TBLSNAP")
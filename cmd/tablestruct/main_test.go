@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-sql/table"
+)
+
+func TestGenerate(t *testing.T) {
+	buf := &table.Buffer{Columns: []string{"order_id", "created", "note"}}
+	buf.AddRow([]any{int64(1), time.Unix(0, 0).UTC(), nil})
+	buf.AddRow([]any{int64(2), time.Unix(1, 0).UTC(), "x"})
+	snap := &bytes.Buffer{}
+	if err := buf.WriteSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	list := []struct {
+		Name  string
+		Input string
+		Want  []string
+	}{
+		{
+			Name:  "snapshot",
+			Input: snap.String(),
+			Want: []string{
+				"package store",
+				`import (
+	"time"
+)`,
+				"OrderID int64     `sql:\"order_id\"`",
+				"Created time.Time `sql:\"created\"`",
+				"Note    *string   `sql:\"note\"`",
+			},
+		},
+		{
+			Name:  "json",
+			Input: `{"Columns":["ID","Name"],"Rows":[[1,"a"]]}`,
+			Want: []string{
+				"ID   float64 `sql:\"ID\"`",
+				"Name string  `sql:\"Name\"`",
+			},
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			out, err := generate(strings.NewReader(item.Input), "store", "Order")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(out)
+			for _, want := range item.Want {
+				if !strings.Contains(got, want) {
+					t.Errorf("generated code missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
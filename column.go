@@ -0,0 +1,78 @@
+package table
+
+// HasColumn reports if the buffer has the named column.
+func (b *Buffer) HasColumn(columnName string) bool {
+	_, ok := b.ColumnIndex(columnName)
+	return ok
+}
+
+// ColumnIndex returns the index of the named column and if it was found.
+func (b *Buffer) ColumnIndex(columnName string) (int, bool) {
+	b.buildIndex()
+	i, ok := b.columnNameIndex[columnName]
+	return i, ok
+}
+
+// HasColumn reports if the row has the named column.
+func (r Row) HasColumn(columnName string) bool {
+	_, ok := r.columnNameIndex[columnName]
+	return ok
+}
+
+// ColumnIndex returns the index of the named column and if it was found.
+func (r Row) ColumnIndex(columnName string) (int, bool) {
+	i, ok := r.columnNameIndex[columnName]
+	return i, ok
+}
+
+// lookupColumn returns the index of the named column.
+func (b *Buffer) lookupColumn(columnName string) (int, error) {
+	i, ok := b.ColumnIndex(columnName)
+	if !ok {
+		return 0, &IndexError{subject: SubjectName, notFoundName: columnName}
+	}
+	return i, nil
+}
+
+// Coalesce replaces NULL values in the named column with defaultValue.
+func (b *Buffer) Coalesce(columnName string, defaultValue any) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	i, err := b.lookupColumn(columnName)
+	if err != nil {
+		return err
+	}
+	b.unshare()
+	for _, row := range b.Rows {
+		if row.Field[i] == nil {
+			row.Field[i] = defaultValue
+		}
+	}
+	return nil
+}
+
+// CoalesceColumns replaces NULL values in each named column with the
+// column's default value. No values are changed if a column is missing.
+func (b *Buffer) CoalesceColumns(defaults map[string]any) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	index := make(map[int]any, len(defaults))
+	for n, v := range defaults {
+		i, err := b.lookupColumn(n)
+		if err != nil {
+			return err
+		}
+		index[i] = v
+	}
+	b.unshare()
+	for _, row := range b.Rows {
+		for i, v := range index {
+			if row.Field[i] == nil {
+				row.Field[i] = v
+			}
+		}
+	}
+	return nil
+}
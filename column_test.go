@@ -0,0 +1,137 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCoalesce(t *testing.T) {
+	rows := func(b *Buffer) string {
+		fields := make([][]any, len(b.Rows))
+		for i, row := range b.Rows {
+			fields[i] = row.Field
+		}
+		return fmt.Sprint(fields)
+	}
+	list := []struct {
+		Name  string
+		Run   func(b *Buffer) error
+		Want  string
+		Error string
+	}{
+		{
+			Name: "column",
+			Run:  func(b *Buffer) error { return b.Coalesce("Score", int64(0)) },
+			Want: "[[1 R1 0] [2 <nil> 20] [<nil> <nil> 0]]",
+		},
+		{
+			Name: "columns",
+			Run: func(b *Buffer) error {
+				return b.CoalesceColumns(map[string]any{"ID": int64(-1), "Name": "none", "Score": int64(0)})
+			},
+			Want: "[[1 R1 0] [2 none 20] [-1 none 0]]",
+		},
+		{
+			Name: "columns-empty",
+			Run:  func(b *Buffer) error { return b.CoalesceColumns(nil) },
+			Want: "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+		},
+		{
+			Name:  "missing",
+			Run:   func(b *Buffer) error { return b.Coalesce("Total", int64(0)) },
+			Want:  "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+			Error: `Table doesn't have column named "Total"`,
+		},
+		{
+			// No values are changed if any column is missing.
+			Name:  "columns-missing",
+			Run:   func(b *Buffer) error { return b.CoalesceColumns(map[string]any{"Name": "none", "Total": int64(0)}) },
+			Want:  "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+			Error: `Table doesn't have column named "Total"`,
+		},
+		{
+			Name: "frozen",
+			Run: func(b *Buffer) error {
+				b.Freeze()
+				return b.Coalesce("Name", "none")
+			},
+			Want:  "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+			Error: ErrFrozen.Error(),
+		},
+		{
+			Name: "frozen-columns",
+			Run: func(b *Buffer) error {
+				b.Freeze()
+				return b.CoalesceColumns(map[string]any{"Name": "none"})
+			},
+			Want:  "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+			Error: ErrFrozen.Error(),
+		},
+		{
+			// A derived buffer copies its fields before changing them.
+			Name: "derived",
+			Run: func(b *Buffer) error {
+				d := b.Filter(func(Row) bool { return true })
+				if err := d.Coalesce("Name", "none"); err != nil {
+					return err
+				}
+				if g, w := rows(d), "[[1 R1 <nil>] [2 none 20] [<nil> none <nil>]]"; g != w {
+					return fmt.Errorf("derived buffer got %s want %s", g, w)
+				}
+				return nil
+			},
+			Want: "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+		},
+		{
+			// A buffer that others were derived from also copies its fields.
+			Name: "parent",
+			Run: func(b *Buffer) error {
+				d := b.Sort(func(x, y Row) bool { return false })
+				if err := b.CoalesceColumns(map[string]any{"Score": int64(0)}); err != nil {
+					return err
+				}
+				if g, w := rows(d), "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]"; g != w {
+					return fmt.Errorf("derived buffer changed to %s", g)
+				}
+				return nil
+			},
+			Want: "[[1 R1 0] [2 <nil> 20] [<nil> <nil> 0]]",
+		},
+		{
+			// A derived frozen buffer is still read only.
+			Name: "derived-frozen",
+			Run: func(b *Buffer) error {
+				b.Freeze()
+				d := b.Filter(func(Row) bool { return true })
+				if err := d.Coalesce("Score", int64(0)); err != nil {
+					return err
+				}
+				if g, w := rows(d), "[[1 R1 0] [2 <nil> 20] [<nil> <nil> 0]]"; g != w {
+					return fmt.Errorf("derived buffer got %s want %s", g, w)
+				}
+				return nil
+			},
+			Want: "[[1 R1 <nil>] [2 <nil> 20] [<nil> <nil> <nil>]]",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := &Buffer{Columns: []string{"ID", "Name", "Score"}}
+			b.AddRow([]any{int64(1), "R1", nil})
+			b.AddRow([]any{int64(2), nil, int64(20)})
+			b.AddRow([]any{nil, nil, nil})
+
+			err := item.Run(b)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if g, w := rows(b), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
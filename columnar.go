@@ -0,0 +1,362 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// VectorKind is the storage type of a Vector.
+type VectorKind byte
+
+const (
+	KindNull    VectorKind = iota // Only NULL values seen so far.
+	KindInt64                     // Values in Vector.Int64.
+	KindFloat64                   // Values in Vector.Float64.
+	KindBool                      // Values in Vector.Bool.
+	KindString                    // Values in Vector.String.
+	KindBytes                     // Values in Vector.Bytes.
+	KindTime                      // Values in Vector.Time.
+	KindAny                       // Mixed or other types, values in Vector.Any.
+)
+
+func (k VectorKind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindInt64:
+		return "int64"
+	case KindFloat64:
+		return "float64"
+	case KindBool:
+		return "bool"
+	case KindString:
+		return "string"
+	case KindBytes:
+		return "bytes"
+	case KindTime:
+		return "time"
+	case KindAny:
+		return "any"
+	}
+	return fmt.Sprintf("VectorKind(%d)", byte(k))
+}
+
+// Vector stores the values of a single column in a typed slice.
+// The kind is chosen from the first non-NULL value. If a later value has a
+// different type, the column is moved to Any. Only the slice for the
+// Vector's Kind is set; NULL positions hold the zero value.
+type Vector struct {
+	Name string
+	Kind VectorKind
+
+	Int64   []int64
+	Float64 []float64
+	Bool    []bool
+	String  []string
+	Bytes   [][]byte
+	Time    []time.Time
+	Any     []any
+
+	nulls []uint64 // Bitmap of NULL positions.
+	n     int
+}
+
+// Len returns the number of values in the vector.
+func (v *Vector) Len() int {
+	return v.n
+}
+
+// IsNull reports if the value at i is NULL.
+func (v *Vector) IsNull(i int) bool {
+	w := i / 64
+	return w < len(v.nulls) && v.nulls[w]&(1<<(i%64)) != 0
+}
+
+// Value returns the value at i, or nil if it is NULL.
+func (v *Vector) Value(i int) any {
+	if i < 0 || i >= v.n {
+		panic(&IndexError{subject: SubjectRow, length: v.n, requested: i})
+	}
+	if v.IsNull(i) {
+		return nil
+	}
+	switch v.Kind {
+	case KindInt64:
+		return v.Int64[i]
+	case KindFloat64:
+		return v.Float64[i]
+	case KindBool:
+		return v.Bool[i]
+	case KindString:
+		return v.String[i]
+	case KindBytes:
+		return v.Bytes[i]
+	case KindTime:
+		return v.Time[i]
+	case KindAny:
+		return v.Any[i]
+	}
+	return nil
+}
+
+func kindOf(x any) VectorKind {
+	switch x.(type) {
+	case nil:
+		return KindNull
+	case int64:
+		return KindInt64
+	case float64:
+		return KindFloat64
+	case bool:
+		return KindBool
+	case string:
+		return KindString
+	case []byte:
+		return KindBytes
+	case time.Time:
+		return KindTime
+	}
+	return KindAny
+}
+
+// Append adds a value to the end of the vector.
+func (v *Vector) Append(x any) {
+	k := kindOf(x)
+	if k == KindNull {
+		i := v.n
+		for len(v.nulls) <= i/64 {
+			v.nulls = append(v.nulls, 0)
+		}
+		v.nulls[i/64] |= 1 << (i % 64)
+		v.appendZero()
+		v.n++
+		return
+	}
+	if v.Kind == KindNull {
+		v.setKind(k)
+	} else if v.Kind != k && v.Kind != KindAny {
+		v.setKind(KindAny)
+	}
+	switch v.Kind {
+	case KindInt64:
+		v.Int64 = append(v.Int64, x.(int64))
+	case KindFloat64:
+		v.Float64 = append(v.Float64, x.(float64))
+	case KindBool:
+		v.Bool = append(v.Bool, x.(bool))
+	case KindString:
+		v.String = append(v.String, x.(string))
+	case KindBytes:
+		v.Bytes = append(v.Bytes, x.([]byte))
+	case KindTime:
+		v.Time = append(v.Time, x.(time.Time))
+	case KindAny:
+		v.Any = append(v.Any, x)
+	}
+	v.n++
+}
+
+func (v *Vector) appendZero() {
+	switch v.Kind {
+	case KindInt64:
+		v.Int64 = append(v.Int64, 0)
+	case KindFloat64:
+		v.Float64 = append(v.Float64, 0)
+	case KindBool:
+		v.Bool = append(v.Bool, false)
+	case KindString:
+		v.String = append(v.String, "")
+	case KindBytes:
+		v.Bytes = append(v.Bytes, nil)
+	case KindTime:
+		v.Time = append(v.Time, time.Time{})
+	case KindAny:
+		v.Any = append(v.Any, nil)
+	}
+}
+
+// setKind moves any existing values to the storage for k.
+func (v *Vector) setKind(k VectorKind) {
+	if v.Kind == KindNull {
+		v.Kind = k
+		for i := 0; i < v.n; i++ {
+			v.appendZero()
+		}
+		return
+	}
+	values := make([]any, v.n)
+	for i := range values {
+		values[i] = v.Value(i)
+	}
+	v.Int64, v.Float64, v.Bool, v.String, v.Bytes, v.Time = nil, nil, nil, nil, nil, nil
+	v.Kind = k
+	v.Any = values
+}
+
+// ColumnBuffer is a result stored column by column rather then row by row.
+// Columns of a single type avoid storing each value in an interface.
+type ColumnBuffer struct {
+	Columns []string
+	Vectors []*Vector
+
+	columnNameIndex map[string]int
+	rows            int
+}
+
+// Len returns the number of rows.
+func (cb *ColumnBuffer) Len() int {
+	return cb.rows
+}
+
+// Vector returns the named column.
+func (cb *ColumnBuffer) Vector(columnName string) *Vector {
+	i, ok := cb.columnNameIndex[columnName]
+	if !ok {
+		panic(&IndexError{subject: SubjectName, notFoundName: columnName})
+	}
+	return cb.Vectors[i]
+}
+
+// Get the field from the row index and named column.
+func (cb *ColumnBuffer) Get(rowIndex int, columnName string) any {
+	return cb.Vector(columnName).Value(rowIndex)
+}
+
+// AddRow adds a new row to the end of the buffer.
+func (cb *ColumnBuffer) AddRow(row []any) {
+	if r, c := len(row), len(cb.Vectors); r != c {
+		panic(fmt.Errorf("row count %d is different then column schema count %d", r, c))
+	}
+	for i, f := range row {
+		cb.Vectors[i].Append(f)
+	}
+	cb.rows++
+}
+
+// Buffer returns the rows in a row based Buffer.
+func (cb *ColumnBuffer) Buffer() *Buffer {
+	b := &Buffer{
+		Columns: cb.Columns,
+		Rows:    make([]Row, 0, cb.rows),
+	}
+	for i := 0; i < cb.rows; i++ {
+		row := make([]any, len(cb.Vectors))
+		for ci, v := range cb.Vectors {
+			row[ci] = v.Value(i)
+		}
+		b.AddRow(row)
+	}
+	return b
+}
+
+// Columnar returns the buffer stored column by column, including rows on
+// disk. It panics if the rows on disk can not be read.
+func (b *Buffer) Columnar() *ColumnBuffer {
+	cb := newColumnBuffer(b.Columns)
+	b.eachRow(func(_ int, row Row) {
+		cb.AddRow(row.Field)
+	})
+	return cb
+}
+
+func newColumnBuffer(columns []string) *ColumnBuffer {
+	cb := &ColumnBuffer{
+		Columns:         columns,
+		Vectors:         make([]*Vector, len(columns)),
+		columnNameIndex: make(map[string]int, len(columns)),
+	}
+	for i, n := range columns {
+		cb.Vectors[i] = &Vector{Name: n}
+		cb.columnNameIndex[n] = i
+	}
+	return cb
+}
+
+// NewColumnBuffer returns the first result set of the query stored column by column.
+// Any Option values in params configure the fill and are not sent with the query.
+func NewColumnBuffer(ctx context.Context, q Queryer, sql string, params ...any) (*ColumnBuffer, error) {
+	rows, opts, qi, err := query(ctx, q, sql, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := FillColumns(ctx, rows, opts...)
+	qi.finish(err, func() FillStats {
+		stats := FillStats{ResultSets: len(list)}
+		for _, cb := range list {
+			if cb != nil {
+				stats.Rows += cb.Len()
+			}
+		}
+		return stats
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, &IndexError{subject: SubjectTable, length: len(list), requested: 0}
+	}
+	return list[0], nil
+}
+
+// FillColumns reads each result set into a ColumnBuffer.
+// The MaxBytes option does not apply to column buffers.
+// When an error is returned, the buffers read up to that point are also returned.
+func FillColumns(ctx context.Context, rows *sql.Rows, opts ...Option) ([]*ColumnBuffer, error) {
+	f := newFiller(ctx, rows, opts)
+	c := f.c
+	var list []*ColumnBuffer
+	for {
+		table := &Buffer{}
+		var cb *ColumnBuffer
+		var out []any
+		first := true
+		for rows.Next() {
+			if first {
+				first = false
+				if err := f.setup(table); err != nil {
+					return list, err
+				}
+				cb = newColumnBuffer(table.Columns)
+				cb.columnNameIndex = table.columnNameIndex
+				out = make([]any, len(table.Columns))
+			}
+			if c.maxRows > 0 && f.rowCount >= c.maxRows {
+				return append(list, cb), c.truncated(f.rowCount, 0, false)
+			}
+			if f.rowCount%ctxCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return append(list, cb), err
+				}
+			}
+			clear(out)
+			if err := f.scan(out); err != nil {
+				return append(list, cb), rowError(cb.rows, err)
+			}
+			cb.AddRow(out)
+			f.rowCount++
+			if c.progress != nil && f.rowCount%c.progressEvery == 0 {
+				c.progress(f.rowCount, f.resultSet)
+			}
+		}
+		if cb == nil {
+			cb = newColumnBuffer(nil)
+		}
+		list = append(list, cb)
+		if err := rows.Err(); err != nil {
+			return list, incomplete(f.rowCount, err)
+		}
+		more, err := f.nextResultSet()
+		if err != nil {
+			return list, err
+		}
+		if !more {
+			break
+		}
+	}
+	f.done()
+	return list, nil
+}
@@ -0,0 +1,48 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestColumnBuffer(t *testing.T) {
+	rows := fakeRows(t, fakeResult{
+		Columns: []string{"ID", "Name", "Mixed"},
+		Rows: [][]driver.Value{
+			{int64(1), nil, int64(5)},
+			{int64(2), "R2", "five"},
+			{nil, "R3", nil},
+		},
+	})
+	list, err := FillColumns(context.Background(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := list[0]
+	if g, w := cb.Len(), 3; g != w {
+		t.Fatalf("got %d rows want %d", g, w)
+	}
+	id := cb.Vector("ID")
+	if id.Kind != KindInt64 || fmt.Sprint(id.Int64) != "[1 2 0]" || !id.IsNull(2) || id.IsNull(1) {
+		t.Fatalf("unexpected ID vector: %+v", id)
+	}
+	name := cb.Vector("Name")
+	if name.Kind != KindString || fmt.Sprintf("%q", name.String) != `["" "R2" "R3"]` || !name.IsNull(0) {
+		t.Fatalf("unexpected Name vector: %+v", name)
+	}
+	if g := cb.Vector("Mixed").Kind; g != KindAny {
+		t.Fatalf("mixed column got kind %v", g)
+	}
+
+	b := cb.Buffer()
+	for i, w := range []string{"[1 <nil> 5]", "[2 R2 five]", "[<nil> R3 <nil>]"} {
+		if g := fmt.Sprint(b.Rows[i].Field); g != w {
+			t.Fatalf("row %d got %s want %s", i, g, w)
+		}
+	}
+	if g, w := fmt.Sprint(b.Columnar().Get(1, "Mixed")), "five"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
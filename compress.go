@@ -0,0 +1,102 @@
+package table
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Codec is a compression format for snapshots.
+type Codec byte
+
+// Codec values are stored in compressed snapshots; never re-number these.
+const (
+	CodecNone Codec = iota // No compression.
+	CodecGzip              // gzip, from compress/gzip.
+	CodecZstd              // Zstandard, registered by importing github.com/golang-sql/table/zstd.
+)
+
+func (c Codec) String() string {
+	switch c {
+	default:
+		return fmt.Sprintf("Codec(%d)", byte(c))
+	case CodecNone:
+		return "none"
+	case CodecGzip:
+		return "gzip"
+	case CodecZstd:
+		return "zstd"
+	}
+}
+
+// Compression compresses the snapshot with the codec. The header of the
+// snapshot is left uncompressed, so ReadSnapshot detects the codec itself.
+// The codec must be registered, as CodecGzip always is.
+func Compression(c Codec) SnapshotOption {
+	return func(sc *snapshotConfig) {
+		sc.codec = c
+	}
+}
+
+type codec struct {
+	magic     []byte
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+	newReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[Codec]*codec{
+		CodecGzip: {
+			magic: []byte{0x1f, 0x8b},
+			newWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+			newReader: func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+		},
+	}
+)
+
+// RegisterCodec makes a codec available to WriteSnapshot and ReadSnapshot,
+// replacing any previous registration. The magic bytes begin every stream
+// the codec writes, and detect snapshots compressed outside of WriteSnapshot;
+// at most 8 bytes are compared.
+func RegisterCodec(c Codec, magic []byte, newWriter func(w io.Writer) (io.WriteCloser, error), newReader func(r io.Reader) (io.ReadCloser, error)) {
+	if c == CodecNone {
+		panic("table: cannot register CodecNone")
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[c] = &codec{
+		magic:     append([]byte(nil), magic...),
+		newWriter: newWriter,
+		newReader: newReader,
+	}
+}
+
+// lookup returns the registered codec.
+func (c Codec) lookup() (*codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	cd, ok := codecs[c]
+	if !ok {
+		return nil, fmt.Errorf("table: snapshot codec %v is not registered", c)
+	}
+	return cd, nil
+}
+
+// detectCodec returns the registered codec whose magic bytes begin header.
+func detectCodec(header []byte) *codec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	for _, cd := range codecs {
+		if len(cd.magic) > 0 && len(cd.magic) <= len(header) && bytes.HasPrefix(header, cd.magic) {
+			return cd
+		}
+	}
+	return nil
+}
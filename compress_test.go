@@ -0,0 +1,85 @@
+package table
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotCompression(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Text"},
+	}
+	for i := 0; i < 100; i++ {
+		b.AddRow([]any{int64(i), strings.Repeat("text ", 20)})
+	}
+	var plain bytes.Buffer
+	if err := b.WriteSnapshot(&plain); err != nil {
+		t.Fatal(err)
+	}
+	var wrapped bytes.Buffer
+	gw := gzip.NewWriter(&wrapped)
+	if err := b.WriteSnapshot(gw); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	list := []struct {
+		Name  string
+		Write func(w *bytes.Buffer) error
+		Err   string
+	}{
+		{Name: "none", Write: func(w *bytes.Buffer) error { return b.WriteSnapshot(w, Compression(CodecNone)) }},
+		{Name: "gzip", Write: func(w *bytes.Buffer) error { return b.WriteSnapshot(w, Compression(CodecGzip)) }},
+		{Name: "wrapped gzip", Write: func(w *bytes.Buffer) error { _, err := w.Write(wrapped.Bytes()); return err }},
+		{Name: "unregistered", Write: func(w *bytes.Buffer) error { return b.WriteSnapshot(w, Compression(Codec(200))) }, Err: "table: snapshot codec Codec(200) is not registered"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := item.Write(&buf)
+			if g, w := fmt.Sprint(err), item.Err; item.Err != "" || err != nil {
+				if g != w {
+					t.Fatalf("expected error: %s, got error: %s", w, g)
+				}
+				return
+			}
+			if item.Name == "gzip" && buf.Len()*5 > plain.Len() {
+				t.Fatalf("compressed %d bytes, uncompressed %d bytes", buf.Len(), plain.Len())
+			}
+			got, err := ReadSnapshot(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := fmt.Sprint(got.Len(), got.Get(99, "ID")), "100 99"; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "t.snap.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteSnapshot(f, Compression(CodecGzip)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := OpenSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+	if g, w := fmt.Sprint(len(got.Rows), got.Spilled(), got.Get(42, "ID")), "100 0 42"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
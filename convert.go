@@ -0,0 +1,259 @@
+package table
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BytesAsString converts []byte field values to string as they are filled.
+// Columns named in exclude, such as those holding binary data, are left as []byte.
+func BytesAsString(exclude ...string) Option {
+	skip := make(map[string]bool, len(exclude))
+	for _, n := range exclude {
+		skip[n] = true
+	}
+	return WithConverter(func(col Column, v any) (any, error) {
+		bb, ok := v.([]byte)
+		if !ok || skip[col.Name] {
+			return v, nil
+		}
+		return string(bb), nil
+	})
+}
+
+// DefaultTimeLayouts are tried, in order, when parsing time text from the
+// driver with NormalizeTime, or with TimeLayouts if no layouts are given.
+// The fractional seconds are optional in each layout.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// timeTypes are the database type names of date, datetime, and timestamp
+// types, without any precision, as reported by common drivers.
+var timeTypes = map[string]bool{
+	"DATE":                           true,
+	"DATETIME":                       true,
+	"DATETIME2":                      true,
+	"DATETIMEOFFSET":                 true,
+	"SMALLDATETIME":                  true,
+	"TIMESTAMP":                      true,
+	"TIMESTAMPTZ":                    true,
+	"TIMESTAMP WITH TIME ZONE":       true,
+	"TIMESTAMP WITHOUT TIME ZONE":    true,
+	"TIMESTAMP WITH LOCAL TIME ZONE": true,
+}
+
+// isTimeColumn reports if the database type name is a date, datetime, or
+// timestamp type. Time of day types, such as TIME and TIMETZ, hold text that
+// is not a point in time and are not included, nor are types that only
+// contain a time in their name, such as DATERANGE.
+func isTimeColumn(col Column) bool {
+	n := strings.ToUpper(col.DatabaseTypeName)
+	// Remove a precision, as in DATETIME2(7) or TIMESTAMP(6) WITH TIME ZONE.
+	if i := strings.IndexByte(n, '('); i >= 0 {
+		if j := strings.IndexByte(n[i:], ')'); j >= 0 {
+			n = n[:i] + n[i+j+1:]
+		}
+	}
+	return timeTypes[strings.Join(strings.Fields(n), " ")]
+}
+
+// NormalizeTime converts time.Time values to loc and truncates them to a
+// multiple of precision. A nil loc keeps the original location and a zero
+// precision keeps the full precision.
+// String and []byte values in columns with a date, datetime, or timestamp
+// database type, as returned by drivers that do not parse times, are parsed
+// into time.Time; time of day columns such as MySQL TIME are left as text.
+// Text without a zone offset is read in loc, or UTC if loc is nil.
+func NormalizeTime(loc *time.Location, precision time.Duration) Option {
+	parseLoc := loc
+	if parseLoc == nil {
+		parseLoc = time.UTC
+	}
+	return WithConverter(func(col Column, v any) (any, error) {
+		var t time.Time
+		switch x := v.(type) {
+		default:
+			return v, nil
+		case time.Time:
+			t = x
+		case string, []byte:
+			if !isTimeColumn(col) {
+				return v, nil
+			}
+			s, _ := asString(x)
+			var err error
+			t, err = parseTime(s, DefaultTimeLayouts, parseLoc)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if loc != nil {
+			t = t.In(loc)
+		}
+		if precision > 0 {
+			t = t.Truncate(precision)
+		}
+		return t, nil
+	})
+}
+
+// TimeLayouts parses string and []byte values into time.Time with the first
+// matching layout, or DefaultTimeLayouts if none are given, for drivers that
+// return times as text such as SQLite. While filling, values in columns with a
+// date, datetime, or timestamp database type are parsed, while time of day and
+// interval columns are left as text. When mapping into structs, values for
+// time.Time and *time.Time fields are parsed. Text without a zone offset is
+// read in loc, or UTC if loc is nil. Text that does not match a layout is an
+// error.
+func TimeLayouts(loc *time.Location, layouts ...string) Option {
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return func(c *fillConfig) {
+		c.timeLayouts = layouts
+		c.timeLoc = loc
+		c.converters = append(c.converters, func(col Column, v any) (any, error) {
+			s, ok := asString(v)
+			if !ok || !isTimeColumn(col) {
+				return v, nil
+			}
+			return parseTime(s, layouts, loc)
+		})
+	}
+}
+
+// DefaultBoolWords is the text CoerceBools converts to bool if no words are given.
+var DefaultBoolWords = map[string]bool{
+	"1": true, "0": false,
+	"t": true, "f": false,
+	"true": true, "false": false,
+	"y": true, "n": false,
+	"yes": true, "no": false,
+	"on": true, "off": false,
+}
+
+// CoerceBools converts the representations drivers use for booleans into bool
+// when mapping into bool and *bool struct fields: integers 0 and 1, a single
+// 0 or 1 byte as MySQL returns for BIT(1), and text found in words ignoring
+// case, or in DefaultBoolWords if words is nil. While filling, values in
+// columns with a BOOL or BOOLEAN database type are also converted.
+// Other values are an error.
+func CoerceBools(words map[string]bool) Option {
+	if words == nil {
+		words = DefaultBoolWords
+	}
+	lower := make(map[string]bool, len(words))
+	for w, b := range words {
+		lower[strings.ToLower(w)] = b
+	}
+	return func(c *fillConfig) {
+		c.boolWords = lower
+		c.converters = append(c.converters, func(col Column, v any) (any, error) {
+			switch strings.ToUpper(col.DatabaseTypeName) {
+			default:
+				return v, nil
+			case "BOOL", "BOOLEAN":
+			}
+			if v == nil {
+				return nil, nil
+			}
+			return coerceBool(v, lower)
+		})
+	}
+}
+
+// coerceBool converts v to bool as described by CoerceBools.
+func coerceBool(v any, words map[string]bool) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case []byte:
+		if len(x) == 1 && x[0] <= 1 {
+			return x[0] == 1, nil
+		}
+	}
+	if s, ok := asString(v); ok {
+		if b, ok := words[strings.ToLower(strings.TrimSpace(s))]; ok {
+			return b, nil
+		}
+	} else if n, ok := asInt64(v); ok && (n == 0 || n == 1) {
+		return n == 1, nil
+	}
+	return false, fmt.Errorf("cannot coerce %T %v to bool", v, v)
+}
+
+// parseTime parses s with the first matching layout.
+func parseTime(s string, layouts []string, loc *time.Location) (time.Time, error) {
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time", s)
+}
+
+// Database type names treated as decimal columns.
+var decimalTypeNames = map[string]bool{
+	"DECIMAL":    true,
+	"NUMERIC":    true,
+	"NEWDECIMAL": true,
+	"MONEY":      true,
+	"SMALLMONEY": true,
+}
+
+// DecimalAs converts values in DECIMAL and NUMERIC columns with fn, which is
+// given the decimal text. Use DecimalString or DecimalRat, or a func returning
+// a decimal type of your choice. NULL values are not converted.
+//
+//	table.DecimalAs(func(s string) (any, error) {
+//		return decimal.NewFromString(s)
+//	})
+func DecimalAs(fn func(s string) (any, error)) Option {
+	return WithConverter(func(col Column, v any) (any, error) {
+		if v == nil || !decimalTypeNames[strings.ToUpper(col.DatabaseTypeName)] {
+			return v, nil
+		}
+		var s string
+		switch x := v.(type) {
+		case string:
+			s = x
+		case []byte:
+			s = string(x)
+		case float64:
+			s = strconv.FormatFloat(x, 'f', -1, 64)
+		case int64:
+			s = strconv.FormatInt(x, 10)
+		case fmt.Stringer:
+			s = x.String()
+		default:
+			return nil, fmt.Errorf("cannot convert %T to a decimal", v)
+		}
+		return fn(s)
+	})
+}
+
+// DecimalString keeps decimal values as their exact text.
+func DecimalString(s string) (any, error) {
+	return s, nil
+}
+
+// DecimalRat converts decimal values to a *big.Rat.
+func DecimalRat(s string) (any, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("cannot parse %q as a decimal", s)
+	}
+	return r, nil
+}
@@ -0,0 +1,309 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestConverters(t *testing.T) {
+	list := []struct {
+		Name   string
+		Result fakeResult
+		Opts   []Option
+		Want   string
+		Error  string
+	}{
+		{
+			Name: "bytes-as-string",
+			Result: fakeResult{
+				Columns: []string{"Name", "Data"},
+				Rows:    [][]driver.Value{{[]byte("R1"), []byte{1, 2}}},
+			},
+			Opts: []Option{BytesAsString("Data")},
+			Want: `[]interface {}{"R1", []uint8{0x1, 0x2}}`,
+		},
+		{
+			Name: "normalize-time",
+			Result: fakeResult{
+				Columns: []string{"At", "Day", "Note"},
+				Types:   []string{"TIMESTAMP", "DATE", "TEXT"},
+				Rows: [][]driver.Value{
+					{time.Date(2024, 1, 2, 3, 4, 5, 6789, time.FixedZone("", 3600)), []byte("2024-05-06"), "2024-05-06"},
+					{"2024-01-02 03:04:05.5", nil, nil},
+				},
+			},
+			Opts: []Option{NormalizeTime(time.UTC, time.Millisecond)},
+			Want: `[]interface {}{time.Date(2024, time.January, 2, 2, 4, 5, 0, time.UTC), time.Date(2024, time.May, 6, 0, 0, 0, 0, time.UTC), "2024-05-06"} ` +
+				`[]interface {}{time.Date(2024, time.January, 2, 3, 4, 5, 500000000, time.UTC), interface {}(nil), interface {}(nil)}`,
+		},
+		{
+			Name: "decimal",
+			Result: fakeResult{
+				Columns: []string{"Price", "Qty"},
+				Types:   []string{"DECIMAL", "INT"},
+				Rows: [][]driver.Value{
+					{[]byte("12.50"), int64(3)},
+					{float64(0.1), int64(4)},
+				},
+			},
+			Opts: []Option{DecimalAs(func(s string) (any, error) {
+				r, err := DecimalRat(s)
+				if err != nil {
+					return nil, err
+				}
+				return r.(*big.Rat).String(), nil
+			})},
+			Want: `[]interface {}{"25/2", 3} []interface {}{"1/10", 4}`,
+		},
+		{
+			Name: "uuid",
+			Result: fakeResult{
+				Columns: []string{"ID", "MSID", "Text"},
+				Types:   []string{"UUID", "UNIQUEIDENTIFIER", "TEXT"},
+				Rows: [][]driver.Value{
+					{
+						[]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+						[]byte{0x78, 0x56, 0x34, 0x12, 0xbc, 0x9a, 0xf0, 0xde, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+						"{12345678-9ABC-DEF0-1234-56789ABCDEF0}",
+					},
+					{"123456789abcdef0123456789abcdef0", nil, nil},
+				},
+			},
+			Opts: []Option{UUIDAs(UUIDString)},
+			Want: `[]interface {}{"12345678-9abc-def0-1234-56789abcdef0", "12345678-9abc-def0-1234-56789abcdef0", "{12345678-9ABC-DEF0-1234-56789ABCDEF0}"} ` +
+				`[]interface {}{"12345678-9abc-def0-1234-56789abcdef0", interface {}(nil), interface {}(nil)}`,
+		},
+		{
+			Name: "postgres-arrays",
+			Result: fakeResult{
+				Columns: []string{"IDs", "Names", "Flags", "Plain"},
+				Types:   []string{"_INT4", "TEXT[]", "_BOOL", "TEXT"},
+				Rows: [][]driver.Value{
+					{[]byte("{1,2,3}"), `{"a b","c\"d",e}`, "{t,f}", "{x}"},
+					{"{}", nil, "{}", nil},
+				},
+			},
+			Opts: []Option{PostgresArrays()},
+			Want: `[]interface {}{[]int64{1, 2, 3}, []string{"a b", "c\"d", "e"}, []bool{true, false}, "{x}"} ` +
+				`[]interface {}{[]int64{}, interface {}(nil), []bool{}, interface {}(nil)}`,
+		},
+		{
+			Name: "postgres-arrays-null",
+			Result: fakeResult{
+				Columns: []string{"IDs"},
+				Types:   []string{"_INT8"},
+				Rows:    [][]driver.Value{{"{1,NULL}"}},
+			},
+			Opts:  []Option{PostgresArrays()},
+			Error: `row 0, column "IDs": array element 1: NULL value`,
+		},
+		{
+			Name: "normalize-time-error",
+			Result: fakeResult{
+				Columns: []string{"At"},
+				Types:   []string{"DATETIME"},
+				Rows:    [][]driver.Value{{"yesterday"}},
+			},
+			Opts:  []Option{NormalizeTime(nil, 0)},
+			Error: `row 0, column "At": cannot parse "yesterday" as a time`,
+		},
+		{
+			Name: "normalize-time-of-day",
+			Result: fakeResult{
+				Columns: []string{"At", "Span", "Zoned"},
+				Types:   []string{"TIME", "TIME", "TIMETZ"},
+				Rows:    [][]driver.Value{{"12:34:56", []byte("838:59:59"), "12:34:56+02"}},
+			},
+			Opts: []Option{NormalizeTime(time.UTC, 0)},
+			Want: `[]interface {}{"12:34:56", []uint8{0x38, 0x33, 0x38, 0x3a, 0x35, 0x39, 0x3a, 0x35, 0x39}, "12:34:56+02"}`,
+		},
+		{
+			Name: "normalize-time-types",
+			Result: fakeResult{
+				Columns: []string{"A", "B", "C", "D"},
+				Types:   []string{"datetime2(7)", "TIMESTAMP(6) WITH TIME ZONE", "timestamptz", "SMALLDATETIME"},
+				Rows:    [][]driver.Value{{"2024-01-02", "2024-01-02", "2024-01-02", "2024-01-02"}},
+			},
+			Opts: []Option{NormalizeTime(time.UTC, 0)},
+			Want: `[]interface {}{time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)}`,
+		},
+		{
+			Name: "normalize-time-ranges",
+			Result: fakeResult{
+				Columns: []string{"Days", "Multi", "Span", "Updated"},
+				Types:   []string{"DATERANGE", "DATEMULTIRANGE", "TSTZRANGE", "UPDATED_DATE_TEXT"},
+				Rows:    [][]driver.Value{{"[2024-01-01,2024-02-01)", "{[2024-01-01,2024-02-01)}", "[2024-01-01 00:00+00,)", "soon"}},
+			},
+			Opts: []Option{NormalizeTime(time.UTC, 0)},
+			Want: `[]interface {}{"[2024-01-01,2024-02-01)", "{[2024-01-01,2024-02-01)}", "[2024-01-01 00:00+00,)", "soon"}`,
+		},
+		{
+			Name: "time-layouts",
+			Result: fakeResult{
+				Columns: []string{"At", "Day", "Note"},
+				Types:   []string{"DATETIME", "DATE", "TEXT"},
+				Rows: [][]driver.Value{
+					{"2024-01-02 03:04:05", []byte("02/01/2024"), "2024-05-06"},
+				},
+			},
+			Opts: []Option{TimeLayouts(time.FixedZone("", 3600), "2006-01-02 15:04:05", "02/01/2006")},
+			Want: `[]interface {}{time.Date(2024, time.January, 2, 3, 4, 5, 0, time.Location("")), time.Date(2024, time.January, 2, 0, 0, 0, 0, time.Location("")), "2024-05-06"}`,
+		},
+		{
+			Name: "time-layouts-time-of-day",
+			Result: fakeResult{
+				Columns: []string{"At", "Zoned", "Span"},
+				Types:   []string{"TIME", "TIME WITH TIME ZONE", "INTERVAL"},
+				Rows:    [][]driver.Value{{"838:59:59", "12:34:56+02", "1 day 02:00:00"}},
+			},
+			Opts: []Option{TimeLayouts(nil)},
+			Want: `[]interface {}{"838:59:59", "12:34:56+02", "1 day 02:00:00"}`,
+		},
+		{
+			Name: "coerce-bools",
+			Result: fakeResult{
+				Columns: []string{"A", "B", "C", "Note"},
+				Types:   []string{"BOOLEAN", "BOOL", "boolean", "TEXT"},
+				Rows: [][]driver.Value{
+					{int64(1), "f", nil, "t"},
+					{int64(0), []byte("Yes"), true, "f"},
+				},
+			},
+			Opts: []Option{CoerceBools(nil)},
+			Want: `[]interface {}{true, false, interface {}(nil), "t"} []interface {}{false, true, true, "f"}`,
+		},
+		{
+			Name: "coerce-bools-error",
+			Result: fakeResult{
+				Columns: []string{"A"},
+				Types:   []string{"BOOLEAN"},
+				Rows:    [][]driver.Value{{int64(2)}},
+			},
+			Opts:  []Option{CoerceBools(nil)},
+			Error: `row 0, column "A": cannot coerce int64 2 to bool`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			set, err := FillSetOpt(context.Background(), fakeRows(t, item.Result), item.Opts...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, row := range set[0].Rows {
+				got = append(got, fmt.Sprintf("%#v", row.Field))
+			}
+			if g, w := fmt.Sprint(got), "["+item.Want+"]"; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
+
+func TestTimeLayoutsStruct(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"At", "Day", "Due"},
+	}
+	b.AddRow([]any{"2024-01-02T03:04:05Z", []byte("2024-05-06"), "2024-05-06 07:08:09.5"})
+	b.AddRow([]any{"2024-01-02 03:04:05", "2024-05-06", nil})
+	type S struct {
+		At  time.Time
+		Day time.Time
+		Due *time.Time
+	}
+	list, err := BufferToStruct[S](b, TimeLayouts(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range list {
+		got = append(got, fmt.Sprint(v.At.Format(time.RFC3339), " ", v.Day.Format(time.DateOnly), " ", v.Due != nil))
+	}
+	if g, w := fmt.Sprint(got), "[2024-01-02T03:04:05Z 2024-05-06 true 2024-01-02T03:04:05Z 2024-05-06 false]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+	if g, w := list[0].Due.Nanosecond(), 500000000; g != w {
+		t.Fatalf("got %d ns want %d", g, w)
+	}
+
+	b.AddRow([]any{"soon", "2024-05-06", nil})
+	_, err = BufferToStruct[S](b, TimeLayouts(nil))
+	if g, w := fmt.Sprint(err), `row 2, column "At": cannot parse "soon" as a time into time.Time field "At"`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+}
+
+func TestCoerceBoolsStruct(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"A", "B", "C", "D"},
+	}
+	b.AddRow([]any{int64(1), []byte{0}, "Y", "si"})
+	b.AddRow([]any{float64(0), []byte{1}, []byte("N"), nil})
+	type S struct {
+		A bool
+		B bool
+		C bool
+		D *bool
+	}
+	list, err := BufferToStruct[S](b, CoerceBools(map[string]bool{"SI": true, "y": true, "n": false}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range list {
+		got = append(got, fmt.Sprint(v.A, v.B, v.C, v.D != nil && *v.D))
+	}
+	if g, w := fmt.Sprint(got), "[true false true true false true false false]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	_, err = BufferToStruct[S](b, CoerceBools(nil))
+	if g, w := fmt.Sprint(err), `row 0, column "D": cannot coerce string si to bool into bool field "D"`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+}
+
+func TestRawBytes(t *testing.T) {
+	res := fakeResult{
+		Columns: []string{"ID", "Name"},
+		Rows: [][]driver.Value{
+			{int64(1), "R1"},
+			{nil, []byte("R2")},
+		},
+	}
+	set, err := FillSetOpt(context.Background(), fakeRows(t, res), RawBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprintf("%q %q", set[0].Rows[0].Field, set[0].Rows[1].Field)
+	if w := `["1" "R1"] [<nil> "R2"]`; got != w {
+		t.Fatalf("got %s want %s", got, w)
+	}
+
+	var names []string
+	err = ForEachRow(context.Background(), fakeRows(t, res), func(row Row) error {
+		bb, err := row.GetBytes("Name")
+		if err != nil {
+			return err
+		}
+		names = append(names, string(bb))
+		return nil
+	}, Borrow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(names), "[R1 R2]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
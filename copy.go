@@ -0,0 +1,126 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BatchRows sets how many rows Copy inserts with each statement.
+// Defaults to 100. Databases limit the parameters of a statement, such as
+// 2100 for SQL Server and 65535 for Postgres, so the batch size times the
+// number of columns must stay under the limit.
+func BatchRows(n int) Option {
+	return func(c *fillConfig) {
+		if n > 0 {
+			c.batchRows = n
+		}
+	}
+}
+
+// PlaceholderFunc sets the parameter placeholder for the nth parameter,
+// starting at 1, used in generated statements. Defaults to the placeholder
+// of the dialect set by WithDialect, "?" unless set.
+func PlaceholderFunc(fn func(n int) string) Option {
+	return func(c *fillConfig) {
+		c.placeholder = fn
+	}
+}
+
+// Copy reads the rows of the first result set of the query from src and
+// inserts them into destTable of dst, returning the number of rows inserted.
+// If dst implements BulkLoader the rows are bulk loaded, otherwise they are
+// inserted in batches of BatchRows rows, and only one batch of rows is held in
+// memory. The result columns are used as the column names of destTable, and
+// names are written as given and are not quoted. Fill options, such as
+// WithConverter, apply to the rows read.
+//
+// Rows inserted before an error are not removed; pass a *sql.Tx as dst to
+// copy all rows or none.
+func Copy(ctx context.Context, src Queryer, dst Execer, sql string, destTable string, opts ...Option) (int64, error) {
+	params := make([]any, len(opts))
+	for i, o := range opts {
+		params[i] = o
+	}
+	cur, err := NewCursor(ctx, src, sql, params...)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close()
+	cur.f.borrow = false // Rows are retained for the batch.
+
+	if !cur.Next() {
+		return 0, cur.Err()
+	}
+	rs := &restartSource{
+		next:    cur.Next,
+		values:  func() []any { return cur.Row().Field },
+		err:     cur.Err,
+		pending: true,
+	}
+	n, ok, err := bulkLoad(ctx, dst, destTable, cur.Columns(), rs)
+	if ok {
+		return n, err
+	}
+
+	c := cur.f.c
+	columns := cur.Columns()
+	if len(columns) == 0 {
+		return 0, errors.New("no columns to copy")
+	}
+	args := make([]any, 0, c.batchRows*len(columns))
+	insert := func() error {
+		rows := len(args) / len(columns)
+		text := insertSQL(destTable, columns, rows, c.placeholderFunc())
+		if _, err := dst.ExecContext(ctx, text, args...); err != nil {
+			return fmt.Errorf("insert %s rows %d to %d: %w", destTable, n, n+int64(rows)-1, err)
+		}
+		n += int64(rows)
+		args = args[:0]
+		return nil
+	}
+	for more := true; more; more = cur.Next() {
+		args = append(args, cur.Row().Field...)
+		if len(args) == cap(args) {
+			if err := insert(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return n, err
+	}
+	if len(args) > 0 {
+		if err := insert(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// insertSQL returns an insert statement for rows rows of the columns.
+// A nil placeholder uses "?".
+func insertSQL(table string, columns []string, rows int, placeholder func(n int) string) string {
+	if placeholder == nil {
+		placeholder = func(int) string { return "?" }
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "insert into %s (%s) values ", table, strings.Join(columns, ", "))
+	p := 0
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for i := range columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			p++
+			sb.WriteString(placeholder(p))
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
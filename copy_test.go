@@ -0,0 +1,103 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	res := fakeResult{
+		Columns: []string{"id", "name"},
+		Rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}, {int64(3), nil}},
+	}
+	list := []struct {
+		Name string
+		Opts []Option
+		Want []string
+	}{
+		{
+			Name: "one-batch",
+			Want: []string{"insert into dst (id, name) values (?, ?), (?, ?), (?, ?) [1 a 2 b 3 <nil>]"},
+		},
+		{
+			Name: "batches",
+			Opts: []Option{BatchRows(2), PlaceholderFunc(func(n int) string { return "$" + strconv.Itoa(n) })},
+			Want: []string{
+				"insert into dst (id, name) values ($1, $2), ($3, $4) [1 a 2 b]",
+				"insert into dst (id, name) values ($1, $2) [3 <nil>]",
+			},
+		},
+		{
+			Name: "options",
+			Opts: []Option{NullAs("-"), BatchRows(3)},
+			Want: []string{"insert into dst (id, name) values (?, ?), (?, ?), (?, ?) [1 a 2 b 3 -]"},
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var rec recordExecer
+			n, err := Copy(ctx, openFake(t, fakeSet(res)), &rec, "select id, name from src", "dst", item.Opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != 3 {
+				t.Fatalf("copied got %d want 3", n)
+			}
+			if g, w := strings.Join(rec, "\n"), strings.Join(item.Want, "\n"); g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
+
+func TestCopyBulk(t *testing.T) {
+	ctx := context.Background()
+	res := fakeResult{
+		Columns: []string{"id", "name"},
+		Rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}},
+	}
+	list := []struct {
+		Name string
+		Read int   // Rows the loader reads before failing.
+		Err  error // Returned by the loader after reading.
+		Want string
+	}{
+		{Name: "bulk", Read: -1, Want: "bulk dst [id name] [[1 a] [2 b]]"},
+		{Name: "unsupported", Err: ErrBulkUnsupported, Want: "insert into dst (id, name) values (?, ?), (?, ?) [1 a 2 b]"},
+		{Name: "peeked", Read: 1, Err: ErrBulkUnsupported, Want: "bulk dst [id name] [[1 a]]\ninsert into dst (id, name) values (?, ?), (?, ?) [1 a 2 b]"},
+		{Name: "advanced", Read: 2, Err: ErrBulkUnsupported, Want: "bulk dst [id name] [[1 a] [2 b]]"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var rec recordExecer
+			loader := BulkLoaderFunc(func(ctx context.Context, table string, columns []string, rows RowSource) (int64, error) {
+				var got [][]any
+				for (item.Read < 0 || len(got) < item.Read) && rows.Next() {
+					v, _ := rows.Values()
+					got = append(got, append([]any(nil), v...))
+				}
+				if len(got) > 0 {
+					rec = append(rec, fmt.Sprint("bulk ", table, " ", columns, " ", got))
+				}
+				return int64(len(got)), item.Err
+			})
+			_, err := Copy(ctx, openFake(t, fakeSet(res)), BulkExecer(&rec, loader), "select id, name", "dst")
+			if item.Name == "advanced" {
+				if !errors.Is(err, ErrBulkUnsupported) {
+					t.Fatalf("expected ErrBulkUnsupported, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := strings.Join(rec, "\n"), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Cursor reads rows one at a time with the same name based access as a Buffer,
+// without holding the result in memory. A single row is reused for each call
+// to Next, so values returned by Row must not be retained past the next call.
+//
+//	c, err := table.NewCursor(ctx, db, "select ID, Name from Account;")
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	for c.Next() {
+//		id, err := c.GetInt64("ID")
+//		// ...
+//	}
+//	return c.Err()
+type Cursor struct {
+	f     *filler
+	table *Buffer
+	row   Row
+	first bool
+	close bool
+	err   error
+	qi    *QueryInfo
+}
+
+// NewCursor runs the query and returns a Cursor over its rows.
+// Any Option values in params configure the fill and are not sent with the query.
+// The Cursor must be closed.
+func NewCursor(ctx context.Context, q Queryer, sql string, params ...any) (*Cursor, error) {
+	rows, opts, qi, err := query(ctx, q, sql, params)
+	if err != nil {
+		return nil, err
+	}
+	c := OpenCursor(ctx, rows, opts...)
+	c.close = true
+	c.qi = qi
+	return c, nil
+}
+
+// OpenCursor returns a Cursor over rows. The caller remains responsible for
+// closing rows. Fill limits and progress options do not apply to a Cursor.
+func OpenCursor(ctx context.Context, rows *sql.Rows, opts ...Option) *Cursor {
+	f := newFiller(ctx, rows, opts)
+	f.borrow = true
+	return &Cursor{
+		f:     f,
+		table: &Buffer{},
+		first: true,
+	}
+}
+
+// Next advances to the next row, returning false at the end of the
+// result set or on error. Check Err after Next returns false.
+func (c *Cursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	if !c.f.rows.Next() {
+		if err := c.f.rows.Err(); err != nil {
+			c.err = incomplete(c.f.rowCount, err)
+		}
+		return false
+	}
+	if c.first {
+		c.first = false
+		if err := c.f.setup(c.table); err != nil {
+			c.err = err
+			return false
+		}
+		c.row = Row{
+			columnNameIndex: c.table.columnNameIndex,
+			Field:           make([]any, len(c.table.Columns)),
+		}
+	}
+	if c.f.rowCount%ctxCheckInterval == 0 {
+		if err := c.f.ctx.Err(); err != nil {
+			c.err = err
+			return false
+		}
+	}
+	clear(c.row.Field)
+	if err := c.f.scan(c.row.Field); err != nil {
+		c.err = err
+		return false
+	}
+	c.f.rowCount++
+	return true
+}
+
+// NextResultSet advances to the next result set, reporting if there is one.
+func (c *Cursor) NextResultSet() bool {
+	if c.err != nil {
+		return false
+	}
+	more, err := c.f.nextResultSet()
+	if err != nil {
+		c.err = err
+		return false
+	}
+	if more {
+		c.first = true
+		c.table = &Buffer{}
+	}
+	return more
+}
+
+// Err returns the error, if any, that stopped the Cursor.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close closes the underlying rows if the Cursor was created by NewCursor.
+func (c *Cursor) Close() error {
+	c.qi.finish(c.err, c.f.stats)
+	if c.close {
+		return c.f.rows.Close()
+	}
+	return nil
+}
+
+// Columns returns the column names of the current result set.
+// The columns are available after the first call to Next.
+func (c *Cursor) Columns() []string {
+	return c.table.Columns
+}
+
+// Row returns the current row. The row is only valid until the next call to Next.
+func (c *Cursor) Row() Row {
+	return c.row
+}
+
+// Get the field from the named column in the current row.
+func (c *Cursor) Get(columnName string) any {
+	return c.row.Get(columnName)
+}
+
+// GetString returns the named field in the current row as a string.
+func (c *Cursor) GetString(columnName string) (string, error) {
+	return c.row.GetString(columnName)
+}
+
+// GetBytes returns the named field in the current row as a []byte.
+// The slice is only valid until the next call to Next.
+func (c *Cursor) GetBytes(columnName string) ([]byte, error) {
+	return c.row.GetBytes(columnName)
+}
+
+// GetInt64 returns the named field in the current row as an int64.
+func (c *Cursor) GetInt64(columnName string) (int64, error) {
+	return c.row.GetInt64(columnName)
+}
+
+// GetFloat64 returns the named field in the current row as a float64.
+func (c *Cursor) GetFloat64(columnName string) (float64, error) {
+	return c.row.GetFloat64(columnName)
+}
+
+// GetBool returns the named field in the current row as a bool.
+func (c *Cursor) GetBool(columnName string) (bool, error) {
+	return c.row.GetBool(columnName)
+}
+
+// GetTime returns the named field in the current row as a time.Time.
+func (c *Cursor) GetTime(columnName string) (time.Time, error) {
+	return c.row.GetTime(columnName)
+}
@@ -0,0 +1,65 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	db := openFake(t, fakeSet(
+		fakeResult{
+			Columns: []string{"ID", "Name"},
+			Rows: [][]driver.Value{
+				{int64(1), []byte("R1")},
+				{int64(2), nil},
+			},
+		},
+		fakeResult{
+			Columns: []string{"Total"},
+			Rows:    [][]driver.Value{{float64(2.5)}},
+		},
+	))
+	c, err := NewCursor(context.Background(), db, "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var got []string
+	for c.Next() {
+		id, err := c.GetInt64("ID")
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, err := c.GetString("Name")
+		var ne *NullError
+		if errors.As(err, &ne) {
+			name = "<null>"
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%d:%s", id, name))
+	}
+	if !c.NextResultSet() {
+		t.Fatal("expected a second result set")
+	}
+	for c.Next() {
+		total, err := c.GetFloat64("Total")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetBool("Total"); err == nil {
+			t.Fatal("expected a type error")
+		}
+		got = append(got, fmt.Sprint(c.Columns(), total))
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got), "[1:R1 2:<null> [Total] 2.5]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
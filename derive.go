@@ -0,0 +1,115 @@
+package table
+
+import (
+	"database/sql"
+	"sort"
+)
+
+// Filter returns a new buffer with the rows where keep returns true.
+//
+// The new buffer shares field storage with b rather then copying it, so
+// chaining Filter, Select, and Sort over a large buffer does not copy the
+// rows at each step. Buffer methods that modify field values, such as
+// Coalesce and Mask, first copy the fields of the buffer they are called on,
+// so a change to either buffer is never seen by the other. Assigning to
+// Row.Field directly bypasses this and changes both buffers.
+//
+// Rows on disk are read into the new buffer; Filter panics if they can not
+// be read.
+func (b *Buffer) Filter(keep func(row Row) bool) *Buffer {
+	d := b.derive()
+	b.eachRow(func(_ int, row Row) {
+		if keep(row) {
+			d.Rows = append(d.Rows, row)
+		}
+	})
+	return d
+}
+
+// Select returns a new buffer with only the named columns, in the order given.
+// Each row gets new field storage holding the selected values, so unlike
+// Filter and Sort the fields are copied, though the values themselves are not.
+func (b *Buffer) Select(columns ...string) (*Buffer, error) {
+	index := make([]int, len(columns))
+	for i, n := range columns {
+		ci, err := b.lookupColumn(n)
+		if err != nil {
+			return nil, err
+		}
+		index[i] = ci
+	}
+	d := &Buffer{
+		Columns:     append([]string(nil), columns...),
+		Rows:        make([]Row, 0, b.Len()),
+		name:        b.name,
+		columnTypes: make([]*sql.ColumnType, 0, len(columns)),
+	}
+	for _, ci := range index {
+		if ci < len(b.columnTypes) {
+			d.columnTypes = append(d.columnTypes, b.columnTypes[ci])
+		}
+	}
+	if len(d.columnTypes) != len(columns) {
+		d.columnTypes = nil
+	}
+	d.buildIndex()
+	err := b.Each(func(_ int, row Row) error {
+		field := make([]any, len(index))
+		for i, ci := range index {
+			field[i] = row.Field[ci]
+		}
+		d.Rows = append(d.Rows, Row{columnNameIndex: d.columnNameIndex, Field: field})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Sort returns a new buffer with the rows ordered by less, keeping the
+// original order of equal rows. The new buffer shares field storage with b
+// as described by Filter, and reads rows on disk as Filter does.
+func (b *Buffer) Sort(less func(a, b Row) bool) *Buffer {
+	d := b.derive()
+	b.eachRow(func(_ int, row Row) {
+		d.Rows = append(d.Rows, row)
+	})
+	sort.SliceStable(d.Rows, func(i, j int) bool {
+		return less(d.Rows[i], d.Rows[j])
+	})
+	return d
+}
+
+// derive returns an empty buffer with the same columns as b whose rows will
+// share field storage with b.
+func (b *Buffer) derive() *Buffer {
+	b.buildIndex()
+	// A frozen buffer is never modified, so it need not copy its own fields
+	// later, and must not be written to here.
+	if !b.frozen {
+		b.shared = true
+	}
+	return &Buffer{
+		Columns:         b.Columns,
+		Rows:            make([]Row, 0, b.Len()),
+		name:            b.name,
+		columnTypes:     b.columnTypes,
+		columnNameIndex: b.columnNameIndex,
+		duplicates:      b.duplicates,
+		hasDuplicates:   b.hasDuplicates,
+		shared:          true,
+	}
+}
+
+// unshare copies the field storage of every row if it may be shared with
+// another buffer. Call it before modifying field values.
+func (b *Buffer) unshare() {
+	if !b.shared {
+		return
+	}
+	for i, row := range b.Rows {
+		b.Rows[i].Field = append([]any(nil), row.Field...)
+	}
+	b.shared = false
+}
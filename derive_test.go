@@ -0,0 +1,73 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDerive(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name", "Score"},
+	}
+	b.AddRow([]any{int64(1), "R1", nil})
+	b.AddRow([]any{int64(2), "R2", int64(20)})
+	b.AddRow([]any{int64(3), "R3", int64(10)})
+
+	rows := func(b *Buffer) string {
+		fields := make([][]any, len(b.Rows))
+		for i, row := range b.Rows {
+			fields[i] = row.Field
+		}
+		return fmt.Sprint(b.Columns, fields)
+	}
+
+	filtered := b.Filter(func(row Row) bool { return row.Get("ID") != int64(2) })
+	sorted := filtered.Sort(func(a, b Row) bool { return a.Get("ID").(int64) > b.Get("ID").(int64) })
+	selected, err := sorted.Select("Score", "ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sorted.Select("Missing"); err == nil {
+		t.Fatal("expected error for missing column")
+	}
+
+	list := []struct {
+		Name string
+		Buf  *Buffer
+		Want string
+	}{
+		{"filter", filtered, "[ID Name Score] [[1 R1 <nil>] [3 R3 10]]"},
+		{"sort", sorted, "[ID Name Score] [[3 R3 10] [1 R1 <nil>]]"},
+		{"select", selected, "[Score ID] [[10 3] [<nil> 1]]"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			if g, w := rows(item.Buf), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+	if g := selected.Get(0, "ID"); g != int64(3) {
+		t.Fatalf("expected selected ID 3, got %v", g)
+	}
+
+	// Storage is shared until modified.
+	if &filtered.Rows[0].Field[0] != &b.Rows[0].Field[0] {
+		t.Fatal("expected filter to share field storage")
+	}
+	if err := sorted.Coalesce("Score", int64(0)); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rows(sorted), "[ID Name Score] [[3 R3 10] [1 R1 0]]"; g != w {
+		t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+	}
+	if err := b.Coalesce("Score", int64(-1)); err != nil {
+		t.Fatal(err)
+	}
+	if g := b.Get(0, "Score"); g != int64(-1) {
+		t.Fatalf("expected Score -1, got %v", g)
+	}
+	if g := filtered.Get(0, "Score"); g != nil {
+		t.Fatalf("modified shared storage, got %v", g)
+	}
+}
@@ -0,0 +1,80 @@
+package table
+
+import "strconv"
+
+// Dialect selects the SQL syntax of statements generated by this package,
+// such as by PaginateKeyset and Copy. Generated statements write table and
+// column names as given; quote them first if needed.
+type Dialect byte
+
+const (
+	// DialectGeneric uses "?" placeholders and LIMIT.
+	DialectGeneric Dialect = iota
+	// DialectPostgres uses "$1" placeholders.
+	DialectPostgres
+	// DialectMySQL uses the generic syntax.
+	DialectMySQL
+	// DialectSQLite uses the generic syntax.
+	DialectSQLite
+	// DialectSQLServer uses "@p1" placeholders and TOP or OFFSET FETCH in
+	// place of LIMIT.
+	DialectSQLServer
+)
+
+func (d Dialect) String() string {
+	switch d {
+	default:
+		return "Dialect(" + strconv.Itoa(int(d)) + ")"
+	case DialectGeneric:
+		return "generic"
+	case DialectPostgres:
+		return "postgres"
+	case DialectMySQL:
+		return "mysql"
+	case DialectSQLite:
+		return "sqlite"
+	case DialectSQLServer:
+		return "sqlserver"
+	}
+}
+
+// WithDialect sets the dialect of generated statements. The default is
+// DialectGeneric.
+func WithDialect(d Dialect) Option {
+	return func(c *fillConfig) {
+		c.dialect = d
+	}
+}
+
+// Placeholder returns the parameter placeholder for the nth parameter,
+// starting at 1.
+func (d Dialect) Placeholder(n int) string {
+	switch d {
+	case DialectPostgres:
+		return "$" + strconv.Itoa(n)
+	case DialectSQLServer:
+		return "@p" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// limit returns a select of at most limit rows of the query after skipping
+// offset rows. The query must end with its ORDER BY clause.
+func (d Dialect) limit(query string, limit, offset int) string {
+	if d == DialectSQLServer {
+		return query + " offset " + strconv.Itoa(offset) + " rows fetch next " + strconv.Itoa(limit) + " rows only"
+	}
+	query += " limit " + strconv.Itoa(limit)
+	if offset > 0 {
+		query += " offset " + strconv.Itoa(offset)
+	}
+	return query
+}
+
+// placeholderFunc returns the PlaceholderFunc if set, or the dialect placeholder.
+func (c *fillConfig) placeholderFunc() func(n int) string {
+	if c.placeholder != nil {
+		return c.placeholder
+	}
+	return c.dialect.Placeholder
+}
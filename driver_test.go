@@ -0,0 +1,166 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeResult is a single result set returned by the fake driver.
+type fakeResult struct {
+	Columns []string
+	Types   []string // Optional database type names.
+	Rows    [][]driver.Value
+
+	// Err is returned from Next after all Rows are read.
+	Err error
+
+	// RowsAffected is returned by ExecContext for the first result.
+	RowsAffected int64
+}
+
+// fakeQuery returns the result sets for a query.
+type fakeQuery func(query string, args []driver.NamedValue) ([]fakeResult, error)
+
+// openFake returns a database that answers every query with q.
+func openFake(t testing.TB, q fakeQuery) *sql.DB {
+	db := sql.OpenDB(&fakeConnector{query: q})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// fakeSet returns a fakeQuery that always returns the given result sets.
+func fakeSet(results ...fakeResult) fakeQuery {
+	return func(string, []driver.NamedValue) ([]fakeResult, error) {
+		return results, nil
+	}
+}
+
+// fakeRows queries a fake database returning results.
+func fakeRows(t testing.TB, results ...fakeResult) *sql.Rows {
+	db := openFake(t, fakeSet(results...))
+	rows, err := db.QueryContext(context.Background(), "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rows.Close() })
+	return rows
+}
+
+type fakeConnector struct {
+	query fakeQuery
+}
+
+func (fc *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{query: fc.query}, nil
+}
+
+func (fc *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver must be opened with openFake")
+}
+
+type fakeConn struct {
+	query fakeQuery
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	results, err := c.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		results = []fakeResult{{}}
+	}
+	return &fakeDriverRows{results: results}, nil
+}
+
+// ExecContext runs the statement through the fake query, discarding any
+// results other then the RowsAffected of the first.
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	results, err := c.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return driver.RowsAffected(0), nil
+	}
+	return driver.RowsAffected(results[0].RowsAffected), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeDriverRows struct {
+	results []fakeResult
+	set     int
+	row     int
+}
+
+func (r *fakeDriverRows) Columns() []string {
+	return r.results[r.set].Columns
+}
+
+func (r *fakeDriverRows) Close() error {
+	return nil
+}
+
+func (r *fakeDriverRows) Next(dest []driver.Value) error {
+	res := r.results[r.set]
+	if r.row >= len(res.Rows) {
+		if res.Err != nil {
+			return res.Err
+		}
+		return io.EOF
+	}
+	copy(dest, res.Rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *fakeDriverRows) HasNextResultSet() bool {
+	return r.set+1 < len(r.results)
+}
+
+func (r *fakeDriverRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.row = 0
+	return nil
+}
+
+func (r *fakeDriverRows) ColumnTypeDatabaseTypeName(index int) string {
+	types := r.results[r.set].Types
+	if index < len(types) {
+		return types[index]
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// FillSetDriver fills a set like FillSetOpt from the rows of a driver, such
+// as inside a driver, proxy, or connection pool middleware where *sql.Rows is
+// not available. Every result set is read if rows implements
+// driver.RowsNextResultSet. Values are stored as the driver returns them,
+// except []byte values are copied, so no driver.Valuer conversion or
+// sql.Scanner is applied. The rows are not closed.
+// When an error is returned, the set read up to that point is also returned.
+func FillSetDriver(ctx context.Context, rows driver.Rows, opts ...Option) (Set, error) {
+	f := newFiller(ctx, &driverRows{rows: rows}, opts)
+	return f.fillSet(time.Now())
+}
+
+// driverRows reads driver.Rows like *sql.Rows.
+type driverRows struct {
+	rows   driver.Rows
+	values []driver.Value
+	err    error
+}
+
+func (dr *driverRows) Columns() ([]string, error) {
+	return dr.rows.Columns(), nil
+}
+
+// ColumnTypes returns no column types, which cannot be created outside of
+// database/sql; see databaseTypeName.
+func (dr *driverRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	return nil, nil
+}
+
+func (dr *driverRows) databaseTypeName(i int) string {
+	if t, ok := dr.rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(i)
+	}
+	return ""
+}
+
+func (dr *driverRows) Next() bool {
+	if dr.err != nil {
+		return false
+	}
+	if dr.values == nil {
+		dr.values = make([]driver.Value, len(dr.rows.Columns()))
+	}
+	err := dr.rows.Next(dr.values)
+	if err != nil {
+		if err != io.EOF {
+			dr.err = err
+		}
+		return false
+	}
+	return true
+}
+
+func (dr *driverRows) Scan(dest ...any) error {
+	if len(dest) != len(dr.values) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(dr.values), len(dest))
+	}
+	for i, d := range dest {
+		v := dr.values[i]
+		switch d := d.(type) {
+		default:
+			return fmt.Errorf("unsupported Scan destination %T", d)
+		case *any:
+			if bb, ok := v.([]byte); ok {
+				v = bytes.Clone(bb)
+			}
+			*d = v
+		case *sql.RawBytes:
+			rb, err := rawBytes(v)
+			if err != nil {
+				return fmt.Errorf("converting column index %d: %w", i, err)
+			}
+			*d = rb
+		}
+	}
+	return nil
+}
+
+// rawBytes returns the text form of a driver value, as database/sql scans it
+// into sql.RawBytes.
+func rawBytes(v driver.Value) (sql.RawBytes, error) {
+	switch v := v.(type) {
+	default:
+		return nil, fmt.Errorf("unsupported type %T into sql.RawBytes", v)
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return sql.RawBytes(v), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	case bool:
+		return strconv.AppendBool(nil, v), nil
+	case time.Time:
+		return v.AppendFormat(nil, time.RFC3339Nano), nil
+	}
+}
+
+func (dr *driverRows) Err() error {
+	return dr.err
+}
+
+func (dr *driverRows) NextResultSet() bool {
+	if dr.err != nil {
+		return false
+	}
+	nrs, ok := dr.rows.(driver.RowsNextResultSet)
+	if !ok || !nrs.HasNextResultSet() {
+		return false
+	}
+	if err := nrs.NextResultSet(); err != nil {
+		if !errors.Is(err, io.EOF) {
+			dr.err = err
+		}
+		return false
+	}
+	dr.values = nil
+	return true
+}
+
+func (dr *driverRows) Close() error {
+	return dr.rows.Close()
+}
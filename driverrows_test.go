@@ -0,0 +1,87 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFillSetDriver(t *testing.T) {
+	ctx := context.Background()
+	errRead := errors.New("read failed")
+	data := []byte("d1")
+
+	list := []struct {
+		Name    string
+		Results []fakeResult
+		Opts    []Option
+		Want    string
+		Error   string
+	}{
+		{
+			Name: "sets",
+			Results: []fakeResult{
+				{Columns: []string{"ID", "Data"}, Rows: [][]driver.Value{{int64(1), data}, {int64(2), nil}}},
+				{Columns: []string{"Name"}, Rows: [][]driver.Value{{"R1"}}},
+			},
+			Want: "[ID Data] [[1 [100 49]] [2 <nil>]]; [Name] [[R1]]",
+		},
+		{
+			Name: "raw bytes",
+			Results: []fakeResult{
+				{Columns: []string{"ID", "OK", "Name"}, Rows: [][]driver.Value{{int64(1), true, "R1"}}},
+			},
+			Opts: []Option{RawBytes()},
+			Want: "[ID OK Name] [[[49] [116 114 117 101] [82 49]]]",
+		},
+		{
+			Name: "type converter",
+			Results: []fakeResult{
+				{Columns: []string{"ID"}, Types: []string{"BIGINT"}, Rows: [][]driver.Value{{int64(1)}}},
+			},
+			Opts: []Option{WithConverter(func(col Column, v any) (any, error) {
+				return col.DatabaseTypeName, nil
+			})},
+			Want: "[ID] [[BIGINT]]",
+		},
+		{
+			Name: "error",
+			Results: []fakeResult{
+				{Columns: []string{"ID"}, Rows: [][]driver.Value{{int64(1)}}, Err: errRead},
+			},
+			Want:  "[ID] [[1]]",
+			Error: "result incomplete after 1 rows: read failed",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			set, err := FillSetDriver(ctx, &fakeDriverRows{results: item.Results}, item.Opts...)
+			if g, w := fmt.Sprint(err), item.Error; (err != nil || w != "") && g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			var got []string
+			for _, b := range set {
+				var rows []any
+				for _, row := range b.Rows {
+					rows = append(rows, row.Field)
+				}
+				got = append(got, fmt.Sprintf("%v %v", b.Columns, rows))
+			}
+			if g := strings.Join(got, "; "); g != item.Want {
+				t.Fatalf("got %s want %s", g, item.Want)
+			}
+		})
+	}
+
+	set, err := FillSetDriver(ctx, &fakeDriverRows{results: []fakeResult{{Columns: []string{"Data"}, Rows: [][]driver.Value{{data}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 'x'
+	if g, w := fmt.Sprintf("%s", set[0].Get(0, "Data")), "d1"; g != w {
+		t.Fatalf("driver bytes not copied, got %s want %s", g, w)
+	}
+}
@@ -0,0 +1,109 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// DuplicatePolicy selects how duplicate column names in a result are handled,
+// such as from "select a.id, b.id".
+type DuplicatePolicy byte
+
+const (
+	// DuplicateLast looks up a duplicate name as the last column with that name.
+	DuplicateLast DuplicatePolicy = iota
+	// DuplicateError fails the fill with an error matching ErrDuplicateColumn.
+	DuplicateError
+	// DuplicateSuffix renames later duplicates with a numeric suffix: id, id_2, id_3.
+	DuplicateSuffix
+	// DuplicatePositional leaves duplicate names out of the name lookup, so the
+	// columns may only be accessed by index.
+	DuplicatePositional
+)
+
+func (p DuplicatePolicy) String() string {
+	switch p {
+	default:
+		return "DuplicatePolicy(" + strconv.Itoa(int(p)) + ")"
+	case DuplicateLast:
+		return "last"
+	case DuplicateError:
+		return "error"
+	case DuplicateSuffix:
+		return "suffix"
+	case DuplicatePositional:
+		return "positional"
+	}
+}
+
+// ErrDuplicateColumn is matched by errors.Is when a result has duplicate
+// column names and the DuplicateError policy is set.
+var ErrDuplicateColumn = errors.New("duplicate column")
+
+// DuplicateColumns sets the policy for duplicate column names.
+// The default is DuplicateLast.
+func DuplicateColumns(policy DuplicatePolicy) Option {
+	return func(c *fillConfig) {
+		c.duplicates = policy
+	}
+}
+
+// DuplicatePolicy reports the policy applied to the buffer columns and if
+// any duplicate column names were found.
+func (t *Buffer) DuplicatePolicy() (DuplicatePolicy, bool) {
+	return t.duplicates, t.hasDuplicates
+}
+
+// indexColumns creates the column name lookup for the buffer, applying the
+// duplicate policy. Renamed columns are also updated in cols.
+func (c *fillConfig) indexColumns(table *Buffer, cols []Column) error {
+	cni := make(map[string]int, len(table.Columns))
+	table.duplicates = c.duplicates
+	table.hasDuplicates = false
+	var dup map[string]bool
+	for i, n := range table.Columns {
+		prev, ok := cni[n]
+		if !ok {
+			cni[n] = i
+			continue
+		}
+		table.hasDuplicates = true
+		switch c.duplicates {
+		default:
+			cni[n] = i
+		case DuplicateError:
+			return fmt.Errorf("%w %q at index %d and %d", ErrDuplicateColumn, n, prev, i)
+		case DuplicateSuffix:
+			for k := 2; ; k++ {
+				s := n + "_" + strconv.Itoa(k)
+				if _, ok := cni[s]; !ok && !hasColumn(table.Columns[i+1:], s) {
+					n = s
+					break
+				}
+			}
+			table.Columns[i] = n
+			cols[i].Name = n
+			cni[n] = i
+		case DuplicatePositional:
+			if dup == nil {
+				dup = make(map[string]bool)
+			}
+			dup[n] = true
+		}
+	}
+	for n := range dup {
+		delete(cni, n)
+	}
+	table.columnNameIndex = cni
+	return nil
+}
+
+func hasColumn(columns []string, name string) bool {
+	for _, n := range columns {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
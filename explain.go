@@ -0,0 +1,61 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Explain returns the query plan of the statement in the plan format of the
+// database, without running it where the database allows:
+//
+//   - DialectGeneric, DialectPostgres, and DialectMySQL use EXPLAIN.
+//   - DialectSQLite uses EXPLAIN QUERY PLAN.
+//   - DialectSQLServer uses SET SHOWPLAN_ALL, which must be set on the same
+//     connection as the statement, so q must be a *sql.DB, *sql.Conn, or
+//     *sql.Tx.
+//
+// Any Option values in params configure the fill and are not sent with the
+// query. Database specific options, such as EXPLAIN ANALYZE in Postgres, may
+// be written in the statement by calling NewBuffer directly.
+func Explain(ctx context.Context, q Queryer, dialect Dialect, sql string, params ...any) (*Buffer, error) {
+	switch dialect {
+	case DialectSQLite:
+		return NewBuffer(ctx, q, "explain query plan "+sql, params...)
+	case DialectSQLServer:
+		return explainSQLServer(ctx, q, sql, params)
+	}
+	return NewBuffer(ctx, q, "explain "+sql, params...)
+}
+
+func explainSQLServer(ctx context.Context, q Queryer, text string, params []any) (_ *Buffer, err error) {
+	type execQueryer interface {
+		Queryer
+		Execer
+	}
+	var conn execQueryer
+	switch q := q.(type) {
+	case *sql.DB:
+		c, err := q.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		conn = c
+	case *sql.Conn:
+		conn = q
+	case *sql.Tx:
+		conn = q
+	default:
+		return nil, fmt.Errorf("explain with %v requires a *sql.DB, *sql.Conn, or *sql.Tx, got %T", DialectSQLServer, q)
+	}
+	if _, err := conn.ExecContext(ctx, "set showplan_all on"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, offErr := conn.ExecContext(context.WithoutCancel(ctx), "set showplan_all off")
+		err = errors.Join(err, offErr)
+	}()
+	return NewBuffer(ctx, conn, text, params...)
+}
@@ -0,0 +1,46 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	list := []struct {
+		Dialect Dialect
+		Queries []string
+	}{
+		{Dialect: DialectPostgres, Queries: []string{"explain select ID from T where ID = $1 [1]"}},
+		{Dialect: DialectSQLite, Queries: []string{"explain query plan select ID from T where ID = $1 [1]"}},
+		{Dialect: DialectSQLServer, Queries: []string{
+			"set showplan_all on []",
+			"select ID from T where ID = $1 [1]",
+			"set showplan_all off []",
+		}},
+	}
+	for _, item := range list {
+		t.Run(item.Dialect.String(), func(t *testing.T) {
+			var queries []string
+			db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+				values := make([]any, len(args))
+				for i, a := range args {
+					values[i] = a.Value
+				}
+				queries = append(queries, fmt.Sprint(query, " ", values))
+				return []fakeResult{{Columns: []string{"QUERY PLAN"}, Rows: [][]driver.Value{{"Seq Scan"}}}}, nil
+			})
+			b, err := Explain(context.Background(), db, item.Dialect, "select ID from T where ID = $1", int64(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b.Rows) != 1 {
+				t.Fatalf("expected 1 plan row, got %d", len(b.Rows))
+			}
+			if g, w := fmt.Sprint(queries), fmt.Sprint(item.Queries); g != w {
+				t.Fatalf("queries got\n%s\nwant\n%s", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package table
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TextOption configures the text writers.
+type TextOption func(*textConfig)
+
+type textConfig struct {
+	header     bool
+	null       string
+	timeLayout string
+
+	// Fixed width settings.
+	pad        rune
+	truncate   bool
+	alignRight map[string]bool
+}
+
+func newTextConfig(opts []TextOption) *textConfig {
+	c := &textConfig{
+		header:     true,
+		timeLayout: time.RFC3339Nano,
+		pad:        ' ',
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Header sets if the column names are written as the first record.
+// Defaults to true.
+func Header(include bool) TextOption {
+	return func(c *textConfig) {
+		c.header = include
+	}
+}
+
+// NullText sets the text written for NULL values. Defaults to an empty string.
+func NullText(s string) TextOption {
+	return func(c *textConfig) {
+		c.null = s
+	}
+}
+
+// TimeLayout sets the layout used to format time.Time values.
+// Defaults to time.RFC3339Nano.
+func TimeLayout(layout string) TextOption {
+	return func(c *textConfig) {
+		c.timeLayout = layout
+	}
+}
+
+// PadRune sets the rune used to pad fixed width fields. Defaults to a space.
+func PadRune(r rune) TextOption {
+	return func(c *textConfig) {
+		c.pad = r
+	}
+}
+
+// TruncateFields cuts fixed width fields that are too long rather then
+// returning an error.
+func TruncateFields() TextOption {
+	return func(c *textConfig) {
+		c.truncate = true
+	}
+}
+
+// AlignRight pads the named fixed width columns on the left.
+// Columns are left aligned by default.
+func AlignRight(columns ...string) TextOption {
+	return func(c *textConfig) {
+		if c.alignRight == nil {
+			c.alignRight = make(map[string]bool, len(columns))
+		}
+		for _, n := range columns {
+			c.alignRight[n] = true
+		}
+	}
+}
+
+// format a single field value as text.
+func (c *textConfig) format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return c.null
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(c.timeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// WriteDelimited writes the buffer as delimited text records separated by delim.
+// Fields that contain the delimiter, quotes, or line breaks are quoted.
+// The delimiter may not be a quote, carriage return, or line feed.
+func (b *Buffer) WriteDelimited(w io.Writer, delim rune, opts ...TextOption) error {
+	// Check the delimiter as csv.Writer does, but before anything is written.
+	if delim == 0 || delim == '"' || delim == '\r' || delim == '\n' || !utf8.ValidRune(delim) || delim == utf8.RuneError {
+		return fmt.Errorf("invalid delimiter %q", delim)
+	}
+	c := newTextConfig(opts)
+	cw := csv.NewWriter(w)
+	cw.Comma = delim
+
+	if c.header {
+		if err := cw.Write(b.Columns); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(b.Columns))
+	err := b.Each(func(_ int, row Row) error {
+		for i, f := range row.Field {
+			record[i] = c.format(f)
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes the buffer as comma separated values.
+func (b *Buffer) WriteCSV(w io.Writer, opts ...TextOption) error {
+	return b.WriteDelimited(w, ',', opts...)
+}
+
+// WriteTSV writes the buffer as tab separated values.
+func (b *Buffer) WriteTSV(w io.Writer, opts ...TextOption) error {
+	return b.WriteDelimited(w, '\t', opts...)
+}
+
+// WriteFixedWidth writes the buffer as fixed width records, one per line.
+// Only columns present in widths are written, in buffer column order.
+// Widths are measured in runes. A value longer then its width is an error
+// unless TruncateFields is set. No header is written unless Header(true) is set.
+func (b *Buffer) WriteFixedWidth(w io.Writer, widths map[string]int, opts ...TextOption) error {
+	c := newTextConfig(append([]TextOption{Header(false)}, opts...))
+
+	type fixedColumn struct {
+		index int
+		name  string
+		width int
+		right bool
+	}
+	cols := make([]fixedColumn, 0, len(widths))
+	for i, n := range b.Columns {
+		width, ok := widths[n]
+		if !ok {
+			continue
+		}
+		if width <= 0 {
+			return fmt.Errorf("column %q has invalid width %d", n, width)
+		}
+		cols = append(cols, fixedColumn{index: i, name: n, width: width, right: c.alignRight[n]})
+	}
+	if len(cols) != len(widths) {
+		have := make(map[string]bool, len(cols))
+		for _, col := range cols {
+			have[col.name] = true
+		}
+		for n := range widths {
+			if !have[n] {
+				return &IndexError{subject: SubjectName, notFoundName: n}
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	line := &strings.Builder{}
+	writeLine := func(rowIndex int, value func(col fixedColumn) string) error {
+		line.Reset()
+		for _, col := range cols {
+			v := value(col)
+			n := utf8.RuneCountInString(v)
+			if n > col.width {
+				if !c.truncate {
+					return fmt.Errorf("row %d, column %q: value %q exceeds width %d", rowIndex, col.name, v, col.width)
+				}
+				v = string([]rune(v)[:col.width])
+				n = col.width
+			}
+			pad := strings.Repeat(string(c.pad), col.width-n)
+			if col.right {
+				line.WriteString(pad)
+				line.WriteString(v)
+			} else {
+				line.WriteString(v)
+				line.WriteString(pad)
+			}
+		}
+		line.WriteByte('\n')
+		_, err := bw.WriteString(line.String())
+		return err
+	}
+
+	if c.header {
+		err := writeLine(-1, func(col fixedColumn) string { return col.name })
+		if err != nil {
+			return err
+		}
+	}
+	err := b.Each(func(ri int, row Row) error {
+		return writeLine(ri, func(col fixedColumn) string { return c.format(row.Field[col.index]) })
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,135 @@
+package table
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDelimited(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name", "Note"},
+	}
+	b.AddRow([]any{int64(1), "R1", nil})
+	b.AddRow([]any{int64(2), "R\t2", []byte(`say "hi"`)})
+	b.AddRow([]any{float64(1000000), "R|3", "a\nb"})
+
+	list := []struct {
+		Name  string
+		Empty bool // Write a buffer without rows.
+		Delim rune
+		Opts  []TextOption
+		Want  string
+		Error string
+	}{
+		{
+			Name:  "csv",
+			Delim: ',',
+			Want:  "ID,Name,Note\n1,R1,\n2,R\t2,\"say \"\"hi\"\"\"\n1000000,R|3,\"a\nb\"\n",
+		},
+		{
+			Name:  "tsv",
+			Delim: '\t',
+			Opts:  []TextOption{NullText("NULL")},
+			Want:  "ID\tName\tNote\n1\tR1\tNULL\n2\t\"R\t2\"\t\"say \"\"hi\"\"\"\n1000000\tR|3\t\"a\nb\"\n",
+		},
+		{
+			Name:  "pipe",
+			Delim: '|',
+			Opts:  []TextOption{Header(false)},
+			Want:  "1|R1|\n2|R\t2|\"say \"\"hi\"\"\"\n1000000|\"R|3\"|\"a\nb\"\n",
+		},
+		{
+			Name:  "invalid",
+			Delim: '"',
+			Error: `invalid delimiter '"'`,
+		},
+		{
+			Name:  "invalid-empty",
+			Empty: true,
+			Delim: '\n',
+			Opts:  []TextOption{Header(false)},
+			Error: `invalid delimiter '\n'`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := b
+			if item.Empty {
+				b = &Buffer{Columns: b.Columns}
+			}
+			sb := &strings.Builder{}
+			err := b.WriteDelimited(sb, item.Delim, item.Opts...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := sb.String(), item.Want; g != w {
+				t.Fatalf("got:\n%q\n\nwant:\n%q\n", g, w)
+			}
+		})
+	}
+}
+
+func TestWriteFixedWidth(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name", "Skip"},
+	}
+	b.AddRow([]any{int64(1), "Ann", "x"})
+	b.AddRow([]any{int64(22), "Bartholomew", "y"})
+
+	list := []struct {
+		Name   string
+		Widths map[string]int
+		Opts   []TextOption
+		Want   string
+		Error  string
+	}{
+		{
+			Name:   "too-long",
+			Widths: map[string]int{"ID": 3, "Name": 5},
+			Error:  `row 1, column "Name": value "Bartholomew" exceeds width 5`,
+		},
+		{
+			Name:   "truncate",
+			Widths: map[string]int{"ID": 3, "Name": 5},
+			Opts:   []TextOption{TruncateFields(), AlignRight("ID"), PadRune('0')},
+			Want:   "001Ann00\n022Barth\n",
+		},
+		{
+			Name:   "header",
+			Widths: map[string]int{"ID": 3, "Name": 12},
+			Opts:   []TextOption{Header(true)},
+			Want:   "ID Name        \n1  Ann         \n22 Bartholomew \n",
+		},
+		{
+			Name:   "missing",
+			Widths: map[string]int{"ID": 3, "Age": 2},
+			Error:  `Table doesn't have column named "Age"`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			err := b.WriteFixedWidth(sb, item.Widths, item.Opts...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := sb.String(), item.Want; g != w {
+				t.Fatalf("got:\n%q\n\nwant:\n%q\n", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FieldError reports a failure to convert or map a single field value,
+// with the row and column it came from.
+type FieldError struct {
+	Row    int    // Row index within the result set.
+	Column string // Buffer column name.
+	Field  string // Struct field name, empty when not mapping to a struct.
+	Value  any    // Value being converted.
+	Err    error
+}
+
+func (fe *FieldError) Error() string {
+	if len(fe.Field) == 0 {
+		return fmt.Sprintf("row %d, column %q: %v", fe.Row, fe.Column, fe.Err)
+	}
+	return fmt.Sprintf("row %d, column %q: %v field %q", fe.Row, fe.Column, fe.Err, fe.Field)
+}
+
+func (fe *FieldError) Unwrap() error {
+	return fe.Err
+}
+
+// rowError adds the row index to err.
+func rowError(row int, err error) error {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		fe.Row = row
+		return err
+	}
+	return fmt.Errorf("row %d: %w", row, err)
+}
@@ -0,0 +1,377 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Number of rows read between checks for a canceled context.
+const ctxCheckInterval = 64
+
+// FillSetOpt fills a set like FillSet, configured by the given options.
+// When an error is returned, the set read up to that point is also returned.
+// The context is checked periodically while reading rows and between result
+// sets, so a canceled context stops the fill with the context error.
+func FillSetOpt(ctx context.Context, rows *sql.Rows, opts ...Option) (Set, error) {
+	f := newFiller(ctx, rows, opts)
+	return f.fillSet(time.Now())
+}
+
+// FillResultSet reads the current result set of rows into a new buffer,
+// without advancing to the next result set. It is for callers that control
+// the result sets themselves, such as when reading driver messages.
+// When an error is returned, the buffer read up to that point is also returned.
+func FillResultSet(ctx context.Context, rows *sql.Rows, opts ...Option) (*Buffer, error) {
+	f := newFiller(ctx, rows, opts)
+	table, err := f.fill()
+	if err != nil {
+		return table, err
+	}
+	f.done()
+	return table, f.rowErrors()
+}
+
+// fillSet reads every result set, reporting to the metrics as started at start.
+func (f *filler) fillSet(start time.Time) (set Set, err error) {
+	defer func() {
+		f.c.observe(start, set, err)
+	}()
+	ctx := f.ctx
+	set = make([]*Buffer, 0, 3)
+	for {
+		table, err := f.fill()
+		set = append(set, table)
+		if err != nil {
+			return set, err
+		}
+		if err := ctx.Err(); err != nil {
+			return set, err
+		}
+		more, err := f.nextResultSet()
+		if err != nil {
+			return set, err
+		}
+		if !more {
+			break
+		}
+	}
+	f.done()
+	if err := f.rowErrors(); err != nil {
+		return set, err
+	}
+	return set, nil
+}
+
+// rowSource is the rows read by a filler: *sql.Rows, or driverRows for
+// FillSetDriver.
+type rowSource interface {
+	Columns() ([]string, error)
+	ColumnTypes() ([]*sql.ColumnType, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	NextResultSet() bool
+	Close() error
+}
+
+// filler reads result sets from rows into buffers.
+type filler struct {
+	ctx  context.Context
+	rows rowSource
+	c    *fillConfig
+
+	resultSet int
+	rowCount  int
+	byteCount int64
+
+	skipped int     // Rows skipped in the current result set.
+	errs    []error // Errors for rows skipped by ContinueOnError.
+
+	dest    []any
+	discard any
+	slab    []any // Remaining field storage when using an Arena.
+
+	raw    []sql.RawBytes // Scan destinations when using RawBytes.
+	borrow bool           // Rows are not retained, so RawBytes need not be copied.
+	keep   []int          // Buffer column index for each result column, -1 if filtered out.
+	cols   []Column
+}
+
+func newFiller(ctx context.Context, rows rowSource, opts []Option) *filler {
+	return &filler{
+		ctx:  ctx,
+		rows: rows,
+		c:    newFillConfig(opts),
+	}
+}
+
+// setup prepares the buffer and scan destinations for the current result set.
+func (f *filler) setup(table *Buffer) error {
+	c := f.c
+
+	// Get the column names.
+	names, err := f.rows.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := f.rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	// Apply the column filter.
+	f.keep = make([]int, len(names))
+	f.cols = f.cols[:0]
+	table.Columns = make([]string, 0, len(names))
+	if types != nil {
+		table.columnTypes = make([]*sql.ColumnType, 0, len(names))
+	}
+	for i, n := range names {
+		if c.columnFilter != nil && !c.columnFilter(n) {
+			f.keep[i] = -1
+			continue
+		}
+		f.keep[i] = len(table.Columns)
+		col := Column{
+			Name:             n,
+			Index:            len(table.Columns),
+			DatabaseTypeName: f.databaseTypeName(types, i),
+		}
+		col.typeConv = c.typeConverter(col.DatabaseTypeName)
+		f.cols = append(f.cols, col)
+		table.Columns = append(table.Columns, n)
+		if types != nil {
+			table.columnTypes = append(table.columnTypes, types[i])
+		}
+	}
+
+	// Create an easy lookup that should be more efficent then
+	// always looping to lookup an index from a column name.
+	if err := c.indexColumns(table, f.cols); err != nil {
+		return err
+	}
+
+	// Create a sized pointer slice.
+	f.dest = make([]any, len(names))
+	if c.rawBytes {
+		f.raw = make([]sql.RawBytes, len(names))
+		for i := range f.raw {
+			f.dest[i] = &f.raw[i]
+		}
+	}
+	return nil
+}
+
+// databaseTypeName returns the database type name of column i.
+func (f *filler) databaseTypeName(types []*sql.ColumnType, i int) string {
+	if types != nil {
+		return types[i].DatabaseTypeName()
+	}
+	if dr, ok := f.rows.(*driverRows); ok {
+		return dr.databaseTypeName(i)
+	}
+	return ""
+}
+
+// fill reads the current result set into a new buffer.
+// The buffer is always returned, even with an error.
+func (f *filler) fill() (*Buffer, error) {
+	c := f.c
+	rows := f.rows
+	table := c.newBuffer()
+	f.skipped = 0
+	var memBytes int64 // Size of the rows kept in memory, for SpillToDisk.
+
+	first := true
+	for rows.Next() {
+		// Some initialization depends on knowing the column names
+		// which isn't available until the first row is fetched.
+		if first {
+			first = false
+			if err := f.setup(table); err != nil {
+				return table, err
+			}
+		}
+		if c.maxRows > 0 && f.rowCount >= c.maxRows {
+			return table, c.truncated(f.rowCount, f.byteCount, false)
+		}
+		if f.rowCount%ctxCheckInterval == 0 {
+			if err := f.ctx.Err(); err != nil {
+				return table, err
+			}
+		}
+		// Create a new data slice that will be appended on to the table.
+		out := f.newField(len(table.Columns))
+		err := f.scan(out)
+		if err != nil {
+			err = rowError(table.Len()+f.skipped, err)
+			if !c.continueOnError {
+				return table, err
+			}
+			if !f.skip(err) {
+				return table, &RowErrors{Errors: f.errs, Limit: true}
+			}
+			continue
+		}
+		var size int64
+		if c.maxBytes > 0 || c.spillBytes > 0 {
+			size = sizeRow + rowSizeBytes(out)
+		}
+		if c.spillBytes > 0 && (table.spill != nil || memBytes+size > c.spillBytes) {
+			if err := f.spillRow(table, out); err != nil {
+				return table, err
+			}
+		} else {
+			if c.maxBytes > 0 {
+				if f.byteCount+size > c.maxBytes {
+					return table, c.truncated(f.rowCount, f.byteCount, true)
+				}
+				f.byteCount += size
+			}
+			memBytes += size
+			table.Rows = append(table.Rows, Row{
+				columnNameIndex: table.columnNameIndex,
+				Field:           out,
+			})
+		}
+		f.rowCount++
+		if c.progress != nil && f.rowCount%c.progressEvery == 0 {
+			c.progress(f.rowCount, f.resultSet)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return table, incomplete(f.rowCount, err)
+	}
+	return table, nil
+}
+
+// spillRow writes the row to the spill file of the buffer, creating it first if needed.
+func (f *filler) spillRow(table *Buffer, field []any) error {
+	if table.spill == nil {
+		s, err := newSpillFile(f.c.spillDir, len(table.Columns))
+		if err != nil {
+			return err
+		}
+		table.spill = s
+	}
+	return table.spill.add(table.Columns, table.Len(), Row{Field: field})
+}
+
+// skip records the error for a skipped row and reports if the error limit
+// still allows the fill to continue.
+func (f *filler) skip(err error) bool {
+	f.errs = append(f.errs, err)
+	f.skipped++
+	return f.c.maxErrors <= 0 || len(f.errs) <= f.c.maxErrors
+}
+
+// rowErrors returns and clears the errors for rows skipped so far.
+func (f *filler) rowErrors() error {
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := &RowErrors{Errors: f.errs}
+	f.errs = nil
+	return err
+}
+
+// newField returns storage for the fields of a single row.
+func (f *filler) newField(n int) []any {
+	if f.c.arena <= 1 || n == 0 {
+		return make([]any, n)
+	}
+	if len(f.slab) < n {
+		f.slab = make([]any, n*f.c.arena)
+	}
+	out := f.slab[:n:n]
+	f.slab = f.slab[n:]
+	return out
+}
+
+// scan reads the current row into out and applies any conversions.
+func (f *filler) scan(out []any) error {
+	if f.c.rawBytes {
+		return f.scanRaw(out)
+	}
+	// Scanning requires having a pointer to the data slice,
+	// so first make a pointer slice to each element of the data slice.
+	for i, k := range f.keep {
+		if k < 0 {
+			f.dest[i] = &f.discard
+			continue
+		}
+		f.dest[i] = &out[k]
+	}
+
+	// Then scan into the pointer slice.
+	err := f.rows.Scan(f.dest...)
+	if err != nil {
+		return err
+	}
+	return f.c.convert(f.cols, out)
+}
+
+// scanRaw reads the current row as sql.RawBytes, then copies the bytes into a
+// single allocation for the row unless the row is borrowed.
+func (f *filler) scanRaw(out []any) error {
+	err := f.rows.Scan(f.dest...)
+	if err != nil {
+		return err
+	}
+	borrow := f.borrow && f.c.borrow
+	var size int
+	if !borrow {
+		for i, k := range f.keep {
+			if k >= 0 {
+				size += len(f.raw[i])
+			}
+		}
+	}
+	buf := make([]byte, 0, size)
+	for i, k := range f.keep {
+		if k < 0 {
+			continue
+		}
+		rb := f.raw[i]
+		switch {
+		case rb == nil:
+			out[k] = nil
+		case borrow:
+			out[k] = []byte(rb)
+		default:
+			start := len(buf)
+			buf = append(buf, rb...)
+			out[k] = buf[start:len(buf):len(buf)]
+		}
+	}
+	return f.c.convert(f.cols, out)
+}
+
+// nextResultSet advances to the next result set, reporting if there is one.
+func (f *filler) nextResultSet() (bool, error) {
+	if !f.rows.NextResultSet() {
+		if err := f.rows.Err(); err != nil {
+			return false, incomplete(f.rowCount, err)
+		}
+		return false, nil
+	}
+	f.resultSet++
+	return true, nil
+}
+
+// stats returns the rows and result sets read so far.
+func (f *filler) stats() FillStats {
+	stats := FillStats{Rows: f.rowCount}
+	if f.dest != nil {
+		stats.ResultSets = f.resultSet + 1
+	}
+	return stats
+}
+
+// done reports the final progress.
+func (f *filler) done() {
+	if f.c.progress != nil {
+		f.c.progress(f.rowCount, f.resultSet)
+	}
+}
@@ -0,0 +1,58 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestFillArena(t *testing.T) {
+	res := fakeResult{Columns: []string{"ID", "Name"}}
+	for i := 0; i < 10; i++ {
+		res.Rows = append(res.Rows, []driver.Value{int64(i), fmt.Sprint("R", i)})
+	}
+	set, err := FillSetOpt(context.Background(), fakeRows(t, res), Arena(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := set[0]
+	for i, row := range b.Rows {
+		if g, w := fmt.Sprint(row.Field), fmt.Sprintf("[%d R%d]", i, i); g != w {
+			t.Fatalf("row %d got %s want %s", i, g, w)
+		}
+		if cap(row.Field) != 2 {
+			t.Fatalf("row %d has capacity %d, appending would overwrite the next row", i, cap(row.Field))
+		}
+	}
+}
+
+func benchmarkFill(b *testing.B, opts ...Option) {
+	res := fakeResult{Columns: []string{"ID", "Name", "Price", "Active"}}
+	for i := 0; i < 10000; i++ {
+		res.Rows = append(res.Rows, []driver.Value{int64(i), "name", float64(i) / 3, i%2 == 0})
+	}
+	db := openFake(b, fakeSet(res))
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.QueryContext(ctx, "select")
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = FillSetOpt(ctx, rows, opts...)
+		rows.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFillSet(b *testing.B) {
+	benchmarkFill(b)
+}
+
+func BenchmarkFillSetArena(b *testing.B) {
+	benchmarkFill(b, Arena(1024))
+}
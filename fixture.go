@@ -0,0 +1,193 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// Execer runs statements that do not return rows, such as *sql.DB, *sql.Conn and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, sql string, params ...any) (sql.Result, error)
+}
+
+// FixtureLoader inserts fixture files into database tables, such as to seed
+// an integration test database.
+//
+// Each table is read from a file in FS named after the table, with a ".csv"
+// or ".json" extension, or another extension with a Decoder. CSV files hold
+// the column names in the first record and read empty fields as NULL, and
+// JSON files hold a Buffer as written by json.Marshal.
+// A YAML decoder may be added with a YAML package, as Buffer implements
+// the YAML unmarshaler:
+//
+//	loader.Decoders = map[string]func([]byte, *table.Buffer) error{
+//		".yaml": func(b []byte, buf *table.Buffer) error { return yaml.Unmarshal(b, buf) },
+//	}
+type FixtureLoader struct {
+	FS     fs.FS
+	Tables []string // Tables in insert order.
+
+	// Decoders read a file into a buffer, keyed by file extension.
+	Decoders map[string]func([]byte, *Buffer) error
+
+	// Placeholder returns the parameter placeholder for the nth parameter,
+	// starting at 1. Defaults to "?". Use "$" + strconv.Itoa(n) for Postgres.
+	Placeholder func(n int) string
+
+	// Truncate removes the rows of a table, such as with DeleteAll.
+	// It is called by Load and Clean for each table in reverse order,
+	// so child tables are emptied before their parents. No rows are
+	// removed if it is nil.
+	Truncate func(ctx context.Context, e Execer, table string) error
+}
+
+// DeleteAll removes every row from the table. It may be used as the
+// FixtureLoader Truncate hook.
+func DeleteAll(ctx context.Context, e Execer, table string) error {
+	_, err := e.ExecContext(ctx, "delete from "+table)
+	return err
+}
+
+// Load truncates the tables, then reads each fixture file and inserts its
+// rows, in table order.
+func (l *FixtureLoader) Load(ctx context.Context, e Execer) error {
+	if err := l.Clean(ctx, e); err != nil {
+		return err
+	}
+	for _, t := range l.Tables {
+		buf, err := l.Read(t)
+		if err != nil {
+			return err
+		}
+		if err := l.Insert(ctx, e, t, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clean calls Truncate for each table in reverse order.
+func (l *FixtureLoader) Clean(ctx context.Context, e Execer) error {
+	if l.Truncate == nil {
+		return nil
+	}
+	for i := len(l.Tables) - 1; i >= 0; i-- {
+		if err := l.Truncate(ctx, e, l.Tables[i]); err != nil {
+			return fmt.Errorf("truncate %s: %w", l.Tables[i], err)
+		}
+	}
+	return nil
+}
+
+// Read returns the fixture buffer for the table.
+func (l *FixtureLoader) Read(table string) (*Buffer, error) {
+	matches, err := fs.Glob(l.FS, table+".*")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range matches {
+		ext := path.Ext(name)
+		decode, ok := l.Decoders[ext]
+		if !ok {
+			switch ext {
+			default:
+				continue
+			case ".csv":
+				decode = func(b []byte, buf *Buffer) error {
+					v, err := readCSV(bytes.NewReader(b))
+					if err != nil {
+						return err
+					}
+					*buf = *v
+					return nil
+				}
+			case ".json":
+				decode = func(b []byte, buf *Buffer) error {
+					return json.Unmarshal(b, buf)
+				}
+			}
+		}
+		b, err := fs.ReadFile(l.FS, name)
+		if err != nil {
+			return nil, err
+		}
+		buf := &Buffer{}
+		if err := decode(b, buf); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", name, err)
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("no fixture file for table %s", table)
+}
+
+// Insert inserts each row of buf into the table, one statement per row,
+// or with a bulk load if e implements BulkLoader.
+// Table and column names are written as given and are not quoted.
+func (l *FixtureLoader) Insert(ctx context.Context, e Execer, table string, buf *Buffer) error {
+	if err := buf.inMemory(); err != nil {
+		return err
+	}
+	if len(buf.Rows) == 0 {
+		return nil
+	}
+	i := 0
+	rs := &restartSource{
+		next: func() bool {
+			i++
+			return i < len(buf.Rows)
+		},
+		values:  func() []any { return buf.Rows[i].Field },
+		err:     func() error { return nil },
+		pending: true,
+	}
+	if _, ok, err := bulkLoad(ctx, e, table, buf.Columns, rs); ok {
+		if err != nil {
+			return fmt.Errorf("bulk load %s: %w", table, err)
+		}
+		return nil
+	}
+	text := insertSQL(table, buf.Columns, 1, l.Placeholder)
+	for i, row := range buf.Rows {
+		if _, err := e.ExecContext(ctx, text, row.Field...); err != nil {
+			return fmt.Errorf("insert %s row %d: %w", table, i, err)
+		}
+	}
+	return nil
+}
+
+// readCSV reads comma separated values into a new buffer of string values.
+// The first record is the column names. Empty fields are read as NULL.
+func readCSV(r io.Reader) (*Buffer, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	b := &Buffer{Columns: append([]string(nil), header...)}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make([]any, len(record))
+		for i, s := range record {
+			if len(s) > 0 {
+				row[i] = s
+			}
+		}
+		b.AddRow(row)
+	}
+	b.buildIndex()
+	return b, nil
+}
@@ -0,0 +1,82 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type recordExecer []string
+
+func (r *recordExecer) ExecContext(ctx context.Context, text string, params ...any) (sql.Result, error) {
+	*r = append(*r, fmt.Sprint(text, " ", params))
+	return nil, nil
+}
+
+func TestFixtureLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"account.csv":    {Data: []byte("id,name\n1,R1\n2,\n")},
+		"order.json":     {Data: []byte(`{"Columns":["id","account_id"],"Rows":[[10,1]]}`)},
+		"order_line.txt": {Data: []byte("id=100")},
+		"notes.md":       {Data: []byte("ignored")},
+	}
+	list := []struct {
+		Name   string
+		Tables []string
+		Want   string
+		Error  string
+	}{
+		{
+			Name:   "load",
+			Tables: []string{"account", "order", "order_line"},
+			Want: `delete from order_line []
+delete from order []
+delete from account []
+insert into account (id, name) values ($1, $2) [1 R1]
+insert into account (id, name) values ($1, $2) [2 <nil>]
+insert into order (id, account_id) values ($1, $2) [10 1]
+insert into order_line (id) values ($1) [100]`,
+		},
+		{
+			Name:   "missing",
+			Tables: []string{"account", "notes"},
+			Error:  "no fixture file for table notes",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var rec recordExecer
+			l := &FixtureLoader{
+				FS:     fsys,
+				Tables: item.Tables,
+				Decoders: map[string]func([]byte, *Buffer) error{
+					".txt": func(b []byte, buf *Buffer) error {
+						k, v, _ := strings.Cut(string(b), "=")
+						buf.Columns = []string{k}
+						buf.AddRow([]any{v})
+						return nil
+					},
+				},
+				Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+				Truncate:    DeleteAll,
+			}
+			err := l.Load(context.Background(), &rec)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := strings.Join(rec, "\n"), item.Want; g != w {
+				t.Fatalf("got:\n%s\n\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// txBeginner starts transactions, such as *sql.DB and *sql.Conn.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// FlushOption configures Buffer.Flush.
+type FlushOption func(*flushConfig)
+
+type flushConfig struct {
+	version    string
+	omitInsert []string
+}
+
+// VersionColumn enables optimistic concurrency for Buffer.Flush with the
+// named version or timestamp column. Updates and deletes only match a row
+// if its version is still the original value read, and any change that
+// matches no row is reported in a *ConflictError.
+//
+// When an update does not Set the version column and its original value is
+// an int64, the version is incremented by the update and in the buffer.
+// Other versions, such as timestamps, must be Set or maintained by the database.
+func VersionColumn(name string) FlushOption {
+	return func(c *flushConfig) {
+		c.version = name
+	}
+}
+
+// OmitInsert leaves the named columns out of the INSERT statements of
+// Buffer.Flush, such as identity or computed columns set by the database.
+// The columns are still set by updates if changed with Set.
+func OmitInsert(columns ...string) FlushOption {
+	return func(c *flushConfig) {
+		c.omitInsert = append(c.omitInsert, columns...)
+	}
+}
+
+// ErrConflict is matched by errors.Is when Flush finds rows changed since
+// they were read.
+var ErrConflict = errors.New("write conflict")
+
+// ConflictError is returned by Flush when rows were changed or deleted in the
+// database since they were read, as found by the VersionColumn.
+type ConflictError struct {
+	Table   string
+	Changes []Change // Updates and deletes that matched no row.
+}
+
+func (ce *ConflictError) Error() string {
+	return fmt.Sprintf("%d rows of %s changed since read", len(ce.Changes), ce.Table)
+}
+
+func (ce *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Flush writes the changes recorded since TrackChanges or AcceptChanges to
+// table, then accepts them. Deleted rows are removed first, then each
+// inserted and updated row is written in row order. Updates only set the
+// columns changed by Set. Deleted and updated rows are found by the original
+// values of keyCols, which must not be NULL. Table and column names are
+// written as given and are not quoted; dialect selects the placeholders.
+//
+// If e can begin a transaction, such as *sql.DB and *sql.Conn, the changes
+// are written in a single transaction. Otherwise they are written to e
+// directly, so pass a *sql.Tx to include them in a larger transaction.
+// If an error is returned the changes are kept. With a VersionColumn, every
+// change is still attempted after a conflict so that all conflicts are
+// reported, then the transaction is rolled back. A frozen buffer can not be
+// flushed and returns ErrFrozen.
+func (b *Buffer) Flush(ctx context.Context, e Execer, table string, keyCols []string, dialect Dialect, opts ...FlushOption) error {
+	changes := b.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	fc := &flushConfig{}
+	for _, o := range opts {
+		o(fc)
+	}
+	w := &flushWriter{
+		table:   table,
+		keyCols: keyCols,
+		keys:    make([]int, len(keyCols)),
+		version: -1,
+		dialect: dialect,
+	}
+	for i, n := range b.Columns {
+		if slices.Contains(fc.omitInsert, n) {
+			continue
+		}
+		w.insertCols = append(w.insertCols, n)
+		w.insert = append(w.insert, i)
+	}
+	for i, n := range keyCols {
+		ci, err := b.lookupColumn(n)
+		if err != nil {
+			return err
+		}
+		w.keys[i] = ci
+	}
+	if len(fc.version) > 0 {
+		vi, err := b.lookupColumn(fc.version)
+		if err != nil {
+			return err
+		}
+		w.version = vi
+		w.versionName = fc.version
+	}
+	if tb, ok := e.(txBeginner); ok {
+		tx, err := tb.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := w.write(ctx, tx, changes); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	} else if err := w.write(ctx, e, changes); err != nil {
+		return err
+	}
+	if len(w.bumps) > 0 {
+		b.unshare()
+		for i := range b.Rows {
+			if v, ok := w.bumps[b.Rows[i].change]; ok {
+				b.Rows[i].Field[w.version] = v
+			}
+		}
+	}
+	b.AcceptChanges()
+	return nil
+}
+
+// flushWriter writes changes to a table.
+type flushWriter struct {
+	table       string
+	keyCols     []string
+	keys        []int
+	insertCols  []string
+	insert      []int // Index of each column in insertCols.
+	version     int   // Version column index, -1 if not set.
+	versionName string
+	dialect     Dialect
+
+	conflicts []Change
+	bumps     map[*rowChange]int64 // Versions incremented by updates.
+}
+
+func (w *flushWriter) write(ctx context.Context, e Execer, changes []Change) error {
+	table, keyCols, keys, dialect := w.table, w.keyCols, w.keys, w.dialect
+	for i, c := range changes {
+		var text string
+		var params []any
+		switch c.Kind {
+		case ChangeInsert:
+			text = insertSQL(table, w.insertCols, 1, dialect.Placeholder)
+			params = make([]any, len(w.insert))
+			for pi, ci := range w.insert {
+				params[pi] = c.Row.Field[ci]
+			}
+		case ChangeUpdate:
+			set := make([]string, len(c.Columns))
+			for ci, n := range c.Columns {
+				set[ci] = n + " = " + dialect.Placeholder(ci+1)
+				params = append(params, c.Row.Get(n))
+			}
+			if w.version >= 0 && !slices.Contains(c.Columns, w.versionName) {
+				if v, ok := c.Original[w.version].(int64); ok {
+					set = append(set, w.versionName+" = "+dialect.Placeholder(len(params)+1))
+					params = append(params, v+1)
+					if w.bumps == nil {
+						w.bumps = make(map[*rowChange]int64)
+					}
+					w.bumps[c.Row.change] = v + 1
+				}
+			}
+			where, err := keyWhere(keyCols, keys, c.Original, len(params), dialect)
+			if err != nil {
+				return fmt.Errorf("update %s change %d: %w", table, i, err)
+			}
+			text = "update " + table + " set " + strings.Join(set, ", ") + " where " + where
+			for _, k := range keys {
+				params = append(params, c.Original[k])
+			}
+			text, params = w.versionWhere(text, params, c)
+		case ChangeDelete:
+			where, err := keyWhere(keyCols, keys, c.Original, 0, dialect)
+			if err != nil {
+				return fmt.Errorf("delete %s change %d: %w", table, i, err)
+			}
+			text = "delete from " + table + " where " + where
+			for _, k := range keys {
+				params = append(params, c.Original[k])
+			}
+			text, params = w.versionWhere(text, params, c)
+		}
+		res, err := e.ExecContext(ctx, text, params...)
+		if err != nil {
+			return fmt.Errorf("%s %s change %d: %w", c.Kind, table, i, err)
+		}
+		if w.version < 0 || c.Kind == ChangeInsert {
+			continue
+		}
+		if res == nil {
+			return fmt.Errorf("%s %s change %d: no result to check the version", c.Kind, table, i)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s %s change %d: %w", c.Kind, table, i, err)
+		}
+		if n == 0 {
+			w.conflicts = append(w.conflicts, c)
+		}
+	}
+	if len(w.conflicts) > 0 {
+		return &ConflictError{Table: table, Changes: w.conflicts}
+	}
+	return nil
+}
+
+// versionWhere adds the condition matching the original version to the
+// statement, if a version column is set.
+func (w *flushWriter) versionWhere(text string, params []any, c Change) (string, []any) {
+	if w.version < 0 {
+		return text, params
+	}
+	name := w.versionName
+	v := c.Original[w.version]
+	if v == nil {
+		return text + " and " + name + " is null", params
+	}
+	return text + " and " + name + " = " + w.dialect.Placeholder(len(params)+1), append(params, v)
+}
+
+// keyWhere returns the condition matching the key columns, with placeholders
+// numbered after the first n parameters.
+func keyWhere(keyCols []string, keys []int, original []any, n int, dialect Dialect) (string, error) {
+	if len(keys) == 0 {
+		return "", errors.New("no key columns")
+	}
+	where := make([]string, len(keys))
+	for i, k := range keys {
+		if original[k] == nil {
+			return "", fmt.Errorf("key column %s is NULL", keyCols[i])
+		}
+		where[i] = keyCols[i] + " = " + dialect.Placeholder(n+i+1)
+	}
+	return strings.Join(where, " and "), nil
+}
@@ -0,0 +1,229 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFlush(t *testing.T) {
+	newBuffer := func() *Buffer {
+		b := &Buffer{
+			Columns: []string{"id", "name", "score"},
+		}
+		for i := int64(1); i <= 3; i++ {
+			b.AddRow([]any{i, fmt.Sprint("R", i), nil})
+		}
+		b.TrackChanges()
+		b.Set(0, "name", "One")
+		b.Set(0, "score", int64(10))
+		b.DeleteRow(1)
+		b.AddRow([]any{int64(4), "R4", nil})
+		return b
+	}
+
+	list := []struct {
+		Name    string
+		Dialect Dialect
+		Keys    []string
+		Opts    []FlushOption
+		Fail    string
+		Want    string
+		Error   string
+	}{
+		{
+			Name:    "postgres",
+			Dialect: DialectPostgres,
+			Keys:    []string{"id"},
+			Want: `delete from account where id = $1 [2]
+update account set name = $1, score = $2 where id = $3 [One 10 1]
+insert into account (id, name, score) values ($1, $2, $3) [4 R4 <nil>]`,
+		},
+		{
+			Name: "generic",
+			Keys: []string{"id", "name"},
+			Want: `delete from account where id = ? and name = ? [2 R2]
+update account set name = ?, score = ? where id = ? and name = ? [One 10 1 R1]
+insert into account (id, name, score) values (?, ?, ?) [4 R4 <nil>]`,
+		},
+		{
+			Name: "omit insert",
+			Keys: []string{"id"},
+			Opts: []FlushOption{OmitInsert("id", "score")},
+			Want: `delete from account where id = ? [2]
+update account set name = ?, score = ? where id = ? [One 10 1]
+insert into account (name) values (?) [R4]`,
+		},
+		{
+			Name:  "no keys",
+			Error: "delete account change 0: no key columns",
+		},
+		{
+			Name:  "missing key",
+			Keys:  []string{"code"},
+			Error: `Table doesn't have column named "code"`,
+		},
+		{
+			Name:  "exec error",
+			Keys:  []string{"id"},
+			Fail:  "update",
+			Error: "update account change 1: update failed",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := newBuffer()
+			var rec []string
+			db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+				if len(item.Fail) > 0 && strings.HasPrefix(query, item.Fail) {
+					return nil, errors.New(item.Fail + " failed")
+				}
+				params := make([]any, len(args))
+				for i, a := range args {
+					params[i] = a.Value
+				}
+				rec = append(rec, fmt.Sprint(query, " ", params))
+				return nil, nil
+			})
+			err := b.Flush(context.Background(), db, "account", item.Keys, item.Dialect, item.Opts...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				if len(b.Changes()) != 3 {
+					t.Fatal("expected changes to be kept after an error")
+				}
+				return
+			}
+			if g, w := strings.Join(rec, "\n"), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+			if len(b.Changes()) != 0 {
+				t.Fatal("expected changes to be accepted")
+			}
+		})
+	}
+
+	// An Execer that can not begin a transaction is used directly.
+	b := newBuffer()
+	var rec recordExecer
+	if err := b.Flush(context.Background(), &rec, "account", []string{"id"}, DialectSQLServer); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rec[1], "update account set name = @p1, score = @p2 where id = @p3 [One 10 1]"; g != w {
+		t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestFlushVersion(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"id", "name", "version"},
+	}
+	b.AddRow([]any{int64(1), "R1", int64(7)})
+	b.AddRow([]any{int64(2), "R2", int64(3)})
+	b.AddRow([]any{int64(3), "R3", nil})
+	b.TrackChanges()
+	b.Set(0, "name", "One")
+	b.Set(1, "name", "Two")
+	b.DeleteRow(2)
+
+	var rec []string
+	stale := true // The row being renamed to Two was changed by someone else.
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		params := make([]any, len(args))
+		for i, a := range args {
+			params[i] = a.Value
+		}
+		rec = append(rec, fmt.Sprint(query, " ", params))
+		if stale && params[0] == "Two" {
+			return nil, nil
+		}
+		return []fakeResult{{RowsAffected: 1}}, nil
+	})
+
+	err := b.Flush(context.Background(), db, "account", []string{"id"}, DialectPostgres, VersionColumn("version"))
+	want := `delete from account where id = $1 and version is null [3]
+update account set name = $1, version = $2 where id = $3 and version = $4 [One 8 1 7]
+update account set name = $1, version = $2 where id = $3 and version = $4 [Two 4 2 3]`
+	if g, w := strings.Join(rec, "\n"), want; g != w {
+		t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+	}
+	var ce *ConflictError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected conflict error, got error: %v", err)
+	}
+	if g, w := err.Error(), "1 rows of account changed since read"; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+	if g := ce.Changes[0].Row.Get("id"); g != int64(2) {
+		t.Fatalf("expected conflict on row 2, got %v", g)
+	}
+	if g := b.Get(0, "version"); g != int64(7) {
+		t.Fatalf("expected version unchanged after conflict, got %v", g)
+	}
+
+	// Reload the stale row and try again.
+	stale = false
+	rec = nil
+	b.Rows[1].Field[2] = int64(4)
+	b.AcceptChanges()
+	b.Set(0, "name", "Uno")
+	if err := b.Flush(context.Background(), db, "account", []string{"id"}, DialectPostgres, VersionColumn("version")); err != nil {
+		t.Fatal(err)
+	}
+	if g := b.Get(0, "version"); g != int64(8) {
+		t.Fatalf("expected version 8, got %v", g)
+	}
+}
+
+func TestFlushVersionShared(t *testing.T) {
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		return []fakeResult{{RowsAffected: 1}}, nil
+	})
+	all := func(Row) bool { return true }
+
+	// Flush a buffer derived from another.
+	parent := &Buffer{
+		Columns: []string{"id", "name", "version"},
+	}
+	parent.AddRow([]any{int64(1), "R1", int64(7)})
+	b := parent.Filter(all)
+	b.TrackChanges()
+	b.Set(0, "name", "One")
+	if err := b.Flush(context.Background(), db, "account", []string{"id"}, DialectGeneric, VersionColumn("version")); err != nil {
+		t.Fatal(err)
+	}
+	if g := b.Get(0, "version"); g != int64(8) {
+		t.Fatalf("expected version 8, got %v", g)
+	}
+	if g := parent.Get(0, "version"); g != int64(7) {
+		t.Fatalf("expected parent version 7, got %v", g)
+	}
+
+	// Flush a buffer another was derived from after the change.
+	b.Set(0, "name", "Uno")
+	child := b.Filter(all)
+	if err := b.Flush(context.Background(), db, "account", []string{"id"}, DialectGeneric, VersionColumn("version")); err != nil {
+		t.Fatal(err)
+	}
+	if g := b.Get(0, "version"); g != int64(9) {
+		t.Fatalf("expected version 9, got %v", g)
+	}
+	if g := child.Get(0, "version"); g != int64(8) {
+		t.Fatalf("expected derived version 8, got %v", g)
+	}
+
+	b.Set(0, "name", "Eins")
+	b.Freeze()
+	err := b.Flush(context.Background(), db, "account", []string{"id"}, DialectGeneric, VersionColumn("version"))
+	if !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected error: %v, got error: %v", ErrFrozen, err)
+	}
+}
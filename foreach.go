@@ -0,0 +1,59 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ForEachRow calls fn for each row in every result set of rows.
+// The Row and its fields are reused for the next row, so fn must not retain
+// them after it returns. With the Borrow option, []byte values are not copied
+// out of the driver at all. If fn returns an error, ForEachRow stops and returns it.
+func ForEachRow(ctx context.Context, rows *sql.Rows, fn func(Row) error, opts ...Option) error {
+	if fn == nil {
+		return errors.New("missing row func")
+	}
+	f := newFiller(ctx, rows, opts)
+	f.borrow = true
+	for {
+		table := &Buffer{}
+		var row Row
+		first := true
+		for rows.Next() {
+			if first {
+				first = false
+				if err := f.setup(table); err != nil {
+					return err
+				}
+				row = Row{
+					columnNameIndex: table.columnNameIndex,
+					Field:           make([]any, len(table.Columns)),
+				}
+			}
+			if f.rowCount%ctxCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			clear(row.Field)
+			if err := f.scan(row.Field); err != nil {
+				return rowError(f.rowCount, err)
+			}
+			f.rowCount++
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return incomplete(f.rowCount, err)
+		}
+		more, err := f.nextResultSet()
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+}
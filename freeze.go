@@ -0,0 +1,36 @@
+package table
+
+import "errors"
+
+// ErrFrozen is returned, or used as the panic value, when a frozen buffer
+// is modified.
+var ErrFrozen = errors.New("buffer is frozen")
+
+// Freeze marks the buffer as read only. After Freeze returns, the buffer may
+// be shared by any number of goroutines that only read it, such as a result
+// kept in a cache. Methods that modify the buffer return ErrFrozen, and
+// those without an error result, such as AddRow and SetResult, panic with
+// ErrFrozen. Set.SetNames also panics when naming a frozen buffer.
+//
+// The Columns and Rows fields, and the row fields, are still exported and
+// can not be protected; callers must not modify them once the buffer is
+// shared. A frozen buffer can not be thawed; copy it to modify it.
+func (b *Buffer) Freeze() {
+	// Build the column lookup now so readers never write it lazily.
+	b.buildIndex()
+	b.frozen = true
+}
+
+// Frozen reports if Freeze has been called on the buffer.
+func (b *Buffer) Frozen() bool {
+	return b.frozen
+}
+
+// mutable returns ErrFrozen if the buffer is frozen, or ErrSpilled if
+// some of its rows are on disk.
+func (b *Buffer) mutable() error {
+	if b.frozen {
+		return ErrFrozen
+	}
+	return b.inMemory()
+}
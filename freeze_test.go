@@ -0,0 +1,66 @@
+package table
+
+import (
+	"encoding/xml"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name"},
+	}
+	b.AddRow([]any{int64(1), nil})
+	b.Freeze()
+	if !b.Frozen() {
+		t.Fatal("expected frozen buffer")
+	}
+
+	list := []struct {
+		Name   string
+		Modify func() error
+	}{
+		{"Coalesce", func() error { return b.Coalesce("Name", "none") }},
+		{"CoalesceColumns", func() error { return b.CoalesceColumns(map[string]any{"Name": "none"}) }},
+		{"Mask", func() error { return b.Mask(map[string]MaskFunc{"ID": MaskFixed(int64(0))}) }},
+		{"UnmarshalXML", func() error { return xml.Unmarshal([]byte("<rows></rows>"), b) }},
+		{"AddRow", func() (err error) {
+			defer func() { err, _ = recover().(error) }()
+			b.AddRow([]any{int64(2), "R2"})
+			return nil
+		}},
+		{"SetResult", func() (err error) {
+			defer func() { err, _ = recover().(error) }()
+			b.SetResult(nil)
+			return nil
+		}},
+		{"SetNames", func() (err error) {
+			defer func() { err, _ = recover().(error) }()
+			Set{b}.SetNames([]string{"people"})
+			return nil
+		}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			if err := item.Modify(); !errors.Is(err, ErrFrozen) {
+				t.Fatalf("expected error: %v, got error: %v", ErrFrozen, err)
+			}
+		})
+	}
+	if g := b.Rows[0].Field[1]; g != nil {
+		t.Fatalf("frozen buffer was modified, got %v", g)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !b.HasColumn("Name") || b.Get(0, "ID") != int64(1) {
+				t.Error("unexpected buffer contents")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,203 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrNull is matched by errors.Is when a NULL value can not be stored in
+// the requested type.
+var ErrNull = errors.New("NULL value")
+
+// NullError is returned by the typed getters when the field is NULL.
+type NullError struct {
+	Column string
+}
+
+func (ne *NullError) Error() string {
+	return fmt.Sprintf("column %q is NULL", ne.Column)
+}
+
+func (ne *NullError) Is(target error) bool {
+	return target == ErrNull
+}
+
+// TypeError is returned by the typed getters when the field can not be
+// converted to the requested type.
+type TypeError struct {
+	Column string
+	Value  any
+	Want   string
+}
+
+func (te *TypeError) Error() string {
+	return fmt.Sprintf("column %q: cannot convert %T to %s", te.Column, te.Value, te.Want)
+}
+
+// lookup returns the named field, or an error if it is missing or NULL.
+func (r Row) lookup(columnName string) (any, error) {
+	i, ok := r.columnNameIndex[columnName]
+	if !ok {
+		return nil, &IndexError{subject: SubjectName, notFoundName: columnName}
+	}
+	v := r.Field[i]
+	if v == nil {
+		return nil, &NullError{Column: columnName}
+	}
+	return v, nil
+}
+
+// GetString returns the named field as a string. []byte values are converted.
+func (r Row) GetString(columnName string) (string, error) {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return "", err
+	}
+	s, ok := asString(v)
+	if !ok {
+		return "", &TypeError{Column: columnName, Value: v, Want: "string"}
+	}
+	return s, nil
+}
+
+// GetBytes returns the named field as a []byte. string values are converted.
+func (r Row) GetBytes(columnName string) ([]byte, error) {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, &TypeError{Column: columnName, Value: v, Want: "[]byte"}
+}
+
+// GetInt64 returns the named field as an int64. Other numeric types are
+// converted if no precision is lost, so 2.0 is returned as 2 but 2.5 is an error.
+func (r Row) GetInt64(columnName string) (int64, error) {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := asInt64(v)
+	if !ok {
+		return 0, &TypeError{Column: columnName, Value: v, Want: "int64"}
+	}
+	return n, nil
+}
+
+// GetFloat64 returns the named field as a float64. Other numeric types are
+// converted if they can be represented exactly.
+func (r Row) GetFloat64(columnName string) (float64, error) {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := asFloat64(v)
+	if !ok {
+		return 0, &TypeError{Column: columnName, Value: v, Want: "float64"}
+	}
+	return f, nil
+}
+
+// GetBool returns the named field as a bool.
+func (r Row) GetBool(columnName string) (bool, error) {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, &TypeError{Column: columnName, Value: v, Want: "bool"}
+	}
+	return b, nil
+}
+
+// GetTime returns the named field as a time.Time.
+func (r Row) GetTime(columnName string) (time.Time, error) {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, ok := v.(time.Time)
+	if !ok {
+		return time.Time{}, &TypeError{Column: columnName, Value: v, Want: "time.Time"}
+	}
+	return t, nil
+}
+
+// GetOr returns the named field, or def if the column is missing or NULL.
+func (r Row) GetOr(columnName string, def any) any {
+	v, err := r.lookup(columnName)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// GetStringOr returns the named field as a string like GetString, or def if
+// the column is missing, NULL, or can not be converted.
+func (r Row) GetStringOr(columnName string, def string) string {
+	s, err := r.GetString(columnName)
+	if err != nil {
+		return def
+	}
+	return s
+}
+
+// GetInt64Or returns the named field as an int64 like GetInt64, or def if
+// the column is missing, NULL, or can not be converted.
+func (r Row) GetInt64Or(columnName string, def int64) int64 {
+	n, err := r.GetInt64(columnName)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func asString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// asInt64 converts integer values, and whole floating point values, that fit in an int64.
+func asInt64(v any) (int64, bool) {
+	if n, ok := v.(int64); ok {
+		return n, true
+	}
+	rv := reflect.ValueOf(v)
+	if !isNumberKind(rv.Kind()) {
+		return 0, false
+	}
+	var n int64
+	if err := setNumber(reflect.ValueOf(&n).Elem(), rv, true); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// asFloat64 converts numeric values that can be represented exactly as a float64.
+func asFloat64(v any) (float64, bool) {
+	if f, ok := v.(float64); ok {
+		return f, true
+	}
+	rv := reflect.ValueOf(v)
+	if !isNumberKind(rv.Kind()) {
+		return 0, false
+	}
+	var f float64
+	if err := setNumber(reflect.ValueOf(&f).Elem(), rv, true); err != nil {
+		return 0, false
+	}
+	return f, true
+}
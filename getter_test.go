@@ -0,0 +1,34 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetOr(t *testing.T) {
+	buf := &Buffer{Columns: []string{"ID", "Name", "Note"}}
+	buf.AddRow([]any{int64(7), []byte("R1"), nil})
+	row := buf.Rows[0]
+
+	list := []struct {
+		Name string
+		Get  func() any
+		Want string
+	}{
+		{Name: "any", Get: func() any { return row.GetOr("ID", "def") }, Want: "7"},
+		{Name: "any-null", Get: func() any { return row.GetOr("Note", "def") }, Want: "def"},
+		{Name: "any-missing", Get: func() any { return row.GetOr("Other", "def") }, Want: "def"},
+		{Name: "string", Get: func() any { return row.GetStringOr("Name", "def") }, Want: "R1"},
+		{Name: "string-null", Get: func() any { return row.GetStringOr("Note", "def") }, Want: "def"},
+		{Name: "string-type", Get: func() any { return row.GetStringOr("ID", "def") }, Want: "def"},
+		{Name: "int64", Get: func() any { return row.GetInt64Or("ID", -1) }, Want: "7"},
+		{Name: "int64-missing", Get: func() any { return row.GetInt64Or("Other", -1) }, Want: "-1"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			if g, w := fmt.Sprint(item.Get()), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
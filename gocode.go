@@ -0,0 +1,244 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// GoStruct returns the formatted source of a Go file in package pkg declaring
+// a struct type named typeName with a field for each buffer column, tagged
+// with the column name for BufferToStruct.
+//
+// Field types come from the Schema. Nullable columns use a pointer type,
+// unless the type can already hold nil, and columns with an unknown type,
+// such as when every value is NULL, use any.
+func (t *Buffer) GoStruct(pkg, typeName string) ([]byte, error) {
+	if !isGoIdent(pkg) || !isGoIdent(typeName) {
+		return nil, fmt.Errorf("invalid package %q or type name %q", pkg, typeName)
+	}
+	imports := map[string]bool{}
+	body := &bytes.Buffer{}
+	fmt.Fprintf(body, "type %s struct {\n", typeName)
+	used := map[string]bool{}
+	for i, sc := range t.Schema() {
+		name := goFieldName(sc.Name, i)
+		for k := 2; used[name]; k++ {
+			name = goFieldName(sc.Name, i) + "_" + strconv.Itoa(k)
+		}
+		used[name] = true
+		fmt.Fprintf(body, "\t%s %s `sql:%q`\n", name, goFieldType(sc, imports), sc.Name)
+	}
+	fmt.Fprintf(body, "}\n")
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for p := range imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		fmt.Fprintf(buf, "import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(buf, "\t%q\n", p)
+		}
+		fmt.Fprintf(buf, ")\n\n")
+	}
+	buf.Write(body.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// GoCode returns a formatted Go variable declaration named varName that
+// rebuilds the buffer with AddRow, such as to keep a real result as a test
+// fixture:
+//
+//	var varName = func() *table.Buffer {
+//		b := &table.Buffer{Columns: []string{"ID", "Name"}}
+//		b.AddRow([]any{int64(1), "R1"})
+//		return b
+//	}()
+//
+// The code references the table package, and the time and math packages for
+// some values; the imports are not included. Values must be NULL, strings,
+// []byte, bool, time.Time, or integer and floating point numbers.
+func (t *Buffer) GoCode(varName string) (string, error) {
+	if !isGoIdent(varName) {
+		return "", fmt.Errorf("invalid variable name %q", varName)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "var %s = func() *table.Buffer {\n", varName)
+	fmt.Fprintf(buf, "b := &table.Buffer{Columns: []string{")
+	for i, n := range t.Columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.Quote(n))
+	}
+	fmt.Fprintf(buf, "}}\n")
+	err := t.Each(func(ri int, row Row) error {
+		buf.WriteString("b.AddRow([]any{")
+		for i, v := range row.Field {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			s, err := goValue(v)
+			if err != nil {
+				return fmt.Errorf("row %d, column %q: %w", ri, t.Columns[i], err)
+			}
+			buf.WriteString(s)
+		}
+		buf.WriteString("})\n")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(buf, "return b\n}()\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// goValue returns a Go expression for the field value.
+func goValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return strconv.Quote(v), nil
+	case []byte:
+		if v == nil {
+			return "[]byte(nil)", nil
+		}
+		return "[]byte(" + strconv.Quote(string(v)) + ")", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		loc := "time.UTC"
+		switch name, offset := v.Zone(); {
+		case v.Location() == time.Local:
+			loc = "time.Local"
+		case v.Location() != time.UTC:
+			loc = fmt.Sprintf("time.FixedZone(%q, %d)", name, offset)
+		}
+		return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, %s)",
+			v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), loc), nil
+	case float32:
+		return goFloat("float32", float64(v), 32), nil
+	case float64:
+		return goFloat("float64", v, 64), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		return fmt.Sprintf("%T(%d)", v, v), nil
+	}
+	return "", fmt.Errorf("cannot write %T as Go code", v)
+}
+
+func goFloat(typeName string, f float64, bits int) string {
+	var s string
+	switch {
+	case math.IsNaN(f):
+		s = "math.NaN()"
+	case math.IsInf(f, 1):
+		s = "math.Inf(1)"
+	case math.IsInf(f, -1):
+		s = "math.Inf(-1)"
+	default:
+		s = strconv.FormatFloat(f, 'g', -1, bits)
+	}
+	return typeName + "(" + s + ")"
+}
+
+// goFieldType returns the Go type expression for the column, adding any
+// packages it references to imports.
+func goFieldType(sc SchemaColumn, imports map[string]bool) string {
+	if sc.Type == nil {
+		return "any"
+	}
+	s := goTypeString(sc.Type, imports)
+	if !sc.Nullable {
+		return s
+	}
+	switch sc.Type.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return s
+	}
+	return "*" + s
+}
+
+// goTypeString returns the Go type expression for tp, adding any packages it
+// references to imports.
+func goTypeString(tp reflect.Type, imports map[string]bool) string {
+	if len(tp.Name()) > 0 {
+		if p := tp.PkgPath(); len(p) > 0 {
+			imports[p] = true
+		}
+		if tp == reflect.TypeOf(byte(0)) {
+			return "byte"
+		}
+		return tp.String()
+	}
+	switch tp.Kind() {
+	case reflect.Pointer:
+		return "*" + goTypeString(tp.Elem(), imports)
+	case reflect.Slice:
+		return "[]" + goTypeString(tp.Elem(), imports)
+	case reflect.Array:
+		return "[" + strconv.Itoa(tp.Len()) + "]" + goTypeString(tp.Elem(), imports)
+	case reflect.Map:
+		return "map[" + goTypeString(tp.Key(), imports) + "]" + goTypeString(tp.Elem(), imports)
+	}
+	return tp.String()
+}
+
+// goInitialisms are name parts written in upper case.
+var goInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goFieldName returns an exported Go identifier for the column name, such as
+// OrderID for "order_id". Column i is used for names without letters.
+func goFieldName(column string, i int) string {
+	parts := strings.FieldsFunc(column, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, p := range parts {
+		if up := strings.ToUpper(p); goInitialisms[up] {
+			sb.WriteString(up)
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+	name := sb.String()
+	if len(name) == 0 {
+		return "Column" + strconv.Itoa(i)
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		name = "C" + name
+	}
+	return name
+}
+
+// isGoIdent reports if s is a valid Go identifier.
+func isGoIdent(s string) bool {
+	for i, r := range s {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return len(s) > 0
+}
@@ -0,0 +1,59 @@
+package table
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGoStruct(t *testing.T) {
+	buf := &Buffer{Columns: []string{"id", "user-name", "data", "2fa", "", "ID"}}
+	buf.AddRow([]any{int64(1), "a", []byte("x"), true, nil, nil})
+	buf.AddRow([]any{int64(2), nil, nil, false, nil, int64(3)})
+
+	out, err := buf.GoStruct("store", "User")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package store\n\ntype User struct {\n" +
+		"\tID       int64   `sql:\"id\"`\n" +
+		"\tUserName *string `sql:\"user-name\"`\n" +
+		"\tData     []byte  `sql:\"data\"`\n" +
+		"\tC2fa     bool    `sql:\"2fa\"`\n" +
+		"\tColumn4  any     `sql:\"\"`\n" +
+		"\tID_2     *int64  `sql:\"ID\"`\n" +
+		"}\n"
+	if g := string(out); g != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", g, want)
+	}
+	if _, err := buf.GoStruct("store", "no good"); err == nil {
+		t.Fatal("expected an error for an invalid type name")
+	}
+}
+
+func TestGoCode(t *testing.T) {
+	buf := &Buffer{Columns: []string{"ID", "Name", "Data", "At", "Ratio", "OK"}}
+	buf.AddRow([]any{int64(1), "R\"1", []byte("x"), time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC), 1.5, true})
+	buf.AddRow([]any{int32(2), nil, nil, time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)), math.Inf(1), false})
+
+	got, err := buf.GoCode("fixture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `var fixture = func() *table.Buffer {
+	b := &table.Buffer{Columns: []string{"ID", "Name", "Data", "At", "Ratio", "OK"}}
+	b.AddRow([]any{int64(1), "R\"1", []byte("x"), time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC), float64(1.5), true})
+	b.AddRow([]any{int32(2), nil, nil, time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)), float64(math.Inf(1)), false})
+	return b
+}()
+`
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	bad := &Buffer{Columns: []string{"V"}}
+	bad.AddRow([]any{struct{}{}})
+	if _, err := bad.GoCode("fixture"); err == nil || err.Error() != `row 0, column "V": cannot write struct {} as Go code` {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package table
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// Hash returns a SHA-256 digest of the columns and row values in order.
+// Values are hashed by type and value, so int64(1) and "1" differ.
+// Field types are limited to those supported by WriteSnapshot.
+func (b *Buffer) Hash() ([32]byte, error) {
+	var sum [32]byte
+	h := sha256.New()
+	sw := &snapshotWriter{w: bufio.NewWriter(h)}
+	sw.columns(b.Columns)
+	sw.uvarint(uint64(b.Len()))
+	err := b.Each(func(ri int, row Row) error {
+		return sw.row(b.Columns, ri, row)
+	})
+	if err != nil {
+		return sum, err
+	}
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+	if sw.err != nil {
+		return sum, sw.err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// HashUnordered returns a SHA-256 digest of the columns and row values
+// that does not depend on the order of the rows. Duplicate rows are still counted.
+func (b *Buffer) HashUnordered() ([32]byte, error) {
+	var sum [32]byte
+	rowSums := make([][32]byte, b.Len())
+	err := b.Each(func(ri int, row Row) error {
+		h := sha256.New()
+		sw := &snapshotWriter{w: bufio.NewWriter(h)}
+		if err := sw.row(b.Columns, ri, row); err != nil {
+			return err
+		}
+		if sw.err == nil {
+			sw.err = sw.w.Flush()
+		}
+		if sw.err != nil {
+			return sw.err
+		}
+		copy(rowSums[ri][:], h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return sum, err
+	}
+	sort.Slice(rowSums, func(i, j int) bool {
+		return bytes.Compare(rowSums[i][:], rowSums[j][:]) < 0
+	})
+
+	h := sha256.New()
+	sw := &snapshotWriter{w: bufio.NewWriter(h)}
+	sw.columns(b.Columns)
+	sw.uvarint(uint64(len(rowSums)))
+	for _, rs := range rowSums {
+		sw.raw(rs[:])
+	}
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+	if sw.err != nil {
+		return sum, sw.err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// Hash returns a 64-bit FNV-1a hash of the named fields, or of every field if
+// no columns are given, such as to group, join, or compare rows. The hash is
+// stable across processes and versions. Values are hashed by type and value,
+// so int64(1) and "1" differ; types not supported by WriteSnapshot are hashed
+// by their type and fmt representation. It panics with an *IndexError if a
+// column does not exist.
+func (r Row) Hash(cols ...string) uint64 {
+	if len(cols) == 0 {
+		return hashFields(r.Field, nil)
+	}
+	index := make([]int, len(cols))
+	for i, n := range cols {
+		ci, ok := r.columnNameIndex[n]
+		if !ok {
+			panic(&IndexError{subject: SubjectName, notFoundName: n})
+		}
+		index[i] = ci
+	}
+	return hashFields(r.Field, index)
+}
+
+// hashFields returns the hash of the fields at index, or of every field if
+// index is nil.
+func hashFields(field []any, index []int) uint64 {
+	h := fnv.New64a()
+	sw := &snapshotWriter{w: bufio.NewWriterSize(h, 64)}
+	value := func(v any) {
+		if err := sw.value(v); err != nil {
+			sw.raw([]byte{0xff})
+			sw.bytes([]byte(fmt.Sprintf("%T:%v", v, v)))
+		}
+	}
+	if index == nil {
+		for _, f := range field {
+			value(f)
+		}
+	} else {
+		for _, i := range index {
+			value(field[i])
+		}
+	}
+	sw.w.Flush()
+	return h.Sum64()
+}
+
+// HashIndex finds the rows of a buffer by the values of key columns.
+type HashIndex struct {
+	b    *Buffer
+	keys []int
+	rows map[uint64][]int
+}
+
+// HashIndex returns an index of the rows by the hash of the named columns,
+// as by Row.Hash. The index is not updated when the buffer changes.
+// It returns ErrSpilled if rows of the buffer are on disk.
+func (b *Buffer) HashIndex(cols ...string) (*HashIndex, error) {
+	if err := b.inMemory(); err != nil {
+		return nil, err
+	}
+	keys, err := b.keyIndex(cols)
+	if err != nil {
+		return nil, err
+	}
+	h := &HashIndex{
+		b:    b,
+		keys: keys,
+		rows: make(map[uint64][]int, len(b.Rows)),
+	}
+	for ri, row := range b.Rows {
+		k := hashFields(row.Field, keys)
+		h.rows[k] = append(h.rows[k], ri)
+	}
+	return h, nil
+}
+
+// Lookup returns the indexes of the rows whose key columns have the given
+// values, in row order. Values are compared by type and value, as by Row.Hash.
+func (h *HashIndex) Lookup(values ...any) []int {
+	if len(values) != len(h.keys) {
+		return nil
+	}
+	var out []int
+	for _, ri := range h.rows[hashFields(values, nil)] {
+		if hashEqual(pick(h.b.Rows[ri].Field, h.keys), values) {
+			out = append(out, ri)
+		}
+	}
+	return out
+}
+
+// hashEqual reports if the fields are equal as hashed by hashFields.
+func hashEqual(a, b []any) bool {
+	ke := newKeyEncoder()
+	all := make([]int, len(a))
+	for i := range all {
+		all[i] = i
+	}
+	ka, errA := ke.key(a, all)
+	kb, errB := ke.key(b, all)
+	if errA != nil || errB != nil {
+		return fmt.Sprintf("%#v", a) == fmt.Sprintf("%#v", b)
+	}
+	return ka == kb
+}
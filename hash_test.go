@@ -0,0 +1,100 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	newBuf := func(rows ...[]any) *Buffer {
+		b := &Buffer{Columns: []string{"ID", "Name"}}
+		for _, r := range rows {
+			b.AddRow(r)
+		}
+		return b
+	}
+	a := newBuf([]any{int64(1), "A"}, []any{int64(2), nil})
+	same := newBuf([]any{int64(1), "A"}, []any{int64(2), nil})
+	swapped := newBuf([]any{int64(2), nil}, []any{int64(1), "A"})
+	typed := newBuf([]any{int64(1), "A"}, []any{int64(2), ""})
+
+	hash := func(b *Buffer, unordered bool) [32]byte {
+		var h [32]byte
+		var err error
+		if unordered {
+			h, err = b.HashUnordered()
+		} else {
+			h, err = b.Hash()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	if hash(a, false) != hash(same, false) {
+		t.Fatal("equal buffers have different hashes")
+	}
+	if hash(a, false) == hash(swapped, false) {
+		t.Fatal("ordered hash ignored row order")
+	}
+	if hash(a, true) != hash(swapped, true) {
+		t.Fatal("unordered hash depends on row order")
+	}
+	if hash(a, false) == hash(typed, false) || hash(a, true) == hash(typed, true) {
+		t.Fatal("NULL and empty string hash the same")
+	}
+	if hash(a, true) == hash(newBuf([]any{int64(1), "A"}), true) {
+		t.Fatal("unordered hash ignored a row")
+	}
+}
+
+func TestRowHash(t *testing.T) {
+	b := &Buffer{Columns: []string{"ID", "Name", "Code"}}
+	b.AddRow([]any{int64(1), "A", []byte("x")})
+	b.AddRow([]any{int64(2), "B", nil})
+	b.AddRow([]any{int64(1), "C", []byte("x")})
+	b.AddRow([]any{"1", "D", []byte("x")})
+
+	r0, r2, r3 := b.Rows[0], b.Rows[2], b.Rows[3]
+	if r0.Hash("ID", "Code") != r2.Hash("ID", "Code") {
+		t.Fatal("equal keys have different hashes")
+	}
+	if r0.Hash() == r2.Hash() {
+		t.Fatal("different rows have the same hash")
+	}
+	if r0.Hash("ID") == r3.Hash("ID") {
+		t.Fatal("int64 and string values have the same hash")
+	}
+	if g, w := r0.Hash("ID"), uint64(0x82f2407b4e8902a); g != w {
+		t.Fatalf("hash is not stable, got %#x, want %#x", g, w)
+	}
+
+	idx, err := b.HashIndex("ID", "Code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := []struct {
+		Name   string
+		Values []any
+		Want   []int
+	}{
+		{"match", []any{int64(1), []byte("x")}, []int{0, 2}},
+		{"null", []any{int64(2), nil}, []int{1}},
+		{"type", []any{"1", []byte("x")}, []int{3}},
+		{"none", []any{int64(3), nil}, nil},
+		{"count", []any{int64(1)}, nil},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			g := idx.Lookup(item.Values...)
+			if len(g) != len(item.Want) {
+				t.Fatalf("got %v, want %v", g, item.Want)
+			}
+			for i := range g {
+				if g[i] != item.Want[i] {
+					t.Fatalf("got %v, want %v", g, item.Want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+type queryInfoKey struct{}
+
+// QueryInfo describes a query run by this package, such as by NewSet or
+// NewCursor. Queryer wrappers, such as LoggingQueryer, find it in the context
+// passed to QueryContext with QueryInfoFrom to learn how the fill of the
+// query ended and which parameters are sensitive.
+type QueryInfo struct {
+	c *fillConfig
+
+	mu       sync.Mutex
+	done     []func(stats FillStats, err error)
+	finished bool
+}
+
+// FillStats describes the rows read for a query.
+type FillStats struct {
+	Rows       int   // Rows read across all result sets.
+	ResultSets int   // Result sets read.
+	Bytes      int64 // Estimated size of the buffered rows, as reported by SizeBytes. Zero if the rows were not buffered.
+}
+
+// QueryInfoFrom returns the QueryInfo for a query run by this package.
+func QueryInfoFrom(ctx context.Context) (*QueryInfo, bool) {
+	qi, ok := ctx.Value(queryInfoKey{}).(*QueryInfo)
+	return qi, ok
+}
+
+// OnDone registers fn to be called once when the rows of the query have been
+// read, with what was read and any error that stopped the fill.
+func (qi *QueryInfo) OnDone(fn func(stats FillStats, err error)) {
+	qi.mu.Lock()
+	defer qi.mu.Unlock()
+	qi.done = append(qi.done, fn)
+}
+
+// RedactParams is like the RedactParams function, also replacing parameters
+// marked sensitive by the query options. The params are those passed to
+// QueryContext, which no longer contain Option values.
+func (qi *QueryInfo) RedactParams(r Redactor, text string, params []any) []any {
+	return redactParams(qi.c, r, text, params)
+}
+
+// finish calls the OnDone functions with the stats returned by fn, which is
+// only called if there are any. It may be called on a nil QueryInfo.
+func (qi *QueryInfo) finish(err error, fn func() FillStats) {
+	if qi == nil {
+		return
+	}
+	qi.mu.Lock()
+	done := qi.done
+	if qi.finished {
+		done = nil
+	}
+	qi.finished = true
+	qi.mu.Unlock()
+
+	if len(done) == 0 {
+		return
+	}
+	stats := fn()
+	for _, fn := range done {
+		fn(stats, err)
+	}
+}
+
+// query runs the query with the Option values removed from params, passing
+// a QueryInfo to Queryer wrappers in the context. The options are returned
+// even if the query fails.
+func query(ctx context.Context, q Queryer, text string, params []any) (*sql.Rows, []Option, *QueryInfo, error) {
+	params, opts := splitOptions(params)
+	qi := &QueryInfo{c: newFillConfig(opts)}
+	rows, err := q.QueryContext(context.WithValue(ctx, queryInfoKey{}, qi), text, params...)
+	if err != nil {
+		return nil, opts, nil, err
+	}
+	return rows, opts, qi, nil
+}
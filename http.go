@@ -0,0 +1,168 @@
+package table
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Handler returns an http.Handler that serves the buffer returned by fn.
+// The format is chosen from the Accept header of the request:
+//
+//   - application/json, the default, as written by json.Marshal.
+//   - text/csv, as written by WriteCSV.
+//   - text/html, as a table.
+//
+// Rows are written as they are encoded, without first encoding the whole
+// buffer. A request that accepts none of the formats is answered with
+// 406 Not Acceptable. If fn returns an error the response is a 500 Internal
+// Server Error; the error text is not sent to the client.
+func Handler(fn func(*http.Request) (*Buffer, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		format := negotiate(r.Header.Get("Accept"), "application/json", "text/csv", "text/html")
+		if len(format) == 0 {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return
+		}
+		b, err := fn(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", format+"; charset=utf-8")
+		if r.Method == http.MethodHead {
+			return
+		}
+		switch format {
+		case "text/csv":
+			b.WriteCSV(w)
+		case "text/html":
+			b.writeHTML(w)
+		default:
+			b.writeJSON(w)
+		}
+	})
+}
+
+// negotiate returns the offer most preferred by the Accept header, in the
+// order of offers when equally preferred, or "" if none are acceptable.
+// An empty header accepts the first offer.
+func negotiate(accept string, offers ...string) string {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return offers[0]
+	}
+	best, bestQ, bestSpecific := "", 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		for _, offer := range offers {
+			specific := 0
+			switch {
+			case mediaType == offer:
+				specific = 2
+			case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaType, "*")):
+				specific = 1
+			case mediaType == "*/*":
+			default:
+				continue
+			}
+			if q > bestQ || (q == bestQ && specific > bestSpecific) {
+				best, bestQ, bestSpecific = offer, q, specific
+			}
+			if specific == 0 {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// MarshalJSON encodes the buffer as its columns and rows, including rows on disk.
+func (b *Buffer) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// writeJSON writes the buffer as MarshalJSON does, one row at a time.
+func (b *Buffer) writeJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	columns, err := json.Marshal(b.Columns)
+	if err != nil {
+		return err
+	}
+	bw.WriteString(`{"Columns":`)
+	bw.Write(columns)
+	bw.WriteString(`,"Rows":[`)
+	err = b.Each(func(i int, row Row) error {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bb, err := json.Marshal(row.Field)
+		if err != nil {
+			return err
+		}
+		_, err = bw.Write(bb)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
+
+// writeHTML writes the buffer as an HTML document with a single table,
+// with NULL values left empty.
+func (b *Buffer) writeHTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<!DOCTYPE html>\n")
+	if err := b.writeHTMLTable(bw, newTextConfig(nil)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeHTMLTable writes the buffer as an HTML table, formatting fields with c.
+func (b *Buffer) writeHTMLTable(bw *bufio.Writer, c *textConfig) error {
+	bw.WriteString("<table>\n<thead><tr>")
+	for _, n := range b.Columns {
+		bw.WriteString("<th>" + html.EscapeString(n) + "</th>")
+	}
+	bw.WriteString("</tr></thead>\n<tbody>\n")
+	err := b.Each(func(_ int, row Row) error {
+		bw.WriteString("<tr>")
+		for _, f := range row.Field {
+			if _, err := bw.WriteString("<td>" + html.EscapeString(c.format(f)) + "</td>"); err != nil {
+				return err
+			}
+		}
+		_, err := bw.WriteString("</tr>\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = bw.WriteString("</tbody>\n</table>\n")
+	return err
+}
@@ -0,0 +1,141 @@
+package table
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	b := &Buffer{Columns: []string{"ID", "Name"}}
+	b.AddRow([]any{int64(1), "<a>"})
+	b.AddRow([]any{int64(2), nil})
+
+	want, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := []struct {
+		Name   string
+		Accept string
+		Err    error
+		Status int
+		Type   string
+		Body   string
+	}{
+		{Name: "default", Status: 200, Type: "application/json; charset=utf-8", Body: string(want) + "\n"},
+		{Name: "csv", Accept: "text/html;q=0.5, text/csv", Status: 200, Type: "text/csv; charset=utf-8", Body: "ID,Name\n1,<a>\n2,\n"},
+		{
+			Name:   "html",
+			Accept: "text/*;q=0.9, text/html, */*;q=0.1",
+			Status: 200,
+			Type:   "text/html; charset=utf-8",
+			Body:   "<!DOCTYPE html>\n<table>\n<thead><tr><th>ID</th><th>Name</th></tr></thead>\n<tbody>\n<tr><td>1</td><td>&lt;a&gt;</td></tr>\n<tr><td>2</td><td></td></tr>\n</tbody>\n</table>\n",
+		},
+		{Name: "wildcard", Accept: "image/png, */*;q=0.1", Status: 200, Type: "application/json; charset=utf-8", Body: string(want) + "\n"},
+		{Name: "not-acceptable", Accept: "image/png", Status: 406, Type: "text/plain; charset=utf-8", Body: "Not Acceptable\n"},
+		{Name: "error", Err: errors.New("secret"), Status: 500, Type: "text/plain; charset=utf-8", Body: "Internal Server Error\n"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			h := Handler(func(*http.Request) (*Buffer, error) {
+				if item.Err != nil {
+					return nil, item.Err
+				}
+				return b, nil
+			})
+			req := httptest.NewRequest("GET", "/", nil)
+			if len(item.Accept) > 0 {
+				req.Header.Set("Accept", item.Accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if g, w := rec.Code, item.Status; g != w {
+				t.Fatalf("status got %d want %d", g, w)
+			}
+			if g, w := rec.Header().Get("Content-Type"), item.Type; g != w {
+				t.Fatalf("content type got %q want %q", g, w)
+			}
+			if g, w := rec.Body.String(), item.Body; g != w {
+				t.Fatalf("body got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	res := fakeResult{
+		Columns: []string{"ID", "Name"},
+		Rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), nil}},
+	}
+	list := []struct {
+		Name   string
+		Accept string
+		Result fakeResult
+		Type   string
+		Body   string
+	}{
+		{
+			Name:   "ndjson",
+			Result: res,
+			Type:   "application/x-ndjson; charset=utf-8",
+			Body:   "[\"ID\",\"Name\"]\n[1,\"a\"]\n[2,null]\n",
+		},
+		{
+			Name:   "events",
+			Accept: "text/event-stream",
+			Result: res,
+			Type:   "text/event-stream; charset=utf-8",
+			Body:   "event: columns\ndata: [\"ID\",\"Name\"]\n\ndata: [1,\"a\"]\n\ndata: [2,null]\n\nevent: end\ndata: {\"Rows\":2}\n\n",
+		},
+		{
+			Name:   "error",
+			Result: fakeResult{Columns: []string{"ID"}, Rows: [][]driver.Value{{int64(1)}}, Err: errors.New("secret")},
+			Type:   "application/x-ndjson; charset=utf-8",
+			Body:   "[\"ID\"]\n[1]\n{\"Error\":\"query failed\"}\n",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			db := openFake(t, fakeSet(item.Result))
+			h := StreamHandler(db, func(r *http.Request) (string, []any, error) {
+				return "select ID, Name", nil, nil
+			}, 0)
+			req := httptest.NewRequest("GET", "/", nil)
+			if len(item.Accept) > 0 {
+				req.Header.Set("Accept", item.Accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if g, w := rec.Header().Get("Content-Type"), item.Type; g != w {
+				t.Fatalf("content type got %q want %q", g, w)
+			}
+			if g, w := rec.Body.String(), item.Body; g != w {
+				t.Fatalf("body got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	rec := httptest.NewRecorder()
+	s := &rowStream{w: bufio.NewWriter(rec), rc: http.NewResponseController(rec), events: true}
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(done)
+	}()
+	s.heartbeat(time.Millisecond, done)
+	if !strings.HasPrefix(rec.Body.String(), ": heartbeat\n\n") {
+		t.Fatalf("expected heartbeat, got %q", rec.Body.String())
+	}
+	if !rec.Flushed {
+		t.Fatal("expected flush")
+	}
+}
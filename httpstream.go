@@ -0,0 +1,178 @@
+package table
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StreamHandler returns an http.Handler that runs the query returned by fn
+// and writes each row as it is read, without buffering the result, so long
+// exports start at once and are not held in memory. The format is chosen
+// from the Accept header of the request:
+//
+//   - application/x-ndjson, the default, writes the column names as a JSON
+//     array on the first line, then each row as a JSON array on its own line.
+//     An error after the rows have started is written as a final line of
+//     {"Error":"query failed"}.
+//   - text/event-stream writes Server-Sent Events: a "columns" event, a
+//     message event for each row, then an "end" or "error" event.
+//
+// While rows are slow to arrive a heartbeat is written every heartbeat
+// interval, 15 seconds if zero: an empty line for NDJSON or a comment for
+// events. The query runs with the request context, so it is canceled when
+// the client disconnects. Error text is not sent to the client.
+func StreamHandler(q Queryer, fn func(*http.Request) (sql string, params []any, err error), heartbeat time.Duration) http.Handler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		format := negotiate(r.Header.Get("Accept"), "application/x-ndjson", "text/event-stream")
+		if len(format) == 0 {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return
+		}
+		sql, params, err := fn(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		cur, err := NewCursor(r.Context(), q, sql, params...)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		defer cur.Close()
+
+		w.Header().Set("Content-Type", format+"; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		s := &rowStream{
+			w:      bufio.NewWriter(w),
+			rc:     http.NewResponseController(w),
+			events: format == "text/event-stream",
+		}
+		s.flush()
+
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.heartbeat(heartbeat, done)
+		}()
+		defer wg.Wait()
+		defer close(done)
+
+		columns := false
+		for cur.Next() {
+			if !columns {
+				columns = true
+				s.columns(cur.Columns())
+			}
+			s.row(cur.Row().Field)
+		}
+		if !columns {
+			s.columns(cur.Columns())
+		}
+		s.end(cur.Err())
+	})
+}
+
+// rowStream writes rows to an HTTP response while a heartbeat runs.
+type rowStream struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	rc      *http.ResponseController
+	events  bool
+	rows    int
+	written bool // Written since the last heartbeat.
+}
+
+// Rows written between flushes of the response.
+const streamFlushRows = 100
+
+func (s *rowStream) write(event string, v any) {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		bb, _ = json.Marshal(map[string]string{"Error": "cannot encode row"})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.events {
+		if len(event) > 0 {
+			s.w.WriteString("event: " + event + "\n")
+		}
+		s.w.WriteString("data: ")
+		s.w.Write(bb)
+		s.w.WriteString("\n\n")
+	} else {
+		s.w.Write(bb)
+		s.w.WriteByte('\n')
+	}
+	s.written = true
+}
+
+func (s *rowStream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Flush()
+	s.rc.Flush()
+}
+
+func (s *rowStream) columns(names []string) {
+	if names == nil {
+		names = []string{}
+	}
+	s.write("columns", names)
+	s.flush()
+}
+
+func (s *rowStream) row(field []any) {
+	s.write("", field)
+	s.rows++
+	if s.rows%streamFlushRows == 0 {
+		s.flush()
+	}
+}
+
+func (s *rowStream) end(err error) {
+	switch {
+	case err != nil && s.events:
+		s.write("error", map[string]string{"Error": "query failed"})
+	case err != nil:
+		s.write("", map[string]string{"Error": "query failed"})
+	case s.events:
+		s.write("end", map[string]int{"Rows": s.rows})
+	}
+	s.flush()
+}
+
+// heartbeat flushes written rows, or writes a heartbeat if there are none,
+// every interval until done is closed.
+func (s *rowStream) heartbeat(interval time.Duration, done <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+		s.mu.Lock()
+		if !s.written {
+			if s.events {
+				s.w.WriteString(": heartbeat\n\n")
+			} else {
+				s.w.WriteByte('\n')
+			}
+		}
+		s.written = false
+		s.w.Flush()
+		s.rc.Flush()
+		s.mu.Unlock()
+	}
+}
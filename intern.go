@@ -0,0 +1,56 @@
+package table
+
+// Limits that keep the intern table from growing without bound when a
+// column holds mostly unique values.
+const (
+	internMaxLen     = 256
+	internMaxEntries = 1 << 16
+)
+
+// InternStrings shares a single copy of repeated string and []byte values
+// within a fill, such as status or type columns with few distinct values.
+// Interned []byte values share a backing array and must not be modified.
+// Values longer then 256 bytes are not interned, and no new values are
+// interned after 65536 distinct values have been seen.
+func InternStrings() Option {
+	return func(c *fillConfig) {
+		c.intern = &interner{
+			strings: make(map[string]string),
+			bytes:   make(map[string][]byte),
+		}
+	}
+}
+
+type interner struct {
+	strings map[string]string
+	bytes   map[string][]byte
+}
+
+func (in *interner) field(field []any) {
+	for i, f := range field {
+		switch v := f.(type) {
+		case string:
+			if len(v) > internMaxLen {
+				continue
+			}
+			if s, ok := in.strings[v]; ok {
+				field[i] = s
+				continue
+			}
+			if len(in.strings) < internMaxEntries {
+				in.strings[v] = v
+			}
+		case []byte:
+			if len(v) > internMaxLen {
+				continue
+			}
+			if bb, ok := in.bytes[string(v)]; ok {
+				field[i] = bb
+				continue
+			}
+			if len(in.bytes) < internMaxEntries {
+				in.bytes[string(v)] = v
+			}
+		}
+	}
+}
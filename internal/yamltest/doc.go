@@ -0,0 +1,5 @@
+// Package yamltest tests the YAML methods of the table package against
+// gopkg.in/yaml.v3. The table package implements the marshaler interfaces
+// without importing a YAML package, so these tests live in their own module
+// to keep yaml.v3 out of the table module's requirements.
+package yamltest
@@ -0,0 +1,9 @@
+module github.com/golang-sql/table/internal/yamltest
+
+go 1.21
+
+require github.com/golang-sql/table v0.0.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+replace github.com/golang-sql/table => ../../
@@ -0,0 +1,170 @@
+package yamltest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-sql/table"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshal(t *testing.T) {
+	b := &table.Buffer{Columns: []string{"ID", "Name", "Data", "At"}}
+	b.AddRow([]any{int64(1), "R1", []byte("x"), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)})
+	b.AddRow([]any{int64(2), nil, nil, nil})
+
+	list := []struct {
+		Name  string
+		Value any
+		Want  string
+		Read  string // The buffer read back from Want.
+	}{
+		{
+			Name:  "rows",
+			Value: b,
+			Want: `columns: [ID, Name, Data, At]
+rows:
+    - - 1
+      - R1
+      - x
+      - 2024-01-02T03:04:05Z
+    - - 2
+      - null
+      - null
+      - null
+`,
+			Read: `[ID Name Data At] [[1 R1 x 2024-01-02 03:04:05 +0000 UTC] [2 <nil> <nil> <nil>]]`,
+		},
+		{
+			Name:  "maps",
+			Value: table.YAMLBuffer{Buffer: b, Maps: true},
+			Want: `- At: 2024-01-02T03:04:05Z
+  Data: x
+  ID: 1
+  Name: R1
+- At: null
+  Data: null
+  ID: 2
+  Name: null
+`,
+			Read: `[At Data ID Name] [[2024-01-02 03:04:05 +0000 UTC x 1 R1] [<nil> <nil> 2 <nil>]]`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			bb, err := yaml.Marshal(item.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := string(bb), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+
+			got := &table.Buffer{}
+			if err := yaml.Unmarshal(bb, got); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := rows(got), item.Read; g != w {
+				t.Fatalf("read back got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	list := []struct {
+		Name  string
+		YAML  string
+		Want  string
+		Error string
+	}{
+		{
+			Name: "rows",
+			YAML: "columns: [ID, Score, OK]\nrows:\n  - [1, 1.5, true]\n  - [9223372036854775807, null, false]\n",
+			Want: `[ID Score OK] [[int64(1) 1.5 true] [int64(9223372036854775807) <nil> false]]`,
+		},
+		{
+			// Columns decoded from maps are sorted by name.
+			Name: "maps",
+			YAML: "- {Name: R1, ID: 1}\n- {ID: 2, Extra: x}\n",
+			Want: `[Extra ID Name] [[<nil> int64(1) R1] [x int64(2) <nil>]]`,
+		},
+		{
+			Name:  "rows-overflow",
+			YAML:  "columns: [ID]\nrows:\n  - [18446744073709551615]\n",
+			Error: `row 0, column "ID": value 18446744073709551615 overflows int64`,
+		},
+		{
+			Name:  "maps-overflow",
+			YAML:  "- {ID: 1}\n- {ID: 9223372036854775808}\n",
+			Error: `row 1, column "ID": value 9223372036854775808 overflows int64`,
+		},
+		{
+			Name:  "row-length",
+			YAML:  "columns: [ID, Name]\nrows:\n  - [1]\n",
+			Error: `row 0 has 1 fields, expected 2`,
+		},
+		{
+			Name:  "scalar",
+			YAML:  "hello",
+			Error: "yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `hello` into []map[string]interface {}",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := &table.Buffer{}
+			err := yaml.Unmarshal([]byte(item.YAML), b)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := typedRows(b), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	var set table.Set
+	err := yaml.Unmarshal([]byte("- columns: [A]\n  rows: [[1]]\n- [{B: x}]\n"), &set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%d %s %s", len(set), rows(set[0]), rows(set[1])), "2 [A] [[1]] [B] [[x]]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
+
+// rows formats the columns and row values of b.
+func rows(b *table.Buffer) string {
+	var list [][]any
+	for _, row := range b.Rows {
+		list = append(list, row.Field)
+	}
+	return fmt.Sprint(b.Columns, " ", list)
+}
+
+// typedRows formats the columns and row values of b, showing int64 values by type.
+func typedRows(b *table.Buffer) string {
+	var list [][]string
+	for _, row := range b.Rows {
+		var r []string
+		for _, v := range row.Field {
+			if i, ok := v.(int64); ok {
+				r = append(r, fmt.Sprintf("int64(%d)", i))
+				continue
+			}
+			r = append(r, fmt.Sprint(v))
+		}
+		list = append(list, r)
+	}
+	return fmt.Sprint(b.Columns, " ", list)
+}
@@ -0,0 +1,86 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"io"
+)
+
+// LazySet reads result sets one at a time, only buffering a result set
+// when Next is called. Result sets that are never requested are not read.
+type LazySet struct {
+	f       *filler
+	started bool
+	done    bool
+	close   bool
+	err     error
+	qi      *QueryInfo
+}
+
+// NewLazySet runs the query and returns a LazySet over its result sets.
+// Any Option values in params configure the fill and are not sent with the query.
+// The LazySet must be closed.
+func NewLazySet(ctx context.Context, q Queryer, sql string, params ...any) (*LazySet, error) {
+	rows, opts, qi, err := query(ctx, q, sql, params)
+	if err != nil {
+		return nil, err
+	}
+	ls := FillLazySet(ctx, rows, opts...)
+	ls.close = true
+	ls.qi = qi
+	return ls, nil
+}
+
+// FillLazySet returns a LazySet over the result sets in rows.
+// The caller remains responsible for closing rows.
+func FillLazySet(ctx context.Context, rows *sql.Rows, opts ...Option) *LazySet {
+	return &LazySet{
+		f: newFiller(ctx, rows, opts),
+	}
+}
+
+// Next buffers and returns the next result set.
+// It returns io.EOF when there are no more result sets.
+// With ContinueOnError, rows skipped in the result set are reported by
+// returning a *RowErrors along with the buffer, and Next may be called again.
+func (ls *LazySet) Next() (*Buffer, error) {
+	if ls.done {
+		return nil, io.EOF
+	}
+	if ls.started {
+		more, err := ls.f.nextResultSet()
+		if err != nil {
+			ls.done = true
+			ls.err = err
+			return nil, err
+		}
+		if !more {
+			ls.done = true
+			ls.f.done()
+			return nil, io.EOF
+		}
+	}
+	ls.started = true
+	if err := ls.f.ctx.Err(); err != nil {
+		ls.done = true
+		ls.err = err
+		return nil, err
+	}
+	table, err := ls.f.fill()
+	if err != nil {
+		ls.done = true
+		ls.err = err
+		return table, err
+	}
+	return table, ls.f.rowErrors()
+}
+
+// Close closes the underlying rows if the LazySet was created by NewLazySet.
+func (ls *LazySet) Close() error {
+	ls.done = true
+	ls.qi.finish(ls.err, ls.f.stats)
+	if ls.close {
+		return ls.f.rows.Close()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLazySet(t *testing.T) {
+	db := openFake(t, fakeSet(
+		fakeResult{Columns: []string{"A"}, Rows: [][]driver.Value{{int64(1)}}},
+		fakeResult{Columns: []string{"B"}, Rows: [][]driver.Value{{int64(2)}, {int64(3)}}},
+	))
+	ls, err := NewLazySet(context.Background(), db, "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ls.Close()
+
+	for i, want := range []string{"A", "B"} {
+		b, err := ls.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g := b.Columns[0]; g != want {
+			t.Fatalf("result %d got column %s want %s", i, g, want)
+		}
+	}
+	if _, err := ls.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
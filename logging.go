@@ -0,0 +1,102 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// LogOption configures a LoggingQueryer.
+type LogOption func(*logConfig)
+
+type logConfig struct {
+	level      slog.Level
+	errorLevel slog.Level
+	params     bool
+	redactor   Redactor
+}
+
+// LogLevel sets the level for queries that succeed. Defaults to slog.LevelDebug.
+func LogLevel(level slog.Level) LogOption {
+	return func(c *logConfig) {
+		c.level = level
+	}
+}
+
+// LogErrorLevel sets the level for queries that fail. Defaults to slog.LevelError.
+func LogErrorLevel(level slog.Level) LogOption {
+	return func(c *logConfig) {
+		c.errorLevel = level
+	}
+}
+
+// LogParams logs the query parameters, passed through RedactParams with r.
+// Parameters are not logged by default.
+func LogParams(r Redactor) LogOption {
+	return func(c *logConfig) {
+		c.params = true
+		c.redactor = r
+	}
+}
+
+// LoggingQueryer returns a Queryer that logs each query to logger with the
+// SQL text, duration, and any error.
+//
+// When the query is run by this package, such as by NewSet or NewCursor, the
+// entry is written once the rows have been read and includes the row count,
+// and the duration covers reading the rows. Otherwise the entry is written
+// when QueryContext returns.
+func LoggingQueryer(q Queryer, logger *slog.Logger, opts ...LogOption) Queryer {
+	c := &logConfig{
+		level:      slog.LevelDebug,
+		errorLevel: slog.LevelError,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return &loggingQueryer{q: q, logger: logger, c: c}
+}
+
+type loggingQueryer struct {
+	q      Queryer
+	logger *slog.Logger
+	c      *logConfig
+}
+
+func (lq *loggingQueryer) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	start := time.Now()
+	qi, ok := QueryInfoFrom(ctx)
+	attrs := []slog.Attr{slog.String("sql", text)}
+	if lq.c.params {
+		if ok {
+			attrs = append(attrs, slog.Any("params", qi.RedactParams(lq.c.redactor, text, params)))
+		} else {
+			attrs = append(attrs, slog.Any("params", RedactParams(lq.c.redactor, text, params)))
+		}
+	}
+
+	rows, err := lq.q.QueryContext(ctx, text, params...)
+	if err != nil || !ok {
+		lq.log(ctx, attrs, start, -1, err)
+		return rows, err
+	}
+	qi.OnDone(func(stats FillStats, err error) {
+		lq.log(ctx, attrs, start, stats.Rows, err)
+	})
+	return rows, nil
+}
+
+// log writes the entry for a query. A negative rowCount is not logged.
+func (lq *loggingQueryer) log(ctx context.Context, attrs []slog.Attr, start time.Time, rowCount int, err error) {
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	if rowCount >= 0 {
+		attrs = append(attrs, slog.Int("rows", rowCount))
+	}
+	level := lq.c.level
+	if err != nil {
+		level = lq.c.errorLevel
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	lq.logger.LogAttrs(ctx, level, "query", attrs...)
+}
@@ -0,0 +1,107 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggingQueryer(t *testing.T) {
+	ctx := context.Background()
+	res := fakeResult{
+		Columns: []string{"ID"},
+		Rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+	}
+	list := []struct {
+		Name  string
+		Query fakeQuery
+		Opts  []LogOption
+		Run   func(q Queryer) error
+		Want  string
+	}{
+		{
+			Name:  "set",
+			Query: fakeSet(res),
+			Run: func(q Queryer) error {
+				_, err := NewSet(ctx, q, "select ID", int64(5), SensitiveParams(1))
+				return err
+			},
+			Want: `level=DEBUG msg=query sql="select ID" rows=2`,
+		},
+		{
+			Name:  "params",
+			Query: fakeSet(res),
+			Opts:  []LogOption{LogParams(nil), LogLevel(slog.LevelInfo)},
+			Run: func(q Queryer) error {
+				_, err := NewSet(ctx, q, "select ID", "secret", int64(5), SensitiveParams(1))
+				return err
+			},
+			Want: `level=INFO msg=query sql="select ID" params="[[REDACTED] 5]" rows=2`,
+		},
+		{
+			Name:  "cursor",
+			Query: fakeSet(res),
+			Run: func(q Queryer) error {
+				c, err := NewCursor(ctx, q, "select ID")
+				if err != nil {
+					return err
+				}
+				c.Next()
+				return c.Close()
+			},
+			Want: `level=DEBUG msg=query sql="select ID" rows=1`,
+		},
+		{
+			Name: "error",
+			Query: func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+				return nil, errors.New("syntax error")
+			},
+			Opts: []LogOption{LogErrorLevel(slog.LevelWarn)},
+			Run: func(q Queryer) error {
+				_, err := NewSet(ctx, q, "selec ID")
+				if err == nil {
+					return errors.New("expected an error")
+				}
+				return nil
+			},
+			Want: `level=WARN msg=query sql="selec ID" error="syntax error"`,
+		},
+		{
+			Name:  "direct",
+			Query: fakeSet(res),
+			Run: func(q Queryer) error {
+				rows, err := q.QueryContext(ctx, "select ID")
+				if err != nil {
+					return err
+				}
+				return rows.Close()
+			},
+			Want: `level=DEBUG msg=query sql="select ID"`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey || a.Key == "duration" {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}))
+			q := LoggingQueryer(openFake(t, item.Query), logger, item.Opts...)
+			if err := item.Run(q); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := strings.TrimSpace(out.String()), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package table
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// MaskFunc returns the masked replacement for a non-NULL field value.
+type MaskFunc func(v any) any
+
+// Mask replaces the values in each named column with the result of its
+// MaskFunc, such as before exporting a result outside of production.
+// NULL values are left as NULL. No values are changed if a column is missing.
+func (b *Buffer) Mask(rules map[string]MaskFunc) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	index := make(map[int]MaskFunc, len(rules))
+	for n, fn := range rules {
+		i, err := b.lookupColumn(n)
+		if err != nil {
+			return err
+		}
+		index[i] = fn
+	}
+	b.unshare()
+	for _, row := range b.Rows {
+		for i, fn := range index {
+			if row.Field[i] != nil {
+				row.Field[i] = fn(row.Field[i])
+			}
+		}
+	}
+	return nil
+}
+
+// maskText returns the value as text.
+func maskText(v any) string {
+	if s, ok := asString(v); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// maskResult returns s as a []byte if the original value was one,
+// otherwise as a string.
+func maskResult(v any, s string) any {
+	if _, ok := v.([]byte); ok {
+		return []byte(s)
+	}
+	return s
+}
+
+// MaskHash replaces values with the hex SHA-256 hash of the salt and the value
+// text. Equal values have equal hashes, so masked columns may still be joined.
+func MaskHash(salt string) MaskFunc {
+	return func(v any) any {
+		sum := sha256.Sum256([]byte(salt + maskText(v)))
+		return maskResult(v, hex.EncodeToString(sum[:]))
+	}
+}
+
+// MaskPartial replaces all but the first keepStart and last keepEnd runes of
+// the value text with mask, such as MaskPartial(0, 4, '*') for card numbers.
+// Values too short to keep any runes are fully masked.
+func MaskPartial(keepStart, keepEnd int, mask rune) MaskFunc {
+	return func(v any) any {
+		r := []rune(maskText(v))
+		start, end := keepStart, len(r)-keepEnd
+		if start >= end {
+			start, end = 0, len(r)
+		}
+		for i := start; i < end; i++ {
+			r[i] = mask
+		}
+		return maskResult(v, string(r))
+	}
+}
+
+// MaskFixed replaces every value with value.
+func MaskFixed(value any) MaskFunc {
+	return func(any) any {
+		return value
+	}
+}
+
+// MaskFake replaces each letter and digit of the value with another of the
+// same kind and case, keeping other characters such as separators. The result
+// depends only on the salt and the value, so equal values mask the same way.
+// Integer values remain integers with the same number of digits; other values
+// become text.
+func MaskFake(salt string) MaskFunc {
+	return func(v any) any {
+		s := maskText(v)
+		sum := sha256.Sum256([]byte(salt + s))
+		seed := binary.LittleEndian.Uint64(sum[:8])
+		next := func(n uint64) uint64 {
+			// xorshift64*
+			seed ^= seed >> 12
+			seed ^= seed << 25
+			seed ^= seed >> 27
+			return (seed * 2685821657736338717) % n
+		}
+		r := []rune(s)
+		for i, c := range r {
+			switch {
+			case c >= '0' && c <= '9':
+				r[i] = '0' + rune(next(10))
+			case unicode.IsUpper(c):
+				r[i] = 'A' + rune(next(26))
+			case unicode.IsLower(c):
+				r[i] = 'a' + rune(next(26))
+			}
+		}
+		switch v.(type) {
+		case int64:
+			// Keep the digit count by avoiding a leading zero.
+			d := 0
+			if len(r) > 0 && r[0] == '-' {
+				d = 1
+			}
+			if len(r) > d+1 && r[d] == '0' {
+				r[d] = '1' + rune(next(9))
+			}
+			if n, err := strconv.ParseInt(string(r), 10, 64); err == nil {
+				return n
+			}
+		}
+		return maskResult(v, string(r))
+	}
+}
@@ -0,0 +1,45 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	list := []struct {
+		Name  string
+		Mask  MaskFunc
+		Value any
+		Want  string
+	}{
+		{Name: "hash", Mask: MaskHash("s"), Value: "alice", Want: `"5e0f217ada7a7a57cce1f1eff23dcba38e99c668961e5e4ffeed5d62c9905245"`},
+		{Name: "hash-null", Mask: MaskHash("s"), Value: nil, Want: `<nil>`},
+		{Name: "partial", Mask: MaskPartial(0, 4, '*'), Value: "4111111111111111", Want: `"************1111"`},
+		{Name: "partial-bytes", Mask: MaskPartial(1, 1, '#'), Value: []byte("secret"), Want: `[]byte{0x73, 0x23, 0x23, 0x23, 0x23, 0x74}`},
+		{Name: "partial-short", Mask: MaskPartial(2, 2, '*'), Value: "abc", Want: `"***"`},
+		{Name: "fixed", Mask: MaskFixed("x"), Value: int64(5), Want: `"x"`},
+		{Name: "fake", Mask: MaskFake("s"), Value: "Ab-12", Want: `"Lg-84"`},
+		{Name: "fake-int", Mask: MaskFake("s"), Value: int64(-1234), Want: `-8067`},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			buf := &Buffer{Columns: []string{"V"}}
+			buf.AddRow([]any{item.Value})
+			if err := buf.Mask(map[string]MaskFunc{"V": item.Mask}); err != nil {
+				t.Fatal(err)
+			}
+			got := fmt.Sprintf("%#v", buf.Rows[0].Field[0])
+			if item.Value == nil {
+				got = fmt.Sprint(buf.Rows[0].Field[0])
+			}
+			if g, w := got, item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+
+	buf := &Buffer{Columns: []string{"V"}}
+	if err := buf.Mask(map[string]MaskFunc{"W": MaskFixed(nil)}); err == nil {
+		t.Fatal("expected error for a missing column")
+	}
+}
@@ -0,0 +1,171 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrMergeConflict is matched by errors.Is when Merge finds a conflict and
+// no ConflictFunc is given.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeConflict is a row changed differently in both merged buffers.
+// A row that is not present, such as one deleted on one side, has a nil Field.
+type MergeConflict struct {
+	Key  []any
+	Base Row // Row in base. Not present in a two-way merge.
+	A    Row
+	B    Row
+}
+
+// ConflictFunc resolves a MergeConflict, returning the fields of the merged
+// row, or nil to leave the row out of the result.
+type ConflictFunc func(c MergeConflict) ([]any, error)
+
+// PreferA resolves every conflict with the row from a, leaving the row out
+// if it was deleted in a.
+func PreferA(c MergeConflict) ([]any, error) {
+	return c.A.Field, nil
+}
+
+// PreferB resolves every conflict with the row from b, leaving the row out
+// if it was deleted in b.
+func PreferB(c MergeConflict) ([]any, error) {
+	return c.B.Field, nil
+}
+
+// Merge combines the changes made in a and b to the rows of base, matching
+// rows by the key columns, which must be unique in each buffer. A row changed,
+// added, or deleted on only one side takes that change, as does a row changed
+// the same way on both sides. Rows changed differently on each side are passed
+// to resolve; if resolve is nil an error matching ErrMergeConflict is returned.
+//
+// If base is nil, the merge is two-way: every row of a and b is kept, and rows
+// with the same key but different values are conflicts.
+//
+// The buffers must have the same columns in the same order. The result has the
+// rows of a in order, followed by the rows only in b. Values are compared by
+// type and value, as by Diff.
+func Merge(base, a, b *Buffer, key []string, resolve ConflictFunc) (*Buffer, error) {
+	for _, x := range []*Buffer{base, b} {
+		if x != nil && !slices.Equal(x.Columns, a.Columns) {
+			return nil, fmt.Errorf("%w: columns %q, want %q", ErrSchemaMismatch, x.Columns, a.Columns)
+		}
+	}
+	keys, err := a.keyIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	ke := newKeyEncoder()
+	index := func(x *Buffer) (map[string]int, error) {
+		if x == nil {
+			return nil, nil
+		}
+		return x.rowsByKey(ke, keys)
+	}
+	baseKeys, err := index(base)
+	if err != nil {
+		return nil, fmt.Errorf("base: %w", err)
+	}
+	aKeys, err := index(a)
+	if err != nil {
+		return nil, fmt.Errorf("a: %w", err)
+	}
+	bKeys, err := index(b)
+	if err != nil {
+		return nil, fmt.Errorf("b: %w", err)
+	}
+	all := make([]int, len(a.Columns))
+	for i := range all {
+		all[i] = i
+	}
+	row := func(x *Buffer, keys map[string]int, k string) Row {
+		if ri, ok := keys[k]; ok {
+			return x.Rows[ri]
+		}
+		return Row{}
+	}
+	equal := func(r1, r2 Row) (bool, error) {
+		if r1.Field == nil || r2.Field == nil {
+			return r1.Field == nil && r2.Field == nil, nil
+		}
+		k1, err := ke.key(r1.Field, all)
+		if err != nil {
+			return false, err
+		}
+		k2, err := ke.key(r2.Field, all)
+		return k1 == k2, err
+	}
+
+	out := &Buffer{Columns: append([]string(nil), a.Columns...)}
+	merge := func(k string, ra, rb Row) error {
+		rbase := row(base, baseKeys, k)
+		var field []any
+		sameA, err := equal(ra, rbase)
+		if err != nil {
+			return err
+		}
+		sameB, err := equal(rb, rbase)
+		if err != nil {
+			return err
+		}
+		same, err := equal(ra, rb)
+		if err != nil {
+			return err
+		}
+		switch {
+		case same:
+			field = ra.Field
+		case base != nil && sameB:
+			field = ra.Field
+		case base != nil && sameA:
+			field = rb.Field
+		case base == nil && rb.Field == nil:
+			field = ra.Field
+		case base == nil && ra.Field == nil:
+			field = rb.Field
+		default:
+			c := MergeConflict{Base: rbase, A: ra, B: rb}
+			for _, r := range []Row{ra, rb, rbase} {
+				if r.Field != nil {
+					c.Key = pick(r.Field, keys)
+					break
+				}
+			}
+			if resolve == nil {
+				return fmt.Errorf("%w: key %v", ErrMergeConflict, c.Key)
+			}
+			field, err = resolve(c)
+			if err != nil {
+				return err
+			}
+			if field != nil && len(field) != len(out.Columns) {
+				return fmt.Errorf("resolved row for key %v has %d fields, expected %d", c.Key, len(field), len(out.Columns))
+			}
+		}
+		if field != nil {
+			out.AddRow(append([]any(nil), field...))
+		}
+		return nil
+	}
+
+	for _, ra := range a.Rows {
+		k, _ := ke.key(ra.Field, keys)
+		if err := merge(k, ra, row(b, bKeys, k)); err != nil {
+			return nil, err
+		}
+	}
+	if b != nil {
+		for _, rb := range b.Rows {
+			k, _ := ke.key(rb.Field, keys)
+			if _, ok := aKeys[k]; ok {
+				continue
+			}
+			if err := merge(k, Row{}, rb); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	newBuffer := func(rows ...[]any) *Buffer {
+		b := &Buffer{
+			Columns: []string{"id", "name"},
+		}
+		for _, r := range rows {
+			b.AddRow(r)
+		}
+		return b
+	}
+	base := newBuffer(
+		[]any{int64(1), "R1"},
+		[]any{int64(2), "R2"},
+		[]any{int64(3), "R3"},
+		[]any{int64(4), "R4"},
+		[]any{int64(5), "R5"},
+	)
+	a := newBuffer(
+		[]any{int64(1), "A1"}, // Changed in a.
+		[]any{int64(2), "R2"}, // Changed in b.
+		[]any{int64(3), "A3"}, // Changed in both.
+		[]any{int64(6), "A6"}, // Added in a.
+		// 4 deleted in a, 5 deleted in a and changed in b.
+	)
+	b := newBuffer(
+		[]any{int64(1), "R1"},
+		[]any{int64(2), "B2"},
+		[]any{int64(3), "B3"},
+		[]any{int64(4), "R4"},
+		[]any{int64(5), "B5"},
+		[]any{int64(7), "B7"}, // Added in b.
+	)
+	var conflicts []string
+	record := func(resolve ConflictFunc) ConflictFunc {
+		return func(c MergeConflict) ([]any, error) {
+			conflicts = append(conflicts, fmt.Sprint(c.Key, c.Base.Field, c.A.Field, c.B.Field))
+			return resolve(c)
+		}
+	}
+
+	list := []struct {
+		Name      string
+		Base      *Buffer
+		Resolve   ConflictFunc
+		Want      string
+		Conflicts string
+		Error     string
+	}{
+		{
+			Name:      "prefer a",
+			Base:      base,
+			Resolve:   record(PreferA),
+			Want:      "[[1 A1] [2 B2] [3 A3] [6 A6] [7 B7]]",
+			Conflicts: "[3] [3 R3] [3 A3] [3 B3]; [5] [5 R5] [] [5 B5]",
+		},
+		{
+			Name:      "prefer b",
+			Base:      base,
+			Resolve:   record(PreferB),
+			Want:      "[[1 A1] [2 B2] [3 B3] [6 A6] [5 B5] [7 B7]]",
+			Conflicts: "[3] [3 R3] [3 A3] [3 B3]; [5] [5 R5] [] [5 B5]",
+		},
+		{
+			Name:      "two-way",
+			Resolve:   record(PreferB),
+			Want:      "[[1 R1] [2 B2] [3 B3] [6 A6] [4 R4] [5 B5] [7 B7]]",
+			Conflicts: "[1] [] [1 A1] [1 R1]; [2] [] [2 R2] [2 B2]; [3] [] [3 A3] [3 B3]",
+		},
+		{
+			Name:  "unresolved",
+			Base:  base,
+			Error: "merge conflict: key [3]",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			conflicts = nil
+			out, err := Merge(item.Base, a, b, []string{"id"}, item.Resolve)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			fields := make([][]any, len(out.Rows))
+			for i, row := range out.Rows {
+				fields[i] = row.Field
+			}
+			if g, w := fmt.Sprint(fields), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+			if g, w := strings.Join(conflicts, "; "), item.Conflicts; g != w {
+				t.Fatalf("conflicts got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tables returns the tables and views of the current database, or schema for
+// DialectMySQL, with the columns:
+//
+//	Schema string
+//	Name   string
+//	Type   string // "BASE TABLE" or "VIEW".
+func Tables(ctx context.Context, q Queryer, dialect Dialect) (*Buffer, error) {
+	var text string
+	switch dialect {
+	case DialectSQLite:
+		text = `select 'main', name, case type when 'table' then 'BASE TABLE' else 'VIEW' end
+from sqlite_master where type in ('table', 'view') and name not like 'sqlite_%' order by name`
+	case DialectMySQL:
+		text = `select table_schema, table_name, table_type from information_schema.tables
+where table_schema = database() order by table_name`
+	default:
+		text = `select table_schema, table_name, table_type from information_schema.tables
+where table_schema not in ('information_schema', 'pg_catalog') order by table_schema, table_name`
+	}
+	b, err := NewBuffer(ctx, q, text)
+	if err != nil {
+		return nil, err
+	}
+	return relabel(b, []string{"Schema", "Name", "Type"}, nil), nil
+}
+
+// Columns returns the columns of a table, in order, with the columns:
+//
+//	Name     string
+//	Position int64 // Starting at 1.
+//	DataType string // As named by the database, such as "integer".
+//	Nullable bool
+//	Default  any    // Default expression text, or nil.
+//
+// The table name may be qualified with a schema, such as "sales.orders".
+// Otherwise the current schema is used, except for DialectGeneric.
+func Columns(ctx context.Context, q Queryer, dialect Dialect, table string) (*Buffer, error) {
+	var text string
+	var params []any
+	switch dialect {
+	case DialectSQLite:
+		schema, name := splitTableName(table)
+		if len(schema) == 0 {
+			schema = "main"
+		}
+		text = `select name, cid + 1, type, "notnull" = 0, dflt_value from pragma_table_info(?, ?) order by cid`
+		params = []any{name, schema}
+	default:
+		text = `select column_name, ordinal_position, data_type, is_nullable, column_default
+from information_schema.columns where table_name = ` + dialect.Placeholder(1) + ` and ` + schemaFilter(dialect, table) + `
+order by ordinal_position`
+		params = tableParams(table)
+	}
+	b, err := NewBuffer(ctx, q, text, params...)
+	if err != nil {
+		return nil, err
+	}
+	return relabel(b, []string{"Name", "Position", "DataType", "Nullable", "Default"}, map[int]func(any) any{
+		1: metaInt,
+		3: metaBool,
+	}), nil
+}
+
+// Indexes returns the columns of each index of a table, ordered by index
+// name and column position, with the columns:
+//
+//	Name     string // Index name.
+//	Column   string
+//	Position int64 // Position of the column in the index, starting at 1.
+//	Unique   bool
+//	Primary  bool
+//
+// The table name may be qualified with a schema, as with Columns.
+// DialectGeneric is not supported, as information_schema has no indexes.
+func Indexes(ctx context.Context, q Queryer, dialect Dialect, table string) (*Buffer, error) {
+	var text string
+	params := tableParams(table)
+	switch dialect {
+	default:
+		return nil, fmt.Errorf("indexes are not supported for dialect %v", dialect)
+	case DialectPostgres:
+		text = `select i.relname, a.attname, k.n, ix.indisunique, ix.indisprimary
+from pg_index ix
+join pg_class t on t.oid = ix.indrelid
+join pg_class i on i.oid = ix.indexrelid
+join pg_namespace ns on ns.oid = t.relnamespace
+cross join lateral unnest(ix.indkey) with ordinality as k(attnum, n)
+join pg_attribute a on a.attrelid = t.oid and a.attnum = k.attnum
+where t.relname = $1 and ` + strings.ReplaceAll(schemaFilter(dialect, table), "table_schema", "ns.nspname") + `
+order by i.relname, k.n`
+	case DialectMySQL:
+		text = `select index_name, column_name, seq_in_index, non_unique = 0, index_name = 'PRIMARY'
+from information_schema.statistics where table_name = ? and ` + schemaFilter(dialect, table) + `
+order by index_name, seq_in_index`
+	case DialectSQLServer:
+		text = `select i.name, c.name, ic.key_ordinal, i.is_unique, i.is_primary_key
+from sys.indexes i
+join sys.index_columns ic on ic.object_id = i.object_id and ic.index_id = i.index_id
+join sys.columns c on c.object_id = ic.object_id and c.column_id = ic.column_id
+where ic.key_ordinal > 0 and i.object_id = object_id(@p1)
+order by i.name, ic.key_ordinal`
+		params = []any{table}
+	case DialectSQLite:
+		schema, name := splitTableName(table)
+		if len(schema) == 0 {
+			schema = "main"
+		}
+		text = `select il.name, ii.name, ii.seqno + 1, il."unique", il.origin = 'pk'
+from pragma_index_list(?, ?) il, pragma_index_info(il.name, ?) ii
+order by il.name, ii.seqno`
+		params = []any{name, schema, schema}
+	}
+	b, err := NewBuffer(ctx, q, text, params...)
+	if err != nil {
+		return nil, err
+	}
+	return relabel(b, []string{"Name", "Column", "Position", "Unique", "Primary"}, map[int]func(any) any{
+		2: metaInt,
+		3: metaBool,
+		4: metaBool,
+	}), nil
+}
+
+// splitTableName splits a schema qualified table name.
+func splitTableName(table string) (schema, name string) {
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// tableParams returns the table name parameter, followed by the schema if
+// the table name has one.
+func tableParams(table string) []any {
+	schema, name := splitTableName(table)
+	if len(schema) > 0 {
+		return []any{name, schema}
+	}
+	return []any{name}
+}
+
+// schemaFilter returns the table_schema condition for the schema of table,
+// which is the second parameter if present.
+func schemaFilter(d Dialect, table string) string {
+	if schema, _ := splitTableName(table); len(schema) > 0 {
+		return "table_schema = " + d.Placeholder(2)
+	}
+	switch d {
+	case DialectPostgres:
+		return "table_schema = current_schema()"
+	case DialectMySQL:
+		return "table_schema = database()"
+	case DialectSQLServer:
+		return "table_schema = schema_name()"
+	}
+	return "1 = 1"
+}
+
+// relabel sets the column names of a metadata buffer, which are also set for
+// an empty result, and converts the fields of the given column indexes.
+func relabel(b *Buffer, names []string, conv map[int]func(any) any) *Buffer {
+	b.Columns = names
+	b.columnTypes = nil
+	b.columnNameIndex = nil
+	b.buildIndex()
+	for i := range b.Rows {
+		row := &b.Rows[i]
+		row.columnNameIndex = b.columnNameIndex
+		for ci, fn := range conv {
+			if ci < len(row.Field) {
+				row.Field[ci] = fn(row.Field[ci])
+			}
+		}
+	}
+	return b
+}
+
+// metaInt converts integer metadata, which some drivers return as text.
+func metaInt(v any) any {
+	if n, ok := asInt64(v); ok {
+		return n
+	}
+	if s, ok := asString(v); ok {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// metaBool converts boolean metadata, such as "YES" and "NO" or 1 and 0.
+func metaBool(v any) any {
+	if n, ok := asInt64(v); ok {
+		return n != 0
+	}
+	if s, ok := asString(v); ok {
+		switch strings.ToUpper(s) {
+		case "YES", "Y", "TRUE", "T", "1":
+			return true
+		case "NO", "N", "FALSE", "F", "0":
+			return false
+		}
+	}
+	return v
+}
@@ -0,0 +1,98 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	ctx := context.Background()
+	list := []struct {
+		Name   string
+		Result fakeResult
+		Run    func(q Queryer) (*Buffer, error)
+		Query  string // Part of the query expected.
+		Params string
+		Want   string
+	}{
+		{
+			Name:   "tables-empty",
+			Result: fakeResult{Columns: []string{"table_schema", "table_name", "table_type"}},
+			Run:    func(q Queryer) (*Buffer, error) { return Tables(ctx, q, DialectPostgres) },
+			Query:  "from information_schema.tables",
+			Params: "[]",
+			Want:   "[Schema Name Type] []",
+		},
+		{
+			Name: "columns-mysql",
+			Result: fakeResult{
+				Columns: []string{"COLUMN_NAME", "ORDINAL_POSITION", "DATA_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT"},
+				Rows:    [][]driver.Value{{"id", []byte("1"), "int", "NO", nil}, {"name", int64(2), "varchar", "YES", "''"}},
+			},
+			Run:    func(q Queryer) (*Buffer, error) { return Columns(ctx, q, DialectMySQL, "orders") },
+			Query:  "where table_name = ? and table_schema = database()",
+			Params: "[orders]",
+			Want:   "[Name Position DataType Nullable Default] [[id 1 int false <nil>] [name 2 varchar true '']]",
+		},
+		{
+			Name: "columns-sqlite",
+			Result: fakeResult{
+				Columns: []string{"name", "cid + 1", "type", `"notnull" = 0`, "dflt_value"},
+				Rows:    [][]driver.Value{{"id", int64(1), "INTEGER", int64(0), nil}},
+			},
+			Run:    func(q Queryer) (*Buffer, error) { return Columns(ctx, q, DialectSQLite, "aux.orders") },
+			Query:  "from pragma_table_info(?, ?)",
+			Params: "[orders aux]",
+			Want:   "[Name Position DataType Nullable Default] [[id 1 INTEGER false <nil>]]",
+		},
+		{
+			Name: "indexes-postgres",
+			Result: fakeResult{
+				Columns: []string{"relname", "attname", "n", "indisunique", "indisprimary"},
+				Rows:    [][]driver.Value{{"orders_pkey", "id", int64(1), true, true}},
+			},
+			Run:    func(q Queryer) (*Buffer, error) { return Indexes(ctx, q, DialectPostgres, "sales.orders") },
+			Query:  "where t.relname = $1 and ns.nspname = $2",
+			Params: "[orders sales]",
+			Want:   "[Name Column Position Unique Primary] [[orders_pkey id 1 true true]]",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var query, params string
+			db := openFake(t, func(q string, args []driver.NamedValue) ([]fakeResult, error) {
+				values := make([]any, len(args))
+				for i, a := range args {
+					values[i] = a.Value
+				}
+				query, params = q, fmt.Sprint(values)
+				return []fakeResult{item.Result}, nil
+			})
+			b, err := item.Run(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(query, item.Query) {
+				t.Fatalf("query %q does not contain %q", query, item.Query)
+			}
+			if g, w := params, item.Params; g != w {
+				t.Fatalf("params got %s want %s", g, w)
+			}
+			rows := make([][]any, len(b.Rows))
+			for i, r := range b.Rows {
+				rows[i] = r.Field
+			}
+			if g, w := fmt.Sprint(b.Columns, " ", rows), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+			if len(b.Rows) > 0 {
+				if _, err := b.Rows[0].GetBool(b.Columns[3]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package table
+
+import (
+	"time"
+)
+
+// Metrics receives measurements from NewSet and FillSetOpt when set with the
+// WithMetrics option. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveQuery records the duration of a query and its fill, with any
+	// error. For FillSetOpt only the fill is timed.
+	ObserveQuery(d time.Duration, err error)
+	// AddRows adds the number of rows fetched.
+	AddRows(n int)
+	// SetBufferBytes records the estimated size of the filled set, as
+	// reported by SizeBytes.
+	SetBufferBytes(n int64)
+}
+
+// WithMetrics reports measurements of the query and fill to m.
+func WithMetrics(m Metrics) Option {
+	return func(c *fillConfig) {
+		c.metrics = m
+	}
+}
+
+// observe reports the fill of set to the metrics, if set.
+func (c *fillConfig) observe(start time.Time, set Set, err error) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveQuery(time.Since(start), err)
+	var n int
+	for _, b := range set {
+		n += b.Len()
+	}
+	if n > 0 {
+		c.metrics.AddRows(n)
+	}
+	if set != nil {
+		c.metrics.SetBufferBytes(set.SizeBytes())
+	}
+}
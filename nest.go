@@ -0,0 +1,196 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Nest copies parents into a slice of P and appends each child row to the
+// slice field of the parent with the matching key, such as orders with their
+// order lines read from two queries or result sets.
+//
+// The named field of P must be a slice of structs and be ignored for column
+// matching with the `sql:"-"` tag. Children are added to the first parent
+// where the parentKey column equals the childKey column. Children with a NULL
+// key or no matching parent are ignored.
+func Nest[P any](parents, children *Buffer, parentKey, childKey, field string, opts ...Option) ([]P, error) {
+	for _, b := range []*Buffer{parents, children} {
+		if err := b.inMemory(); err != nil {
+			return nil, err
+		}
+	}
+	var list []P
+	c := newFillConfig(opts)
+	if err := bufferToSlice(parents, reflect.ValueOf(&list).Elem(), c); err != nil {
+		return nil, err
+	}
+	sf, err := childField(reflect.TypeOf(list).Elem(), field)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := parents.lookupColumn(parentKey)
+	if err != nil {
+		return nil, err
+	}
+	ck, err := children.lookupColumn(childKey)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[any]int, len(parents.Rows))
+	for i := len(parents.Rows) - 1; i >= 0; i-- {
+		if k := nestKey(parents.Rows[i].Field[pk]); k != nil {
+			index[k] = i
+		}
+	}
+	owner := make([]int, len(children.Rows))
+	for i, row := range children.Rows {
+		owner[i] = -1
+		if k := nestKey(row.Field[ck]); k != nil {
+			if p, ok := index[k]; ok {
+				owner[i] = p
+			}
+		}
+	}
+	if err := attachChildren(reflect.ValueOf(list), sf, children, owner, c); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// BufferToNested collapses a flat joined result, where the parent columns are
+// repeated for each child row, into a slice of P with the child rows in the
+// named slice of structs field.
+//
+// Columns starting with childPrefix are mapped to the child struct and columns
+// starting with parentPrefix to P, each with the prefix removed. Rows with the
+// same key column value share a parent, which is taken from the first such row.
+// Rows with a NULL key are ignored, and rows where every child column is NULL,
+// as from an outer join, add no child. The field must be ignored for column
+// matching with the `sql:"-"` tag.
+func BufferToNested[P any](buf *Buffer, key, parentPrefix, childPrefix, field string, opts ...Option) ([]P, error) {
+	if len(childPrefix) == 0 || strings.HasPrefix(parentPrefix, childPrefix) {
+		return nil, fmt.Errorf("child prefix %q must be set and differ from parent prefix %q", childPrefix, parentPrefix)
+	}
+	sf, err := childField(reflect.TypeOf((*P)(nil)).Elem(), field)
+	if err != nil {
+		return nil, err
+	}
+	kc, err := buf.lookupColumn(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := buf.inMemory(); err != nil {
+		return nil, err
+	}
+
+	// Split the columns between the parent and child.
+	parents := &Buffer{Columns: []string{}}
+	children := &Buffer{Columns: []string{}}
+	var pcols, ccols []int
+	for i, n := range buf.Columns {
+		switch {
+		case strings.HasPrefix(n, childPrefix):
+			ccols = append(ccols, i)
+			children.Columns = append(children.Columns, n[len(childPrefix):])
+		case strings.HasPrefix(n, parentPrefix):
+			pcols = append(pcols, i)
+			parents.Columns = append(parents.Columns, n[len(parentPrefix):])
+		}
+	}
+
+	index := make(map[any]int)
+	var owner []int
+	for _, row := range buf.Rows {
+		k := nestKey(row.Field[kc])
+		if k == nil {
+			continue
+		}
+		p, ok := index[k]
+		if !ok {
+			p = len(parents.Rows)
+			index[k] = p
+			parents.AddRow(pick(row.Field, pcols))
+		}
+		if allNull(row.Field, ccols) {
+			continue
+		}
+		children.AddRow(pick(row.Field, ccols))
+		owner = append(owner, p)
+	}
+
+	var list []P
+	c := newFillConfig(opts)
+	if err := bufferToSlice(parents, reflect.ValueOf(&list).Elem(), c); err != nil {
+		return nil, err
+	}
+	if err := attachChildren(reflect.ValueOf(list), sf, children, owner, c); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// attachChildren appends each child row to the field of the parent in
+// lv given by owner, skipping children with an owner of -1.
+func attachChildren(lv reflect.Value, sf reflect.StructField, children *Buffer, owner []int, c *fillConfig) error {
+	childList := reflect.New(sf.Type).Elem()
+	if err := bufferToSlice(children, childList, c); err != nil {
+		return fmt.Errorf("children: %w", err)
+	}
+	for i, p := range owner {
+		if p < 0 {
+			continue
+		}
+		f := lv.Index(p).Field(sf.Index[0])
+		f.Set(reflect.Append(f, childList.Index(i)))
+	}
+	return nil
+}
+
+// pick returns the fields at the given indexes.
+func pick(field []any, index []int) []any {
+	out := make([]any, len(index))
+	for i, x := range index {
+		out[i] = field[x]
+	}
+	return out
+}
+
+// allNull reports if every field at the given indexes is NULL.
+func allNull(field []any, index []int) bool {
+	for _, x := range index {
+		if field[x] != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// childField returns the named slice of structs field in tp.
+func childField(tp reflect.Type, field string) (reflect.StructField, error) {
+	sf, ok := tp.FieldByName(field)
+	if !ok || len(sf.Index) != 1 {
+		return sf, fmt.Errorf("struct %s has no field %q", tp, field)
+	}
+	if sf.Type.Kind() != reflect.Slice || sf.Type.Elem().Kind() != reflect.Struct {
+		return sf, fmt.Errorf("field %q is %s, expected a slice of structs", field, sf.Type)
+	}
+	return sf, nil
+}
+
+// nestKey returns a comparable map key for a key column value.
+// []byte and other values that are not comparable are converted to a string,
+// and nil stays nil.
+func nestKey(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return string(v)
+	}
+	if !reflect.TypeOf(v).Comparable() {
+		return fmt.Sprint(v)
+	}
+	return v
+}
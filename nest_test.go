@@ -0,0 +1,125 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNest(t *testing.T) {
+	type Line struct {
+		OrderID int64
+		Item    string
+	}
+	type Order struct {
+		ID    int64
+		Lines []Line `sql:"-"`
+	}
+	parents := &Buffer{Columns: []string{"ID"}}
+	parents.AddRow([]any{int64(1)})
+	parents.AddRow([]any{int64(2)})
+	parents.AddRow([]any{int64(3)})
+	children := &Buffer{Columns: []string{"OrderID", "Item"}}
+	children.AddRow([]any{int64(1), "A"})
+	children.AddRow([]any{int64(3), "B"})
+	children.AddRow([]any{int64(1), "C"})
+	children.AddRow([]any{int64(9), "orphan"})
+	children.AddRow([]any{nil, "null"})
+
+	list := []struct {
+		Name  string
+		Field string
+		Key   string
+		Want  string
+		Error string
+	}{
+		{
+			Name:  "ok",
+			Field: "Lines",
+			Key:   "OrderID",
+			Want:  `[]table.Order{table.Order{ID:1, Lines:[]table.Line{table.Line{OrderID:1, Item:"A"}, table.Line{OrderID:1, Item:"C"}}}, table.Order{ID:2, Lines:[]table.Line(nil)}, table.Order{ID:3, Lines:[]table.Line{table.Line{OrderID:3, Item:"B"}}}}`,
+		},
+		{
+			Name:  "missing-field",
+			Field: "Items",
+			Key:   "OrderID",
+			Want:  `[]table.Order(nil)`,
+			Error: `struct table.Order has no field "Items"`,
+		},
+		{
+			Name:  "missing-key",
+			Field: "Lines",
+			Key:   "Order",
+			Want:  `[]table.Order(nil)`,
+			Error: `Table doesn't have column named "Order"`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			v, err := Nest[Order](parents, children, "ID", item.Key, item.Field, NullAsZero())
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if g, w := fmt.Sprintf("%#v", v), item.Want; g != w {
+				t.Fatalf("got:\n%s\n\nwant:%s\n", g, w)
+			}
+		})
+	}
+}
+
+func TestBufferToNested(t *testing.T) {
+	type Line struct {
+		Item string
+		Qty  int64
+	}
+	type Order struct {
+		ID    int64
+		Name  string
+		Lines []Line `sql:"-"`
+	}
+	buf := &Buffer{Columns: []string{"o_ID", "o_Name", "l_Item", "l_Qty"}}
+	buf.AddRow([]any{int64(1), "first", "A", int64(2)})
+	buf.AddRow([]any{int64(1), "first", "B", int64(1)})
+	buf.AddRow([]any{int64(2), "second", nil, nil})
+	buf.AddRow([]any{int64(3), "third", "C", int64(5)})
+	buf.AddRow([]any{nil, nil, "D", int64(1)})
+
+	list := []struct {
+		Name   string
+		Parent string
+		Child  string
+		Want   string
+		Error  string
+	}{
+		{
+			Name:   "ok",
+			Parent: "o_",
+			Child:  "l_",
+			Want:   `[]table.Order{table.Order{ID:1, Name:"first", Lines:[]table.Line{table.Line{Item:"A", Qty:2}, table.Line{Item:"B", Qty:1}}}, table.Order{ID:2, Name:"second", Lines:[]table.Line(nil)}, table.Order{ID:3, Name:"third", Lines:[]table.Line{table.Line{Item:"C", Qty:5}}}}`,
+		},
+		{
+			Name:   "no-child-prefix",
+			Parent: "o_",
+			Want:   `[]table.Order(nil)`,
+			Error:  `child prefix "" must be set and differ from parent prefix "o_"`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			v, err := BufferToNested[Order](buf, "o_ID", item.Parent, item.Child, "Lines")
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if g, w := fmt.Sprintf("%#v", v), item.Want; g != w {
+				t.Fatalf("got:\n%s\n\nwant:%s\n", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// StrictNumbers returns an error when a numeric value would overflow or lose
+// precision when mapped into a struct field of another numeric type, such
+// as an int64 of 300 into an int8 field or 1.5 into an int field.
+// Without it, numeric values are converted with Go conversion rules.
+func StrictNumbers() Option {
+	return func(c *fillConfig) {
+		c.strictNumbers = true
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || isUintKind(k) || isFloatKind(k)
+}
+
+// setNumber sets the numeric value v into the numeric rf. If strict, an error
+// is returned rather then overflowing or losing precision.
+func setNumber(rf reflect.Value, v reflect.Value, strict bool) error {
+	fk, vk := rf.Kind(), v.Kind()
+	lossy := func() error {
+		return fmt.Errorf("%s value %v overflows or loses precision in", v.Type(), v.Interface())
+	}
+	switch {
+	case isIntKind(fk):
+		var n int64
+		switch {
+		case isIntKind(vk):
+			n = v.Int()
+		case isUintKind(vk):
+			u := v.Uint()
+			if strict && u > math.MaxInt64 {
+				return lossy()
+			}
+			n = int64(u)
+		default:
+			f := v.Float()
+			if strict && (f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64) {
+				return lossy()
+			}
+			n = int64(f)
+		}
+		if strict && rf.OverflowInt(n) {
+			return lossy()
+		}
+		rf.SetInt(n)
+	case isUintKind(fk):
+		var u uint64
+		switch {
+		case isIntKind(vk):
+			n := v.Int()
+			if strict && n < 0 {
+				return lossy()
+			}
+			u = uint64(n)
+		case isUintKind(vk):
+			u = v.Uint()
+		default:
+			f := v.Float()
+			if strict && (f != math.Trunc(f) || f < 0 || f >= math.MaxUint64) {
+				return lossy()
+			}
+			u = uint64(f)
+		}
+		if strict && rf.OverflowUint(u) {
+			return lossy()
+		}
+		rf.SetUint(u)
+	default:
+		var f float64
+		switch {
+		case isIntKind(vk):
+			n := v.Int()
+			f = float64(n)
+			if strict && (f >= math.MaxInt64 || int64(f) != n) {
+				return lossy()
+			}
+		case isUintKind(vk):
+			u := v.Uint()
+			f = float64(u)
+			if strict && (f >= math.MaxUint64 || uint64(f) != u) {
+				return lossy()
+			}
+		default:
+			f = v.Float()
+		}
+		if strict && fk == reflect.Float32 {
+			if rf.OverflowFloat(f) || float64(float32(f)) != f {
+				return lossy()
+			}
+		}
+		rf.SetFloat(f)
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Option configures how query results are filled into buffers.
+type Option func(*fillConfig)
+
+type fillConfig struct {
+	maxRows  int
+	maxBytes int64
+
+	continueOnError bool
+	maxErrors       int
+	duplicates      DuplicatePolicy
+
+	capacity     int
+	parallel     int
+	shardColumn  string
+	converters   []Converter
+	types        []*TypeRegistry
+	columnFilter func(name string) bool
+	nullValue    any
+	nullSet      bool
+
+	progress      func(rowsScanned int, resultSet int)
+	progressEvery int
+
+	intern *interner
+	arena  int
+
+	rawBytes bool
+	borrow   bool
+
+	spillBytes int64
+	spillDir   string
+
+	metrics Metrics
+
+	// Query logging settings.
+	sensitive      []int
+	sensitiveNames []string
+
+	// Generated statement settings.
+	dialect     Dialect
+	batchRows   int
+	placeholder func(n int) string
+
+	// Query cache settings.
+	cacheTags []string
+
+	// Struct mapping settings.
+	nullZero      bool
+	decodeJSON    bool
+	strictNumbers bool
+	structTags    []string
+	tagFallback   bool
+	timeLayouts   []string
+	timeLoc       *time.Location
+	boolWords     map[string]bool
+}
+
+func newFillConfig(opts []Option) *fillConfig {
+	c := &fillConfig{
+		capacity:      10,
+		progressEvery: 1000,
+		parallel:      4,
+		batchRows:     100,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// MaxRows stops filling once n rows have been read across all result sets.
+// If more rows remain, a *TruncatedError is returned along with the partial set.
+func MaxRows(n int) Option {
+	return func(c *fillConfig) {
+		c.maxRows = n
+	}
+}
+
+// MaxBytes stops filling before the estimated size of the buffered rows,
+// as reported by SizeBytes, exceeds n bytes. The row that would exceed the
+// limit is not kept. A *TruncatedError is returned along with the partial set.
+func MaxBytes(n int64) Option {
+	return func(c *fillConfig) {
+		c.maxBytes = n
+	}
+}
+
+// ContinueOnError skips rows that fail to scan or convert and keeps filling,
+// recording each failure with its row number. The fill stops once more than
+// max rows have failed; a max of zero or less has no limit. The failures are
+// returned as a *RowErrors along with the set.
+//
+// Row numbers count every row read from the result set, including skipped rows.
+func ContinueOnError(max int) Option {
+	return func(c *fillConfig) {
+		c.continueOnError = true
+		c.maxErrors = max
+	}
+}
+
+// Capacity sets the initial row capacity of each buffer.
+// Set it when the approximate result size is known to avoid re-allocations.
+func Capacity(rows int) Option {
+	return func(c *fillConfig) {
+		if rows >= 0 {
+			c.capacity = rows
+		}
+	}
+}
+
+// Column describes a result column to a Converter.
+type Column struct {
+	Name             string
+	Index            int    // Index of the column in the buffer.
+	DatabaseTypeName string // As reported by sql.ColumnType.DatabaseTypeName.
+
+	typeConv Converter // Registered converter for the database type.
+}
+
+// Converter changes a scanned field value before it is stored in the buffer.
+// Return the value unchanged if the converter does not apply to it.
+type Converter func(col Column, v any) (any, error)
+
+// WithConverter adds a converter that is applied to every field as it is filled.
+// Multiple converters are applied in the order they are given.
+func WithConverter(fn Converter) Option {
+	return func(c *fillConfig) {
+		c.converters = append(c.converters, fn)
+	}
+}
+
+// ColumnFilter only keeps the result columns where keep returns true.
+// Filtered columns are still read from the database, but are not stored.
+func ColumnFilter(keep func(name string) bool) Option {
+	return func(c *fillConfig) {
+		c.columnFilter = keep
+	}
+}
+
+// IncludeColumns only keeps the named result columns.
+func IncludeColumns(names ...string) Option {
+	include := make(map[string]bool, len(names))
+	for _, n := range names {
+		include[n] = true
+	}
+	return ColumnFilter(func(name string) bool {
+		return include[name]
+	})
+}
+
+// ExcludeColumns removes the named result columns.
+func ExcludeColumns(names ...string) Option {
+	exclude := make(map[string]bool, len(names))
+	for _, n := range names {
+		exclude[n] = true
+	}
+	return ColumnFilter(func(name string) bool {
+		return !exclude[name]
+	})
+}
+
+// NullAs stores v in place of NULL values. Converters are applied first.
+func NullAs(v any) Option {
+	return func(c *fillConfig) {
+		c.nullValue = v
+		c.nullSet = true
+	}
+}
+
+// WithProgress calls fn every ProgressEvery rows while filling, and once more
+// when the fill ends. rowsScanned is the total across all result sets and
+// resultSet is the zero based index of the current result set.
+func WithProgress(fn func(rowsScanned int, resultSet int)) Option {
+	return func(c *fillConfig) {
+		c.progress = fn
+	}
+}
+
+// ProgressEvery sets how many rows are read between progress calls.
+// Defaults to 1000.
+func ProgressEvery(n int) Option {
+	return func(c *fillConfig) {
+		if n > 0 {
+			c.progressEvery = n
+		}
+	}
+}
+
+// Arena stores the fields of rowsPerSlab rows in a single shared allocation
+// rather then allocating the fields of each row separately. This reduces
+// allocations and GC work for large results. The memory of a slab is only
+// released once every row in it is unreachable.
+func Arena(rowsPerSlab int) Option {
+	return func(c *fillConfig) {
+		c.arena = rowsPerSlab
+	}
+}
+
+// RawBytes scans every column into sql.RawBytes and copies the bytes of each
+// row into a single allocation. Every non-NULL value is stored as a []byte in
+// the driver's text form, so numbers and times are no longer typed values.
+func RawBytes() Option {
+	return func(c *fillConfig) {
+		c.rawBytes = true
+	}
+}
+
+// Borrow is like RawBytes, but does not copy the bytes when the rows are not
+// retained, as with ForEachRow and Cursor. The []byte values reference driver
+// memory and are only valid until the next row is read.
+// When filling a buffer, Borrow behaves like RawBytes.
+func Borrow() Option {
+	return func(c *fillConfig) {
+		c.rawBytes = true
+		c.borrow = true
+	}
+}
+
+// NullAsZero sets struct fields to their zero value for NULL values,
+// rather then returning an error. Pointer fields and fields that implement
+// sql.Scanner handle NULL values themselves and are not affected.
+func NullAsZero() Option {
+	return func(c *fillConfig) {
+		c.nullZero = true
+	}
+}
+
+// DecodeJSON decodes string and []byte values as JSON when mapping them into
+// struct, map, or slice struct fields, such as from JSON or JSONB columns.
+func DecodeJSON() Option {
+	return func(c *fillConfig) {
+		c.decodeJSON = true
+	}
+}
+
+// StructTag maps struct fields to columns by the named field tag, such as
+// "db" for structs tagged for sqlx, rather then by the "sql" tag. Fields
+// without the tag are matched by field name and "-" skips a field as usual.
+func StructTag(name string) Option {
+	return func(c *fillConfig) {
+		c.structTags = []string{name}
+	}
+}
+
+// TagFallback maps struct fields without a "sql" tag, or the tag given to
+// StructTag, by their "db" tag, then by their "json" tag, before falling back
+// to the field name. This reuses the tags of structs already tagged for sqlx
+// or encoding/json. Options in the fallback tags, such as omitempty, are
+// ignored, and a field tagged "-" by the first tag found is skipped.
+func TagFallback() Option {
+	return func(c *fillConfig) {
+		c.tagFallback = true
+	}
+}
+
+// ErrTruncated is matched by errors.Is when a fill limit is reached.
+var ErrTruncated = errors.New("result truncated")
+
+// TruncatedError is returned when a fill limit is reached before the end of
+// the result. The set returned with it holds the rows read up to the limit.
+type TruncatedError struct {
+	Rows  int   // Rows kept across all result sets.
+	Bytes int64 // Estimated size of the rows kept.
+
+	MaxRows  int   // Row limit, zero if not set.
+	MaxBytes int64 // Byte limit, zero if not set.
+
+	byBytes bool // Byte limit was reached rather then the row limit.
+}
+
+func (te *TruncatedError) Error() string {
+	if te.byBytes {
+		return fmt.Sprintf("result truncated at %d rows, exceeded %d bytes", te.Rows, te.MaxBytes)
+	}
+	return fmt.Sprintf("result truncated at %d rows", te.Rows)
+}
+
+func (te *TruncatedError) Is(target error) bool {
+	return target == ErrTruncated
+}
+
+func (c *fillConfig) truncated(rows int, bytes int64, byBytes bool) error {
+	return &TruncatedError{
+		Rows:     rows,
+		Bytes:    bytes,
+		MaxRows:  c.maxRows,
+		MaxBytes: c.maxBytes,
+		byBytes:  byBytes,
+	}
+}
+
+// RowErrors is returned when ContinueOnError skipped rows.
+type RowErrors struct {
+	Errors []error // Error for each skipped row, in the order read.
+	Limit  bool    // Too many rows failed and the fill stopped early.
+}
+
+func (re *RowErrors) Error() string {
+	if re.Limit {
+		return fmt.Sprintf("fill stopped after %d row errors, first: %v", len(re.Errors), re.Errors[0])
+	}
+	return fmt.Sprintf("skipped %d rows, first: %v", len(re.Errors), re.Errors[0])
+}
+
+func (re *RowErrors) Unwrap() []error {
+	return re.Errors
+}
+
+// ErrIncomplete is matched by errors.Is when reading the result failed part
+// way through, such as from a dropped connection. The underlying driver error
+// is also wrapped. Unlike ErrTruncated, the set returned with it is missing
+// rows that were never read.
+var ErrIncomplete = errors.New("result incomplete")
+
+func incomplete(rows int, err error) error {
+	return fmt.Errorf("%w after %d rows: %w", ErrIncomplete, rows, err)
+}
+
+func (c *fillConfig) newBuffer() *Buffer {
+	return &Buffer{
+		Rows: make([]Row, 0, c.capacity),
+	}
+}
+
+// convert applies the type and other converters, NULL handling, and interning
+// to a scanned row. Conversion errors are returned as a *FieldError.
+func (c *fillConfig) convert(cols []Column, field []any) error {
+	for i, col := range cols {
+		if col.typeConv == nil {
+			continue
+		}
+		v, err := col.typeConv(col, field[i])
+		if err != nil {
+			return &FieldError{Column: col.Name, Value: field[i], Err: err}
+		}
+		field[i] = v
+	}
+	for _, conv := range c.converters {
+		for i, f := range field {
+			v, err := conv(cols[i], f)
+			if err != nil {
+				return &FieldError{Column: cols[i].Name, Value: f, Err: err}
+			}
+			field[i] = v
+		}
+	}
+	if c.nullSet {
+		for i, f := range field {
+			if f == nil {
+				field[i] = c.nullValue
+			}
+		}
+	}
+	if c.intern != nil {
+		c.intern.field(field)
+	}
+	return nil
+}
+
+// splitOptions removes any Option values from query parameters.
+func splitOptions(params []any) ([]any, []Option) {
+	var opts []Option
+	for _, p := range params {
+		if o, ok := p.(Option); ok {
+			opts = append(opts, o)
+		}
+	}
+	if len(opts) == 0 {
+		return params, nil
+	}
+	out := make([]any, 0, len(params)-len(opts))
+	for _, p := range params {
+		if _, ok := p.(Option); !ok {
+			out = append(out, p)
+		}
+	}
+	return out, opts
+}
@@ -0,0 +1,341 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestFillLimits(t *testing.T) {
+	results := []fakeResult{
+		{
+			Columns: []string{"ID", "Name"},
+			Rows: [][]driver.Value{
+				{int64(1), "R1"},
+				{int64(2), "R2"},
+			},
+		},
+		{
+			Columns: []string{"ID"},
+			Rows: [][]driver.Value{
+				{int64(3)},
+				{int64(4)},
+			},
+		},
+	}
+	list := []struct {
+		Name      string
+		Opts      []Option
+		Truncated bool
+		Counts    []int
+	}{
+		{Name: "none", Counts: []int{2, 2}},
+		{Name: "rows-exact", Opts: []Option{MaxRows(4)}, Counts: []int{2, 2}},
+		{Name: "rows", Opts: []Option{MaxRows(3)}, Truncated: true, Counts: []int{2, 1}},
+		{Name: "rows-first", Opts: []Option{MaxRows(1)}, Truncated: true, Counts: []int{1}},
+		{Name: "bytes", Opts: []Option{MaxBytes(1)}, Truncated: true, Counts: []int{0}},
+		{Name: "bytes-large", Opts: []Option{MaxBytes(1 << 20)}, Counts: []int{2, 2}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			set, err := FillSetOpt(context.Background(), fakeRows(t, results...), item.Opts...)
+			var te *TruncatedError
+			if g, w := errors.As(err, &te), item.Truncated; g != w {
+				t.Fatalf("truncated got %t want %t: %v", g, w, err)
+			}
+			if item.Truncated && !errors.Is(err, ErrTruncated) {
+				t.Fatalf("expected ErrTruncated, got %v", err)
+			}
+			if !item.Truncated && err != nil {
+				t.Fatal(err)
+			}
+			if g, w := len(set), len(item.Counts); g != w {
+				t.Fatalf("got %d buffers want %d", g, w)
+			}
+			for i, w := range item.Counts {
+				if g := len(set[i].Rows); g != w {
+					t.Fatalf("buffer %d got %d rows want %d", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestFillOptions(t *testing.T) {
+	var gotArgs []driver.NamedValue
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		gotArgs = args
+		return []fakeResult{{
+			Columns: []string{"ID", "Name", "Secret"},
+			Types:   []string{"INT", "TEXT", "TEXT"},
+			Rows: [][]driver.Value{
+				{int64(1), "R1", "x"},
+				{int64(2), nil, "y"},
+			},
+		}}, nil
+	})
+	upper := func(col Column, v any) (any, error) {
+		if s, ok := v.(string); ok && col.DatabaseTypeName == "TEXT" {
+			return s + "!", nil
+		}
+		return v, nil
+	}
+	buf, err := NewBuffer(context.Background(), db, "select", int64(5), ExcludeColumns("Secret"), WithConverter(upper), NullAs("none"), Capacity(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotArgs) != 1 || gotArgs[0].Value != int64(5) {
+		t.Fatalf("options were sent as query parameters: %v", gotArgs)
+	}
+	if g, w := fmt.Sprint(buf.Columns), "[ID Name]"; g != w {
+		t.Fatalf("columns got %s want %s", g, w)
+	}
+	if g, w := fmt.Sprint(buf.Rows[0].Field, buf.Rows[1].Field), "[1 R1!] [2 none]"; g != w {
+		t.Fatalf("rows got %s want %s", g, w)
+	}
+}
+
+func TestFillCanceled(t *testing.T) {
+	res := fakeResult{Columns: []string{"ID"}}
+	for i := 0; i < ctxCheckInterval*3; i++ {
+		res.Rows = append(res.Rows, []driver.Value{int64(i)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := fakeRows(t, res, res)
+	cancel()
+	set, err := FillSet(ctx, rows)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(set) != 1 || len(set[0].Rows) != 0 {
+		t.Fatalf("expected an empty partial set, got %d buffers", len(set))
+	}
+}
+
+func TestFillRowsErr(t *testing.T) {
+	errDrop := errors.New("connection dropped")
+	rows := fakeRows(t, fakeResult{
+		Columns: []string{"ID"},
+		Rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+		Err:     errDrop,
+	})
+	set, err := FillSet(context.Background(), rows)
+	if !errors.Is(err, errDrop) || !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("expected wrapped driver error, got %v", err)
+	}
+	if errors.Is(err, ErrTruncated) {
+		t.Fatal("incomplete result reported as truncated")
+	}
+	if len(set) != 1 || len(set[0].Rows) != 2 {
+		t.Fatal("expected the partial set")
+	}
+}
+
+func TestFillFieldError(t *testing.T) {
+	errBad := errors.New("bad value")
+	rows := fakeRows(t, fakeResult{
+		Columns: []string{"ID", "Price"},
+		Rows:    [][]driver.Value{{int64(1), "1.5"}, {int64(2), "x"}},
+	})
+	conv := func(col Column, v any) (any, error) {
+		if col.Name == "Price" && v == "x" {
+			return nil, errBad
+		}
+		return v, nil
+	}
+	_, err := FillSetOpt(context.Background(), rows, WithConverter(conv))
+	var fe *FieldError
+	if !errors.As(err, &fe) || !errors.Is(err, errBad) {
+		t.Fatalf("expected a FieldError, got %v", err)
+	}
+	if fe.Row != 1 || fe.Column != "Price" || fe.Value != "x" {
+		t.Fatalf("unexpected field error %+v", *fe)
+	}
+}
+
+func TestFillContinueOnError(t *testing.T) {
+	errBad := errors.New("bad value")
+	conv := func(col Column, v any) (any, error) {
+		if v == int64(-1) {
+			return nil, errBad
+		}
+		return v, nil
+	}
+	res := fakeResult{
+		Columns: []string{"ID"},
+		Rows:    [][]driver.Value{{int64(1)}, {int64(-1)}, {int64(3)}, {int64(-1)}, {int64(5)}},
+	}
+
+	list := []struct {
+		Name   string
+		Max    int
+		Rows   string
+		Errors int
+		Limit  bool
+	}{
+		{Name: "no-limit", Max: 0, Rows: "[[1] [3] [5]]", Errors: 2},
+		{Name: "within-limit", Max: 2, Rows: "[[1] [3] [5]]", Errors: 2},
+		{Name: "over-limit", Max: 1, Rows: "[[1] [3]]", Errors: 2, Limit: true},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			rows := fakeRows(t, res)
+			set, err := FillSetOpt(context.Background(), rows, WithConverter(conv), ContinueOnError(item.Max))
+			var re *RowErrors
+			if !errors.As(err, &re) || !errors.Is(err, errBad) {
+				t.Fatalf("expected RowErrors, got %v", err)
+			}
+			if g, w := len(re.Errors), item.Errors; g != w {
+				t.Fatalf("errors got %d want %d", g, w)
+			}
+			if g, w := re.Limit, item.Limit; g != w {
+				t.Fatalf("limit got %t want %t", g, w)
+			}
+			var fe *FieldError
+			if !errors.As(re.Errors[1], &fe) || fe.Row != 3 {
+				t.Fatalf("expected an error for row 3, got %v", re.Errors[1])
+			}
+			var got []any
+			for _, row := range set[0].Rows {
+				got = append(got, row.Field)
+			}
+			if g, w := fmt.Sprint(got), item.Rows; g != w {
+				t.Fatalf("rows got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestFillDuplicateColumns(t *testing.T) {
+	res := fakeResult{
+		Columns: []string{"id", "id", "id_2", "id"},
+		Rows:    [][]driver.Value{{int64(1), int64(2), int64(3), int64(4)}},
+	}
+	list := []struct {
+		Name    string
+		Policy  DuplicatePolicy
+		Columns string
+		Get     string
+		Error   string
+	}{
+		{Name: "last", Policy: DuplicateLast, Columns: "[id id id_2 id]", Get: "4"},
+		{Name: "error", Policy: DuplicateError, Error: `duplicate column "id" at index 0 and 1`},
+		{Name: "suffix", Policy: DuplicateSuffix, Columns: "[id id_3 id_2 id_4]", Get: "1"},
+		{Name: "positional", Policy: DuplicatePositional, Columns: "[id id id_2 id]", Get: "panic"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			rows := fakeRows(t, res)
+			set, err := FillSetOpt(context.Background(), rows, DuplicateColumns(item.Policy))
+			if len(item.Error) > 0 {
+				if err == nil || err.Error() != item.Error || !errors.Is(err, ErrDuplicateColumn) {
+					t.Fatalf("expected error %s, got %v", item.Error, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := set[0]
+			if g, w := fmt.Sprint(buf.Columns), item.Columns; g != w {
+				t.Fatalf("columns got %s want %s", g, w)
+			}
+			if p, ok := buf.DuplicatePolicy(); p != item.Policy || !ok {
+				t.Fatalf("policy got %v, %t", p, ok)
+			}
+			got := func() (s string) {
+				defer func() {
+					if recover() != nil {
+						s = "panic"
+					}
+				}()
+				return fmt.Sprint(buf.Get(0, "id"))
+			}()
+			if g, w := got, item.Get; g != w {
+				t.Fatalf("get got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+type recordMetrics struct {
+	queries int
+	errs    int
+	rows    int
+	bytes   int64
+}
+
+func (m *recordMetrics) ObserveQuery(d time.Duration, err error) {
+	m.queries++
+	if err != nil {
+		m.errs++
+	}
+}
+func (m *recordMetrics) AddRows(n int)          { m.rows += n }
+func (m *recordMetrics) SetBufferBytes(n int64) { m.bytes = n }
+
+func TestFillMetrics(t *testing.T) {
+	res := fakeResult{
+		Columns: []string{"ID"},
+		Rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+	}
+	m := &recordMetrics{}
+	db := openFake(t, fakeSet(res, res))
+	set, err := NewSet(context.Background(), db, "select", WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.queries != 1 || m.errs != 0 || m.rows != 4 || m.bytes != set.SizeBytes() {
+		t.Fatalf("unexpected metrics %+v", *m)
+	}
+
+	_, err = FillSetOpt(context.Background(), fakeRows(t, res), MaxRows(1), WithMetrics(m))
+	if err == nil {
+		t.Fatal("expected a truncated error")
+	}
+	if m.queries != 2 || m.errs != 1 || m.rows != 5 {
+		t.Fatalf("unexpected metrics %+v", *m)
+	}
+}
+
+func TestFillProgress(t *testing.T) {
+	res := fakeResult{Columns: []string{"ID"}}
+	for i := 0; i < 5; i++ {
+		res.Rows = append(res.Rows, []driver.Value{int64(i)})
+	}
+	var calls []string
+	progress := func(rowsScanned int, resultSet int) {
+		calls = append(calls, fmt.Sprintf("%d/%d", rowsScanned, resultSet))
+	}
+	_, err := FillSetOpt(context.Background(), fakeRows(t, res, res), WithProgress(progress), ProgressEvery(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(calls), "[4/0 8/1 10/1]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
+
+func TestFillIntern(t *testing.T) {
+	res := fakeResult{Columns: []string{"Status", "Code"}}
+	for i := 0; i < 3; i++ {
+		res.Rows = append(res.Rows, []driver.Value{"active", []byte("A")})
+	}
+	buf, err := NewBuffer(context.Background(), openFake(t, fakeSet(res)), "select", InternStrings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := buf.Rows[0]
+	for _, row := range buf.Rows[1:] {
+		if unsafe.StringData(row.Field[0].(string)) != unsafe.StringData(first.Field[0].(string)) {
+			t.Fatal("string not interned")
+		}
+		if &row.Field[1].([]byte)[0] != &first.Field[1].([]byte)[0] {
+			t.Fatal("bytes not interned")
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package tableotel traces queries run through a table.Queryer with
+// OpenTelemetry. Wrap the database with NewQueryer and pass it wherever the
+// table package takes a Queryer:
+//
+//	q := tableotel.NewQueryer(db, tableotel.WithSystem("postgresql"))
+//	set, err := table.NewSet(ctx, q, "select * from Account")
+package tableotel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/golang-sql/table"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/golang-sql/table/otel"
+
+// Option configures the Queryer.
+type Option func(*config)
+
+type config struct {
+	provider trace.TracerProvider
+	system   string
+	params   bool
+	redactor table.Redactor
+}
+
+// WithTracerProvider sets the provider of the tracer.
+// Defaults to the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = tp
+	}
+}
+
+// WithSystem sets the db.system attribute, such as "postgresql".
+func WithSystem(system string) Option {
+	return func(c *config) {
+		c.system = system
+	}
+}
+
+// WithParams records the query parameters as db.query.parameter.<n>
+// attributes, passed through table.RedactParams with r.
+// Parameters are not recorded by default.
+func WithParams(r table.Redactor) Option {
+	return func(c *config) {
+		c.params = true
+		c.redactor = r
+	}
+}
+
+// NewQueryer returns a Queryer that starts a span for each query.
+//
+// The span has the db.statement attribute with the SQL text. When the query
+// is run by the table package, such as by table.NewSet, the span ends once
+// the rows have been read and has the db.response.returned_rows and
+// table.result_sets attributes. Otherwise it ends when QueryContext returns.
+func NewQueryer(q table.Queryer, opts ...Option) table.Queryer {
+	c := &config{provider: otel.GetTracerProvider()}
+	for _, o := range opts {
+		o(c)
+	}
+	return &otelQueryer{
+		q:      q,
+		c:      c,
+		tracer: c.provider.Tracer(instrumentationName),
+	}
+}
+
+type otelQueryer struct {
+	q      table.Queryer
+	c      *config
+	tracer trace.Tracer
+}
+
+func (oq *otelQueryer) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	qi, ok := table.QueryInfoFrom(ctx)
+	attrs := []attribute.KeyValue{attribute.String("db.statement", text)}
+	if len(oq.c.system) > 0 {
+		attrs = append(attrs, attribute.String("db.system", oq.c.system))
+	}
+	if oq.c.params {
+		var logged []any
+		if ok {
+			logged = qi.RedactParams(oq.c.redactor, text, params)
+		} else {
+			logged = table.RedactParams(oq.c.redactor, text, params)
+		}
+		for i, p := range logged {
+			attrs = append(attrs, attribute.String(fmt.Sprintf("db.query.parameter.%d", i), fmt.Sprint(p)))
+		}
+	}
+	ctx, span := oq.tracer.Start(ctx, spanName(text),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	rows, err := oq.q.QueryContext(ctx, text, params...)
+	if err != nil || !ok {
+		end(span, err)
+		return rows, err
+	}
+	qi.OnDone(func(stats table.FillStats, err error) {
+		span.SetAttributes(
+			attribute.Int("db.response.returned_rows", stats.Rows),
+			attribute.Int("table.result_sets", stats.ResultSets),
+		)
+		end(span, err)
+	})
+	return rows, nil
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// spanName returns the first keyword of the SQL text, such as "SELECT".
+func spanName(text string) string {
+	f := strings.Fields(text)
+	if len(f) == 0 {
+		return "query"
+	}
+	return strings.ToUpper(f[0])
+}
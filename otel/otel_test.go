@@ -0,0 +1,91 @@
+package tableotel
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/golang-sql/table"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// rowsDriver returns the same two rows for every query.
+type rowsDriver struct{}
+
+func (rowsDriver) Open(name string) (driver.Conn, error) { return conn{}, nil }
+
+type conn struct{}
+
+func (conn) Prepare(query string) (driver.Stmt, error) { return stmt{}, nil }
+func (conn) Close() error                              { return nil }
+func (conn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type stmt struct{}
+
+func (stmt) Close() error                                    { return nil }
+func (stmt) NumInput() int                                   { return -1 }
+func (stmt) Exec(args []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (stmt) Query(args []driver.Value) (driver.Rows, error)  { return &rows{}, nil }
+
+type rows struct{ n int }
+
+func (r *rows) Columns() []string { return []string{"ID"} }
+func (r *rows) Close() error      { return nil }
+func (r *rows) Next(dest []driver.Value) error {
+	if r.n == 2 {
+		return io.EOF
+	}
+	r.n++
+	dest[0] = int64(r.n)
+	return nil
+}
+
+func init() {
+	sql.Register("tableotel", rowsDriver{})
+}
+
+func TestQueryer(t *testing.T) {
+	db, err := sql.Open("tableotel", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	q := NewQueryer(db, WithTracerProvider(tp), WithSystem("fake"), WithParams(nil))
+
+	set, err := table.NewSet(context.Background(), q, "select ID", "secret", table.SensitiveParams(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set[0].Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(set[0].Rows))
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if g, w := spans[0].Name(), "SELECT"; g != w {
+		t.Fatalf("span name got %s want %s", g, w)
+	}
+	got := map[string]string{}
+	for _, kv := range spans[0].Attributes() {
+		got[string(kv.Key)] = kv.Value.Emit()
+	}
+	for k, w := range map[string]string{
+		"db.statement":              "select ID",
+		"db.system":                 "fake",
+		"db.query.parameter.0":      "[REDACTED]",
+		"db.response.returned_rows": "2",
+		"table.result_sets":         "1",
+	} {
+		if g := got[k]; g != w {
+			t.Errorf("attribute %s got %q want %q", k, g, w)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strconv"
+)
+
+// OutParams holds the final values of output parameters, keyed by name.
+// Output parameters not passed with sql.Named are keyed by their position
+// in the query parameters, starting at "1".
+type OutParams map[string]any
+
+// NewSetOut is like NewSet, also returning the values of output parameters,
+// such as sql.Named("Total", sql.Out{Dest: &total}) for a SQL Server stored
+// procedure. Drivers set output values once every result set has been read,
+// so they are collected after the fill, and are also returned with an error.
+func NewSetOut(ctx context.Context, q Queryer, sql string, params ...any) (Set, OutParams, error) {
+	set, err := NewSet(ctx, q, sql, params...)
+	return set, outParams(params), err
+}
+
+// outParams returns the current values of the output parameters in params.
+func outParams(params []any) OutParams {
+	var out OutParams
+	pos := 0
+	for _, p := range params {
+		if _, ok := p.(Option); ok {
+			continue
+		}
+		pos++
+		name := strconv.Itoa(pos)
+		if na, ok := p.(sql.NamedArg); ok {
+			name = na.Name
+			p = na.Value
+		}
+		o, ok := p.(sql.Out)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(OutParams)
+		}
+		v := reflect.ValueOf(o.Dest)
+		if v.Kind() == reflect.Pointer && !v.IsNil() {
+			out[name] = v.Elem().Interface()
+		} else {
+			out[name] = nil
+		}
+	}
+	return out
+}
@@ -0,0 +1,251 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeysetPager reads the rows of a query one page at a time, using the key
+// columns of the last row of each page to select the next. Unlike paging with
+// OFFSET, rows are neither skipped nor repeated when rows before the current
+// page are inserted or deleted, and later pages are as fast as the first.
+//
+//	p := table.PaginateKeyset(ctx, db, "select ID, Name from Account", []string{"ID"}, 1000)
+//	for p.Next() {
+//		buf := p.Buffer()
+//		// ...
+//	}
+//	return p.Err()
+type KeysetPager struct {
+	ctx      context.Context
+	q        Queryer
+	base     string
+	keys     []string
+	pageSize int
+	params   []any
+	opts     []Option
+	c        *fillConfig
+
+	buf  *Buffer
+	last []any // Key values of the last row read.
+	done bool
+	err  error
+}
+
+// PaginateKeyset returns a KeysetPager over the rows of baseSQL, ordered by
+// the keyColumns ascending, with at most pageSize rows in each page.
+// The key columns must be in the result, must not be NULL, and must together
+// be unique. Any Option values in params configure the fill of each page;
+// use WithDialect to match the database.
+//
+// baseSQL is run as a sub-query, so it must not have its own ORDER BY or
+// limit clause. Key column names are written as given and are not quoted.
+func PaginateKeyset(ctx context.Context, q Queryer, baseSQL string, keyColumns []string, pageSize int, params ...any) *KeysetPager {
+	params, opts := splitOptions(params)
+	p := &KeysetPager{
+		ctx:      ctx,
+		q:        q,
+		base:     strings.TrimRight(strings.TrimSpace(baseSQL), ";"),
+		keys:     keyColumns,
+		pageSize: pageSize,
+		params:   params,
+		opts:     opts,
+		c:        newFillConfig(opts),
+	}
+	switch {
+	case len(keyColumns) == 0:
+		p.err = fmt.Errorf("missing key columns")
+	case pageSize <= 0:
+		p.err = fmt.Errorf("invalid page size %d", pageSize)
+	}
+	return p
+}
+
+// Next reads the next page, returning false when there are no more rows or
+// on error. Check Err after Next returns false.
+func (p *KeysetPager) Next() bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	text, params := p.pageSQL()
+	for _, o := range p.opts {
+		params = append(params, o)
+	}
+	buf, err := NewBuffer(p.ctx, p.q, text, params...)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	n := buf.Len()
+	if n < p.pageSize {
+		p.done = true
+	}
+	if n == 0 {
+		return false
+	}
+	last, err := buf.Row(n - 1)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	p.last = make([]any, len(p.keys))
+	for i, k := range p.keys {
+		v, err := last.lookup(k)
+		if err != nil {
+			p.err = fmt.Errorf("key column: %w", err)
+			return false
+		}
+		p.last[i] = v
+	}
+	p.buf = buf
+	return true
+}
+
+// Buffer returns the current page.
+func (p *KeysetPager) Buffer() *Buffer {
+	return p.buf
+}
+
+// Err returns the error, if any, that stopped the pager.
+func (p *KeysetPager) Err() error {
+	return p.err
+}
+
+// pageSQL returns the statement and parameters for the next page. After the
+// first page, rows must sort after the last key: for keys a and b,
+// "a > ? or (a = ? and b > ?)".
+func (p *KeysetPager) pageSQL() (string, []any) {
+	d := p.c.dialect
+	placeholder := p.c.placeholderFunc()
+	params := append([]any(nil), p.params...)
+
+	var sb strings.Builder
+	sb.WriteString("select ")
+	if d == DialectSQLServer {
+		sb.WriteString("top (" + strconv.Itoa(p.pageSize) + ") ")
+	}
+	sb.WriteString("* from (" + p.base + ") keyset_page")
+	if p.last != nil {
+		sb.WriteString(" where ")
+		for i := range p.keys {
+			if i > 0 {
+				sb.WriteString(" or ")
+			}
+			sb.WriteString("(")
+			for j := 0; j < i; j++ {
+				params = append(params, p.last[j])
+				sb.WriteString(p.keys[j] + " = " + placeholder(len(params)) + " and ")
+			}
+			params = append(params, p.last[i])
+			sb.WriteString(p.keys[i] + " > " + placeholder(len(params)) + ")")
+		}
+	}
+	sb.WriteString(" order by " + strings.Join(p.keys, ", "))
+	if d == DialectSQLServer {
+		return sb.String(), params
+	}
+	return d.limit(sb.String(), p.pageSize, 0), params
+}
+
+// Page is a page of a query result read by NewBufferPage.
+type Page struct {
+	Buffer   *Buffer
+	Page     int   // Page number, starting at 1.
+	PageSize int   // Maximum rows in a page.
+	Total    int64 // Rows in the whole result.
+}
+
+// Pages returns the number of pages in the whole result.
+func (p *Page) Pages() int {
+	return int((p.Total + int64(p.PageSize) - 1) / int64(p.PageSize))
+}
+
+// NewBufferPage returns page number page, starting at 1, of the query with
+// pageSize rows per page, along with the total rows of the query. The total
+// is read with a second query, "select count(*)" over the query as a
+// sub-query. Any Option values in params configure the fill; use WithDialect
+// to match the database.
+//
+// The query should end with an ORDER BY clause so that pages do not overlap,
+// and must for DialectSQLServer. It must not have its own limit clause.
+func NewBufferPage(ctx context.Context, q Queryer, sql string, page, pageSize int, params ...any) (*Page, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid page %d", page)
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	queryParams, opts := splitOptions(params)
+	c := newFillConfig(opts)
+	sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+
+	// Fill options may filter or convert the columns, so are not used for the count.
+	row, err := NewRow(ctx, q, "select count(*) from ("+trimOrderBy(sql)+") page_count", queryParams...)
+	if err != nil {
+		return nil, fmt.Errorf("page count: %w", err)
+	}
+	total, ok := asInt64(row.Field[0])
+	if !ok {
+		if s, isString := asString(row.Field[0]); isString {
+			total, err = strconv.ParseInt(s, 10, 64)
+			ok = err == nil
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("page count: cannot convert %T to int64", row.Field[0])
+	}
+
+	p := &Page{Page: page, PageSize: pageSize, Total: total}
+	p.Buffer, err = NewBuffer(ctx, q, c.dialect.limit(sql, pageSize, (page-1)*pageSize), params...)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// trimOrderBy removes a final ORDER BY clause from the query, which is not
+// allowed in a sub-query by some databases. Clauses inside parentheses, quotes,
+// or comments are ignored.
+func trimOrderBy(sql string) string {
+	lower := strings.ToLower(sql)
+	depth := 0
+	at := -1
+	for i := 0; i < len(sql); i++ {
+		switch ch := sql[i]; ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '\'', '"', '`', '[':
+			end := ch
+			if ch == '[' {
+				end = ']'
+			}
+			for i++; i < len(sql) && sql[i] != end; i++ {
+			}
+		case '-':
+			if strings.HasPrefix(sql[i:], "--") {
+				for i < len(sql) && sql[i] != '\n' {
+					i++
+				}
+			}
+		case 'o', 'O':
+			if depth == 0 && strings.HasPrefix(lower[i:], "order") && (i == 0 || isSpace(sql[i-1])) {
+				rest := strings.TrimLeft(lower[i+len("order"):], " \t\r\n")
+				if strings.HasPrefix(rest, "by") && len(rest) > 2 && isSpace(rest[2]) {
+					at = i
+				}
+			}
+		}
+	}
+	if at < 0 {
+		return sql
+	}
+	return strings.TrimSpace(sql[:at])
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
@@ -0,0 +1,164 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestPaginateKeyset(t *testing.T) {
+	ctx := context.Background()
+	list := []struct {
+		Name    string
+		Keys    []string
+		Params  []any
+		Pages   string
+		Queries []string
+	}{
+		{
+			Name:  "single",
+			Keys:  []string{"ID"},
+			Pages: "[[1 2] [3 4] [5]]",
+			Queries: []string{
+				"select * from (select ID from T) keyset_page order by ID limit 2 []",
+				"select * from (select ID from T) keyset_page where (ID > ?) order by ID limit 2 [2]",
+				"select * from (select ID from T) keyset_page where (ID > ?) order by ID limit 2 [4]",
+			},
+		},
+		{
+			Name:   "compound-postgres",
+			Keys:   []string{"G", "ID"},
+			Params: []any{"x", WithDialect(DialectPostgres)},
+			Pages:  "[[1 2] [3 4] [5]]",
+			Queries: []string{
+				"select * from (select ID from T) keyset_page order by G, ID limit 2 [x]",
+				"select * from (select ID from T) keyset_page where (G > $2) or (G = $3 and ID > $4) order by G, ID limit 2 [x 0 0 2]",
+				"select * from (select ID from T) keyset_page where (G > $2) or (G = $3 and ID > $4) order by G, ID limit 2 [x 0 0 4]",
+			},
+		},
+		{
+			Name:   "sqlserver",
+			Keys:   []string{"ID"},
+			Params: []any{WithDialect(DialectSQLServer)},
+			Pages:  "[[1 2] [3 4] [5]]",
+			Queries: []string{
+				"select top (2) * from (select ID from T) keyset_page order by ID []",
+				"select top (2) * from (select ID from T) keyset_page where (ID > @p1) order by ID [2]",
+				"select top (2) * from (select ID from T) keyset_page where (ID > @p1) order by ID [4]",
+			},
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var queries []string
+			db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+				values := make([]any, len(args))
+				for i, a := range args {
+					values[i] = a.Value
+				}
+				queries = append(queries, fmt.Sprint(query, " ", values))
+				var after int64
+				if len(values) > 0 {
+					after, _ = values[len(values)-1].(int64)
+				}
+				res := fakeResult{Columns: []string{"G", "ID"}}
+				for id := after + 1; id <= 5 && id <= after+2; id++ {
+					res.Rows = append(res.Rows, []driver.Value{int64(0), id})
+				}
+				return []fakeResult{res}, nil
+			})
+			p := PaginateKeyset(ctx, db, "select ID from T;", item.Keys, 2, item.Params...)
+			var pages [][]any
+			for p.Next() {
+				var page []any
+				for _, r := range p.Buffer().Rows {
+					page = append(page, r.Field[1])
+				}
+				pages = append(pages, page)
+			}
+			if err := p.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := fmt.Sprint(pages), item.Pages; g != w {
+				t.Fatalf("pages got %s want %s", g, w)
+			}
+			if g, w := fmt.Sprint(queries), fmt.Sprint(item.Queries); g != w {
+				t.Fatalf("queries got\n%s\nwant\n%s", g, w)
+			}
+		})
+	}
+}
+
+func TestNewBufferPage(t *testing.T) {
+	ctx := context.Background()
+	list := []struct {
+		Name    string
+		SQL     string
+		Page    int
+		Params  []any
+		Count   driver.Value
+		Pages   int
+		Queries []string
+		Error   string
+	}{
+		{
+			Name:   "first",
+			SQL:    "select ID from T where G = ? order by ID;",
+			Page:   1,
+			Params: []any{"x", NullAs("-")},
+			Count:  int64(5),
+			Pages:  3,
+			Queries: []string{
+				"select count(*) from (select ID from T where G = ?) page_count [x]",
+				"select ID from T where G = ? order by ID limit 2 [x]",
+			},
+		},
+		{
+			Name:   "sqlserver",
+			SQL:    "select ID, (select max(X) from U order by X) M from T order by ID",
+			Page:   3,
+			Params: []any{WithDialect(DialectSQLServer)},
+			Count:  []byte("4"),
+			Pages:  2,
+			Queries: []string{
+				"select count(*) from (select ID, (select max(X) from U order by X) M from T) page_count []",
+				"select ID, (select max(X) from U order by X) M from T order by ID offset 4 rows fetch next 2 rows only []",
+			},
+		},
+		{Name: "page", SQL: "select ID from T", Page: 0, Error: "invalid page 0"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var queries []string
+			db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+				values := make([]any, len(args))
+				for i, a := range args {
+					values[i] = a.Value
+				}
+				queries = append(queries, fmt.Sprint(query, " ", values))
+				if len(queries) == 1 {
+					return []fakeResult{{Columns: []string{""}, Rows: [][]driver.Value{{item.Count}}}}, nil
+				}
+				return []fakeResult{{Columns: []string{"ID"}, Rows: [][]driver.Value{{int64(1)}}}}, nil
+			})
+			p, err := NewBufferPage(ctx, db, item.SQL, item.Page, 2, item.Params...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := p.Pages(), item.Pages; g != w {
+				t.Fatalf("pages got %d want %d", g, w)
+			}
+			if g, w := fmt.Sprint(queries), fmt.Sprint(item.Queries); g != w {
+				t.Fatalf("queries got\n%s\nwant\n%s", g, w)
+			}
+		})
+	}
+}
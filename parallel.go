@@ -0,0 +1,176 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Query is a single query to run with NewSetParallel.
+type Query struct {
+	SQL    string
+	Params []any // May include Option values for this query.
+}
+
+// Parallel sets how many queries NewSetParallel runs at once. Defaults to 4.
+func Parallel(n int) Option {
+	return func(c *fillConfig) {
+		if n > 0 {
+			c.parallel = n
+		}
+	}
+}
+
+// NewSetParallel runs independent queries concurrently and returns the first
+// result set of each, in the order of queries. The opts apply to every query,
+// before any Option values in the query params.
+//
+// The first query to fail cancels the rest, and its error is returned with
+// the index of the query.
+func NewSetParallel(ctx context.Context, q Queryer, queries []Query, opts ...Option) (Set, error) {
+	set := make(Set, len(queries))
+	err := runParallel(ctx, len(queries), newFillConfig(opts).parallel, func(ctx context.Context, i int) error {
+		params := make([]any, 0, len(opts)+len(queries[i].Params))
+		for _, o := range opts {
+			params = append(params, o)
+		}
+		params = append(params, queries[i].Params...)
+
+		b, err := NewBuffer(ctx, q, queries[i].SQL, params...)
+		if err != nil {
+			return fmt.Errorf("query %d: %w", i, err)
+		}
+		set[i] = b
+		return nil
+	})
+	if err != nil {
+		set.close()
+		return nil, err
+	}
+	return set, nil
+}
+
+// ShardColumn adds a column with the given name to the buffer returned by
+// QueryShards, holding the int64 index of the shard each row was read from.
+func ShardColumn(name string) Option {
+	return func(c *fillConfig) {
+		c.shardColumn = name
+	}
+}
+
+// QueryShards runs the same query against each Queryer concurrently, such as
+// the shards or replicas of a database, and appends the first result set of
+// each into a single buffer in the order of qs. Every shard with rows must
+// return the same columns, or an error matching ErrSchemaMismatch is returned.
+// Any Option values in params apply to each shard, and Parallel limits how
+// many shards are queried at once.
+//
+// The first shard to fail cancels the rest, and its error is returned with
+// the index of the shard.
+func QueryShards(ctx context.Context, qs []Queryer, sql string, params ...any) (*Buffer, error) {
+	_, opts := splitOptions(params)
+	c := newFillConfig(opts)
+	parts := make([]*Buffer, len(qs))
+	err := runParallel(ctx, len(qs), c.parallel, func(ctx context.Context, i int) error {
+		b, err := NewBuffer(ctx, qs[i], sql, params...)
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+		parts[i] = b
+		return nil
+	})
+	defer Set(parts).close()
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, &IndexError{subject: SubjectTable}
+	}
+
+	// Empty results do not report their columns.
+	first, firstIndex := parts[0], 0
+	for i, b := range parts {
+		if len(b.Columns) > 0 {
+			first, firstIndex = b, i
+			break
+		}
+	}
+	out := &Buffer{
+		Columns:     append([]string(nil), first.Columns...),
+		columnTypes: first.columnTypes,
+	}
+	if len(c.shardColumn) > 0 {
+		out.Columns = append(out.Columns, c.shardColumn)
+		if out.columnTypes != nil {
+			// The shard column is not from the driver, so has no column type.
+			out.columnTypes = append(out.columnTypes[:len(out.columnTypes):len(out.columnTypes)], nil)
+		}
+	}
+	var n int
+	for _, b := range parts {
+		n += b.Len()
+	}
+	out.Rows = make([]Row, 0, n)
+	for i, b := range parts {
+		if b.Len() > 0 && !slices.Equal(b.Columns, first.Columns) {
+			return nil, fmt.Errorf("%w: shard %d columns %q, shard %d columns %q", ErrSchemaMismatch, i, b.Columns, firstIndex, first.Columns)
+		}
+		shard := int64(i)
+		err := b.Each(func(_ int, row Row) error {
+			field := row.Field
+			if len(c.shardColumn) > 0 {
+				field = append(field[:len(field):len(field)], shard)
+			}
+			out.AddRow(field)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// runParallel calls fn for each index from 0 to n, at most parallel at once.
+// The first error cancels the context passed to the other calls and is returned.
+func runParallel(ctx context.Context, n, parallel int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, parallel)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ctx, i); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,152 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSetParallel(t *testing.T) {
+	ctx := context.Background()
+	var (
+		mu            sync.Mutex
+		running, peak int
+	)
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if query == "fail" {
+			return nil, errors.New("bad query")
+		}
+		return []fakeResult{{Columns: []string{query}, Rows: [][]driver.Value{{args[0].Value}}}}, nil
+	})
+	list := []struct {
+		Name    string
+		Queries []Query
+		Opts    []Option
+		Peak    int
+		Error   string
+	}{
+		{
+			Name: "ordered",
+			Queries: []Query{
+				{SQL: "a", Params: []any{int64(1)}},
+				{SQL: "b", Params: []any{int64(2)}},
+				{SQL: "c", Params: []any{int64(3)}},
+				{SQL: "d", Params: []any{int64(4), NullAs("x")}},
+			},
+			Opts: []Option{Parallel(2)},
+			Peak: 2,
+		},
+		{
+			Name: "error",
+			Queries: []Query{
+				{SQL: "a", Params: []any{int64(1)}},
+				{SQL: "fail", Params: []any{int64(2)}},
+			},
+			Error: "query 1: bad query",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			peak = 0
+			set, err := NewSetParallel(ctx, db, item.Queries, item.Opts...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := peak, item.Peak; g != w {
+				t.Fatalf("peak concurrent queries got %d want %d", g, w)
+			}
+			for i, qry := range item.Queries {
+				b := set[i]
+				if b.Columns[0] != qry.SQL || b.Rows[0].Field[0] != qry.Params[0] {
+					t.Fatalf("buffer %d got %v %v want %s %v", i, b.Columns, b.Rows[0].Field, qry.SQL, qry.Params[0])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryShards(t *testing.T) {
+	ctx := context.Background()
+	shard := func(columns []string, rows ...int64) Queryer {
+		res := fakeResult{Columns: columns}
+		for _, r := range rows {
+			res.Rows = append(res.Rows, []driver.Value{r, "x"})
+		}
+		return openFake(t, fakeSet(res))
+	}
+	cols := []string{"ID", "Name"}
+	list := []struct {
+		Name   string
+		Shards []Queryer
+		Opts   []any
+		Want   string
+		Error  string
+	}{
+		{
+			Name:   "append",
+			Shards: []Queryer{shard(cols, 1, 2), shard(cols), shard(cols, 3)},
+			Want:   "[ID Name] [[1 x] [2 x] [3 x]]",
+		},
+		{
+			Name:   "shard-column",
+			Shards: []Queryer{shard(cols, 1), shard(cols, 2)},
+			Opts:   []any{ShardColumn("Shard"), Parallel(1)},
+			Want:   "[ID Name Shard] [[1 x 0] [2 x 1]]",
+		},
+		{
+			Name:   "mismatch",
+			Shards: []Queryer{shard(cols, 1), shard([]string{"ID", "Title"}, 2)},
+			Error:  `schema mismatch: shard 1 columns ["ID" "Title"], shard 0 columns ["ID" "Name"]`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b, err := QueryShards(ctx, item.Shards, "select ID, Name", item.Opts...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			rows := make([][]any, len(b.Rows))
+			for i, r := range b.Rows {
+				rows[i] = r.Field
+			}
+			if g, w := fmt.Sprint(b.Columns, " ", rows), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+			if i, ok := b.ColumnIndex("Name"); !ok || i != 1 {
+				t.Fatalf("column index got %d %t", i, ok)
+			}
+			if g, w := len(b.columnTypes), len(b.Columns); g != w {
+				t.Fatalf("column types got %d want %d", g, w)
+			}
+			if g, w := len(b.Schema()), len(b.Columns); g != w {
+				t.Fatalf("schema got %d columns want %d", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package table
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Params returns a sql.NamedArg for each exported field of the struct v, or
+// the struct v points to, for queries with named placeholders:
+//
+//	q := "select * from Report where Region = @Region and Year = @Year"
+//	b, err := table.NewBuffer(ctx, db, q, table.Params(filter)...)
+//
+// Fields are named as BufferToStruct maps them, including the StructTag and
+// TagFallback options: by the "sql" tag if set, otherwise by the field name.
+// Fields tagged "-" are skipped, and embedded structs are a single field.
+// A nil pointer returns no parameters. Params panics if v is not a struct or
+// has an invalid tag.
+func Params(v any, opts ...Option) []any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("table: Params requires a struct, got %T", v))
+	}
+	tp := rv.Type()
+	tags := newFillConfig(opts).tagNames()
+	sc, err := structColumns(tp, tags)
+	if err != nil {
+		panic(fmt.Sprintf("table: Params of %v: %v", tp, err))
+	}
+	plan := getStructPlan(tp, sc.Columns, nil, tags)
+	params := make([]any, len(plan.fields))
+	for i, pf := range plan.fields {
+		params[i] = sql.Named(sc.Columns[pf.column], rv.Field(pf.index).Interface())
+	}
+	return params
+}
@@ -0,0 +1,116 @@
+package table
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	type Page struct {
+		Limit  int
+		Offset int `sql:"Skip"`
+	}
+	type Filter struct {
+		Page
+		Region string `sql:"RegionCode" db:"region"`
+		Year   int    `json:"year"`
+		Min    *float64
+		Note   string `sql:"-"`
+		secret string
+	}
+	minPrice := 1.5
+
+	list := []struct {
+		Name  string
+		Value any
+		Opts  []Option
+		Want  string
+		Panic string
+	}{
+		{Name: "struct", Value: Filter{Page: Page{10, 20}, Region: "EU", Year: 2024, Note: "n", secret: "s"}, Want: "Page={10 20} RegionCode=EU Year=2024 Min=<nil>"},
+		{Name: "pointer", Value: &Filter{Region: "US", Min: &minPrice}, Want: "Page={0 0} RegionCode=US Year=0 Min=1.5"},
+		{Name: "struct-tag", Value: Filter{Region: "EU"}, Opts: []Option{StructTag("db")}, Want: "Page={0 0} region=EU Year=0 Min=<nil> Note="},
+		{Name: "tag-fallback", Value: Filter{Region: "EU", Year: 2024}, Opts: []Option{TagFallback()}, Want: "Page={0 0} RegionCode=EU year=2024 Min=<nil>"},
+		{Name: "nil", Value: (*Filter)(nil), Want: ""},
+		{Name: "not struct", Value: 5, Panic: "table: Params requires a struct, got int"},
+		{Name: "bad tag", Value: struct {
+			ID int `sql:",primary"`
+		}{}, Panic: `table: Params of struct { ID int "sql:\",primary\"" }: field ID: unknown tag option "primary"`},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if g, w := fmt.Sprint(r), item.Panic; r != nil || w != "" {
+					if g != w {
+						t.Fatalf("expected panic: %s, got panic: %s", w, g)
+					}
+				}
+			}()
+			var got []string
+			for _, p := range Params(item.Value, item.Opts...) {
+				na := p.(sql.NamedArg)
+				var v any = na.Value
+				if f, ok := v.(*float64); ok && f != nil {
+					v = *f
+				}
+				got = append(got, fmt.Sprintf("%s=%v", na.Name, v))
+			}
+			if g := strings.Join(got, " "); g != item.Want {
+				t.Fatalf("got %q want %q", g, item.Want)
+			}
+		})
+	}
+}
+
+// TestParamsStruct checks that Params names the fields as BufferToStruct
+// maps them, so a buffer of the parameters maps back to the struct.
+func TestParamsStruct(t *testing.T) {
+	type Audit struct {
+		By string
+	}
+	type Account struct {
+		Audit
+		ID    int64  `sql:"account_id,pk" db:"id"`
+		Name  string `db:"name"`
+		Email string `json:"email"`
+		Skip  string `sql:"-"`
+	}
+	want := Account{Audit: Audit{By: "admin"}, ID: 7, Name: "Ann", Email: "ann@example.com"}
+
+	list := []struct {
+		Name string
+		Opts []Option
+		Skip string // Skip is only mapped if the "sql" tag is not read.
+	}{
+		{Name: "default"},
+		{Name: "struct-tag", Opts: []Option{StructTag("db")}, Skip: "s"},
+		{Name: "tag-fallback", Opts: []Option{TagFallback()}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			want := want
+			want.Skip = item.Skip
+			v := want
+			v.Skip = "s"
+			b := &Buffer{}
+			var row []any
+			for _, p := range Params(v, item.Opts...) {
+				na := p.(sql.NamedArg)
+				b.Columns = append(b.Columns, na.Name)
+				row = append(row, na.Value)
+			}
+			b.AddRow(row)
+			got, err := BufferToStruct[Account](b, item.Opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+				t.Fatalf("columns %q: got %+v want %+v", b.Columns, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package table
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// Patch holds the rows that differ between two buffers with the same
+// columns, matched by the Key columns. Apply it with Buffer.ApplyPatch.
+// A Patch can be stored or sent to another process with MarshalBinary,
+// which keeps the exact value types like WriteSnapshot.
+type Patch struct {
+	Key []string
+
+	Deletes *Buffer // Key columns of the rows to delete.
+	Updates *Buffer // Every column of the rows to change, with the new values.
+	Inserts *Buffer // Every column of the rows to add.
+}
+
+// Diff returns the patch that changes b into to. Rows are matched by the
+// key columns, which must be unique in each buffer. Both buffers must have
+// the same columns in the same order. Values are compared by type and value,
+// so int64(1) and float64(1) differ. Field types are limited to those
+// supported by WriteSnapshot.
+func (b *Buffer) Diff(to *Buffer, key ...string) (Patch, error) {
+	p := Patch{Key: key}
+	if !slices.Equal(b.Columns, to.Columns) {
+		return p, fmt.Errorf("%w: columns %q, want %q", ErrSchemaMismatch, to.Columns, b.Columns)
+	}
+	keys, err := b.keyIndex(key)
+	if err != nil {
+		return p, err
+	}
+	ke := newKeyEncoder()
+	from, err := b.rowsByKey(ke, keys)
+	if err != nil {
+		return p, err
+	}
+	if err := to.inMemory(); err != nil {
+		return p, err
+	}
+	all := make([]int, len(b.Columns))
+	for i := range all {
+		all[i] = i
+	}
+	p.Deletes = &Buffer{Columns: append([]string(nil), key...)}
+	p.Updates = &Buffer{Columns: append([]string(nil), b.Columns...)}
+	p.Inserts = &Buffer{Columns: append([]string(nil), b.Columns...)}
+
+	seen := make(map[string]bool, len(to.Rows))
+	for ri, row := range to.Rows {
+		k, err := ke.key(row.Field, keys)
+		if err != nil {
+			return p, fmt.Errorf("row %d: %w", ri, err)
+		}
+		if seen[k] {
+			return p, fmt.Errorf("row %d: duplicate key %v", ri, pick(row.Field, keys))
+		}
+		seen[k] = true
+		fi, ok := from[k]
+		if !ok {
+			p.Inserts.AddRow(append([]any(nil), row.Field...))
+			continue
+		}
+		old, err := ke.key(b.Rows[fi].Field, all)
+		if err != nil {
+			return p, fmt.Errorf("row %d: %w", fi, err)
+		}
+		cur, err := ke.key(row.Field, all)
+		if err != nil {
+			return p, fmt.Errorf("row %d: %w", ri, err)
+		}
+		if old != cur {
+			p.Updates.AddRow(append([]any(nil), row.Field...))
+		}
+	}
+	for _, row := range b.Rows {
+		k, _ := ke.key(row.Field, keys)
+		if !seen[k] {
+			p.Deletes.AddRow(pick(row.Field, keys))
+		}
+	}
+	return p, nil
+}
+
+// ApplyPatch applies the patch to the buffer: the updated rows are changed,
+// then the deleted rows are removed, then the inserted rows are added at the
+// end. The changes are made with Set, DeleteRow, and AddRow, so they are
+// recorded when tracking changes. The patch is checked before any change is
+// made; every deleted and updated key must exist and no inserted key may
+// remain after the deletes.
+func (b *Buffer) ApplyPatch(p Patch) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	for _, pb := range []*Buffer{p.Updates, p.Inserts} {
+		if pb != nil && !slices.Equal(pb.Columns, b.Columns) {
+			return fmt.Errorf("%w: patch columns %q, want %q", ErrSchemaMismatch, pb.Columns, b.Columns)
+		}
+	}
+	if p.Deletes != nil && !slices.Equal(p.Deletes.Columns, p.Key) {
+		return fmt.Errorf("%w: patch delete columns %q, want key %q", ErrSchemaMismatch, p.Deletes.Columns, p.Key)
+	}
+	keys, err := b.keyIndex(p.Key)
+	if err != nil {
+		return err
+	}
+	ke := newKeyEncoder()
+	index, err := b.rowsByKey(ke, keys)
+	if err != nil {
+		return err
+	}
+	find := func(op string, field []any, keys []int) (int, string, error) {
+		k, err := ke.key(field, keys)
+		if err != nil {
+			return 0, "", fmt.Errorf("patch %s: %w", op, err)
+		}
+		ri, ok := index[k]
+		if !ok {
+			return 0, k, fmt.Errorf("patch %s key %v not found", op, pick(field, keys))
+		}
+		return ri, k, nil
+	}
+
+	var updates []int
+	if p.Updates != nil {
+		updates = make([]int, len(p.Updates.Rows))
+		for i, row := range p.Updates.Rows {
+			if updates[i], _, err = find("update", row.Field, keys); err != nil {
+				return err
+			}
+		}
+	}
+	var deletes []int
+	if p.Deletes != nil {
+		deleteKeys := make([]int, len(p.Key))
+		for i := range deleteKeys {
+			deleteKeys[i] = i
+		}
+		for _, row := range p.Deletes.Rows {
+			ri, k, err := find("delete", row.Field, deleteKeys)
+			if err != nil {
+				return err
+			}
+			deletes = append(deletes, ri)
+			delete(index, k)
+		}
+	}
+	if p.Inserts != nil {
+		for _, row := range p.Inserts.Rows {
+			k, err := ke.key(row.Field, keys)
+			if err != nil {
+				return fmt.Errorf("patch insert: %w", err)
+			}
+			if _, ok := index[k]; ok {
+				return fmt.Errorf("patch insert key %v exists", pick(row.Field, keys))
+			}
+			index[k] = -1
+		}
+	}
+
+	for i, ri := range updates {
+		for ci, v := range p.Updates.Rows[i].Field {
+			old, err := ke.key(b.Rows[ri].Field[ci:ci+1], []int{0})
+			if err != nil {
+				return err
+			}
+			cur, err := ke.key([]any{v}, []int{0})
+			if err != nil {
+				return err
+			}
+			if old != cur {
+				if err := b.Set(ri, b.Columns[ci], v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(deletes)))
+	for _, ri := range deletes {
+		if err := b.DeleteRow(ri); err != nil {
+			return err
+		}
+	}
+	if p.Inserts != nil {
+		for _, row := range p.Inserts.Rows {
+			b.AddRow(append([]any(nil), row.Field...))
+		}
+	}
+	return nil
+}
+
+// keyIndex returns the column index of each key column.
+func (b *Buffer) keyIndex(key []string) ([]int, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("no key columns")
+	}
+	keys := make([]int, len(key))
+	for i, n := range key {
+		ci, err := b.lookupColumn(n)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = ci
+	}
+	return keys, nil
+}
+
+// rowsByKey returns the row index of each key, which must be unique.
+// It returns ErrSpilled if rows of the buffer are on disk.
+func (b *Buffer) rowsByKey(ke *keyEncoder, keys []int) (map[string]int, error) {
+	if err := b.inMemory(); err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(b.Rows))
+	for ri, row := range b.Rows {
+		k, err := ke.key(row.Field, keys)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", ri, err)
+		}
+		if _, ok := index[k]; ok {
+			return nil, fmt.Errorf("row %d: duplicate key %v", ri, pick(row.Field, keys))
+		}
+		index[k] = ri
+	}
+	return index, nil
+}
+
+// keyEncoder encodes fields as comparable strings, by type and value, using
+// the snapshot encoding.
+type keyEncoder struct {
+	buf bytes.Buffer
+	sw  snapshotWriter
+}
+
+func newKeyEncoder() *keyEncoder {
+	ke := &keyEncoder{}
+	ke.sw.w = bufio.NewWriter(&ke.buf)
+	return ke
+}
+
+// key returns the encoding of the fields at index.
+func (ke *keyEncoder) key(field []any, index []int) (string, error) {
+	ke.buf.Reset()
+	ke.sw.err = nil
+	for _, i := range index {
+		if err := ke.sw.value(field[i]); err != nil {
+			return "", err
+		}
+	}
+	if ke.sw.err == nil {
+		ke.sw.err = ke.sw.w.Flush()
+	}
+	if ke.sw.err != nil {
+		return "", ke.sw.err
+	}
+	return ke.buf.String(), nil
+}
+
+const (
+	patchMagic   = "TBLPATCH"
+	patchVersion = 1
+)
+
+// MarshalBinary encodes the patch as the key column names followed by a
+// snapshot of the deletes, updates, and inserts.
+func (p Patch) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	sw := &snapshotWriter{w: bufio.NewWriter(&buf)}
+	sw.raw([]byte(patchMagic))
+	sw.raw([]byte{patchVersion})
+	sw.columns(p.Key)
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+	if sw.err != nil {
+		return nil, sw.err
+	}
+	for _, pb := range []*Buffer{p.Deletes, p.Updates, p.Inserts} {
+		if pb == nil {
+			pb = &Buffer{}
+		}
+		if err := pb.WriteSnapshot(&buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a patch encoded by MarshalBinary.
+func (p *Patch) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	sr := &snapshotReader{r: r, br: r}
+	magic := make([]byte, len(patchMagic)+1)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic[:len(patchMagic)]) != patchMagic {
+		return fmt.Errorf("%w: not a patch", ErrSnapshotFormat)
+	}
+	if v := magic[len(patchMagic)]; v != patchVersion {
+		return fmt.Errorf("%w: unsupported patch version %d", ErrSnapshotFormat, v)
+	}
+	n := sr.count()
+	key := make([]string, 0, min(n, maxPrealloc))
+	for i := 0; i < n && sr.err == nil; i++ {
+		key = append(key, string(sr.bytes()))
+	}
+	if sr.err != nil {
+		return sr.err
+	}
+	var set [3]*Buffer
+	for i := range set {
+		b, err := ReadSnapshot(r)
+		if err != nil {
+			return err
+		}
+		set[i] = b
+	}
+	*p = Patch{Key: key, Deletes: set[0], Updates: set[1], Inserts: set[2]}
+	return nil
+}
@@ -0,0 +1,119 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	newBuffer := func(rows ...[]any) *Buffer {
+		b := &Buffer{
+			Columns: []string{"id", "name", "score"},
+		}
+		for _, r := range rows {
+			b.AddRow(r)
+		}
+		return b
+	}
+	from := newBuffer(
+		[]any{int64(1), "R1", int64(10)},
+		[]any{int64(2), "R2", nil},
+		[]any{int64(3), "R3", int64(30)},
+	)
+	to := newBuffer(
+		[]any{int64(3), "R3", int64(30)},
+		[]any{int64(1), "One", int64(10)},
+		[]any{int64(4), "R4", nil},
+	)
+
+	p, err := from.Diff(to, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := func(b *Buffer) string {
+		out := make([][]any, len(b.Rows))
+		for i, row := range b.Rows {
+			out[i] = row.Field
+		}
+		return fmt.Sprint(out)
+	}
+	if g, w := fmt.Sprintln(fields(p.Deletes), fields(p.Updates), fields(p.Inserts)), "[[2]] [[1 One 10]] [[4 R4 <nil>]]\n"; g != w {
+		t.Fatalf("got %s, want %s", g, w)
+	}
+
+	bb, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read Patch
+	if err := read.UnmarshalBinary(bb); err != nil {
+		t.Fatal(err)
+	}
+
+	from.TrackChanges()
+	if err := from.ApplyPatch(read); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fields(from), "[[1 One 10] [3 R3 30] [4 R4 <nil>]]"; g != w {
+		t.Fatalf("got %s, want %s", g, w)
+	}
+	var kinds []string
+	for _, c := range from.Changes() {
+		kinds = append(kinds, fmt.Sprint(c.Kind, c.Columns))
+	}
+	if g, w := fmt.Sprint(kinds), "[delete [] update [name] insert []]"; g != w {
+		t.Fatalf("got %s, want %s", g, w)
+	}
+
+	list := []struct {
+		Name  string
+		Err   func() error
+		Error string
+	}{
+		{
+			Name: "columns",
+			Err: func() error {
+				_, err := from.Diff(&Buffer{Columns: []string{"id"}}, "id")
+				return err
+			},
+			Error: `schema mismatch: columns ["id"], want ["id" "name" "score"]`,
+		},
+		{
+			Name: "duplicate",
+			Err: func() error {
+				_, err := from.Diff(newBuffer([]any{int64(1), "A", nil}, []any{int64(1), "B", nil}), "id")
+				return err
+			},
+			Error: "row 1: duplicate key [1]",
+		},
+		{
+			Name:  "missing update",
+			Err:   func() error { return newBuffer().ApplyPatch(read) },
+			Error: "patch update key [1] not found",
+		},
+		{
+			Name: "insert exists",
+			Err: func() error {
+				return newBuffer([]any{int64(4), "R4", nil}).ApplyPatch(Patch{Key: []string{"id"}, Inserts: to})
+			},
+			Error: "patch insert key [4] exists",
+		},
+		{
+			Name:  "corrupt",
+			Err:   func() error { return read.UnmarshalBinary(bb[:12]) },
+			Error: "invalid table snapshot: unexpected EOF",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			err := item.Err()
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+		})
+	}
+}
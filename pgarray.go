@@ -0,0 +1,202 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PostgresArrays decodes Postgres array text, such as {1,2,3} or {"a","b"},
+// in array columns into Go slices as they are filled. Array columns are those
+// with a database type name starting with an underscore, such as _INT4, or
+// ending in [], such as INT4[]. Integer arrays become []int64, floating
+// point arrays []float64, boolean arrays []bool, and all others []string.
+// Multi-dimensional arrays are not supported. A NULL element is an error
+// wrapping ErrNull.
+func PostgresArrays() Option {
+	return WithConverter(func(col Column, v any) (any, error) {
+		elem, ok := pgArrayElem(col.DatabaseTypeName)
+		if !ok || v == nil {
+			return v, nil
+		}
+		s, ok := asString(v)
+		if !ok {
+			return v, nil
+		}
+		items, err := parsePGArray(s)
+		if err != nil {
+			return nil, err
+		}
+		switch elem {
+		case "INT2", "INT4", "INT8", "OID":
+			return pgArrayTo(items, func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+		case "FLOAT4", "FLOAT8":
+			return pgArrayTo(items, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+		case "BOOL":
+			return pgArrayTo(items, parsePGBool)
+		default:
+			return pgArrayTo(items, func(s string) (string, error) { return s, nil })
+		}
+	})
+}
+
+// pgArrayElem returns the element type name of an array type name.
+func pgArrayElem(typeName string) (string, bool) {
+	n := strings.ToUpper(typeName)
+	switch {
+	case strings.HasPrefix(n, "_"):
+		return n[1:], true
+	case strings.HasSuffix(n, "[]"):
+		return n[:len(n)-2], true
+	}
+	return "", false
+}
+
+func pgArrayTo[T any](items []*string, parse func(string) (T, error)) ([]T, error) {
+	out := make([]T, len(items))
+	for i, item := range items {
+		if item == nil {
+			return nil, fmt.Errorf("array element %d: %w", i, ErrNull)
+		}
+		v, err := parse(*item)
+		if err != nil {
+			return nil, fmt.Errorf("array element %d: %w", i, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func parsePGBool(s string) (bool, error) {
+	switch s {
+	case "t", "true":
+		return true, nil
+	case "f", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
+
+var errPGArray = errors.New("invalid array text")
+
+// parsePGArray parses one dimensional Postgres array text.
+// NULL elements are returned as nil.
+func parsePGArray(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("%w %q", errPGArray, s)
+	}
+	body := s[1 : len(s)-1]
+	if len(body) == 0 {
+		return []*string{}, nil
+	}
+	var items []*string
+	for i := 0; i <= len(body); {
+		if i < len(body) && body[i] == '{' {
+			return nil, fmt.Errorf("%w %q: multi-dimensional arrays are not supported", errPGArray, s)
+		}
+		var sb strings.Builder
+		quoted := i < len(body) && body[i] == '"'
+		if quoted {
+			i++
+			closed := false
+			for i < len(body) {
+				ch := body[i]
+				i++
+				if ch == '\\' && i < len(body) {
+					sb.WriteByte(body[i])
+					i++
+					continue
+				}
+				if ch == '"' {
+					closed = true
+					break
+				}
+				sb.WriteByte(ch)
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w %q: unterminated quote", errPGArray, s)
+			}
+		} else {
+			for i < len(body) && body[i] != ',' {
+				sb.WriteByte(body[i])
+				i++
+			}
+		}
+		item := sb.String()
+		if !quoted && strings.EqualFold(item, "NULL") {
+			items = append(items, nil)
+		} else {
+			items = append(items, &item)
+		}
+		if i < len(body) && body[i] != ',' {
+			return nil, fmt.Errorf("%w %q", errPGArray, s)
+		}
+		i++
+	}
+	return items, nil
+}
+
+// assignPGArray sets the slice field rf from Postgres array text.
+func assignPGArray(rf reflect.Value, s string) error {
+	items, err := parsePGArray(s)
+	if err != nil {
+		return err
+	}
+	out := reflect.MakeSlice(rf.Type(), len(items), len(items))
+	for i, item := range items {
+		ev := out.Index(i)
+		if item == nil {
+			if ev.Kind() == reflect.Pointer {
+				continue
+			}
+			return fmt.Errorf("array element %d: %w", i, ErrNull)
+		}
+		if ev.Kind() == reflect.Pointer {
+			p := reflect.New(ev.Type().Elem())
+			ev.Set(p)
+			ev = p.Elem()
+		}
+		if err := setFromText(ev, *item); err != nil {
+			return fmt.Errorf("array element %d: %w", i, err)
+		}
+	}
+	rf.Set(out)
+	return nil
+}
+
+// setFromText parses s into a value of a basic kind.
+func setFromText(rv reflect.Value, s string) error {
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Bool:
+		b, err := parsePGBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	default:
+		return fmt.Errorf("cannot parse text into %s", rv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Package tablepgx fills table buffers from pgx, which does not use
+// database/sql, keeping the values pgx decodes, such as netip.Prefix,
+// pgtype.Range, and slices for arrays.
+//
+// To use a *pgxpool.Pool with the table package instead, as a table.Queryer
+// through database/sql, use OpenDB. Values read that way are limited to the
+// driver.Value types, so values such as netip.Prefix are not kept.
+package tablepgx
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/golang-sql/table"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Querier runs a query with pgx, such as *pgx.Conn, *pgxpool.Pool, and pgx.Tx.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+var (
+	_ Querier = (*pgx.Conn)(nil)
+	_ Querier = (*pgxpool.Pool)(nil)
+	_ Querier = pgx.Tx(nil)
+)
+
+// NewBuffer runs the query and returns the rows in a new buffer.
+func NewBuffer(ctx context.Context, q Querier, sql string, args ...any) (*table.Buffer, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return Fill(ctx, rows)
+}
+
+// Fill reads the rows into a new buffer and closes them. Each field holds the
+// value decoded by pgx, as returned by pgx.Rows.Values.
+// When an error is returned, the buffer read up to that point is also returned.
+func Fill(ctx context.Context, rows pgx.Rows) (*table.Buffer, error) {
+	defer rows.Close()
+	fds := rows.FieldDescriptions()
+	b := &table.Buffer{
+		Columns: make([]string, len(fds)),
+	}
+	for i, fd := range fds {
+		b.Columns[i] = fd.Name
+	}
+	for n := 0; rows.Next(); n++ {
+		if n%64 == 0 {
+			if err := ctx.Err(); err != nil {
+				return b, err
+			}
+		}
+		field, err := rows.Values()
+		if err != nil {
+			return b, err
+		}
+		b.AddRow(field)
+	}
+	rows.Close()
+	return b, rows.Err()
+}
+
+// OpenDB returns a *sql.DB using the pool, which may be used as a
+// table.Queryer. Closing it does not close the pool.
+func OpenDB(pool *pgxpool.Pool) *sql.DB {
+	return stdlib.OpenDBFromPool(pool)
+}
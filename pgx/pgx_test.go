@@ -0,0 +1,99 @@
+package tablepgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRows returns the values of data, then err.
+type fakeRows struct {
+	columns []string
+	data    [][]any
+	err     error
+	n       int
+	closed  bool
+}
+
+func (r *fakeRows) Close()                        { r.closed = true }
+func (r *fakeRows) Err() error                    { return r.err }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+func (r *fakeRows) Scan(dest ...any) error        { return errors.New("not supported") }
+func (r *fakeRows) RawValues() [][]byte           { return nil }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fds := make([]pgconn.FieldDescription, len(r.columns))
+	for i, c := range r.columns {
+		fds[i].Name = c
+	}
+	return fds
+}
+
+func (r *fakeRows) Next() bool {
+	if r.closed || r.n >= len(r.data) {
+		return false
+	}
+	r.n++
+	return true
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	return r.data[r.n-1], nil
+}
+
+type querierFunc func(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+
+func (fn querierFunc) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return fn(ctx, sql, args...)
+}
+
+func TestNewBuffer(t *testing.T) {
+	ctx := context.Background()
+	addr := netip.MustParsePrefix("10.0.0.0/8")
+	rows := &fakeRows{
+		columns: []string{"ID", "Net", "Tags"},
+		data: [][]any{
+			{int32(1), addr, []any{"a", "b"}},
+			{int32(2), nil, nil},
+		},
+	}
+	q := querierFunc(func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		return rows, nil
+	})
+	b, err := NewBuffer(ctx, q, "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.closed {
+		t.Fatal("rows not closed")
+	}
+	if g, w := fmt.Sprint(b.Columns), "[ID Net Tags]"; g != w {
+		t.Fatalf("columns got %s want %s", g, w)
+	}
+	if g, ok := b.Get(0, "Net").(netip.Prefix); !ok || g != addr {
+		t.Fatalf("got %#v want %v", b.Get(0, "Net"), addr)
+	}
+	if g, w := fmt.Sprintf("%v", b.Rows[1].Field), "[2 <nil> <nil>]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	errRead := errors.New("read failed")
+	rows = &fakeRows{
+		columns: []string{"ID"},
+		data:    [][]any{{int32(1)}},
+		err:     errRead,
+	}
+	b, err = Fill(ctx, rows)
+	if !errors.Is(err, errRead) {
+		t.Fatalf("expected error: %s, got error: %s", errRead, err)
+	}
+	if g, w := len(b.Rows), 1; g != w {
+		t.Fatalf("got %d rows want %d", g, w)
+	}
+}
@@ -0,0 +1,70 @@
+// Package tableprom reports table.Metrics to Prometheus. Create the
+// collectors once with NewMetrics and pass them to each fill with the
+// table.WithMetrics option.
+package tableprom
+
+import (
+	"time"
+
+	"github.com/golang-sql/table"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements table.Metrics with Prometheus collectors:
+//
+//   - <namespace>_query_duration_seconds, a histogram with a "status" label
+//     of "ok" or "error".
+//   - <namespace>_rows_fetched_total, a counter.
+//   - <namespace>_buffer_bytes, a gauge of the last filled set size.
+type Metrics struct {
+	duration *prometheus.HistogramVec
+	rows     prometheus.Counter
+	bytes    prometheus.Gauge
+}
+
+var _ table.Metrics = (*Metrics)(nil)
+
+// NewMetrics creates the collectors and registers them with reg.
+// The namespace prefixes the metric names, such as "table".
+func NewMetrics(reg prometheus.Registerer, namespace string) (*Metrics, error) {
+	m := &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Duration of queries and their fill.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"status"}),
+		rows: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rows_fetched_total",
+			Help:      "Rows fetched into buffers.",
+		}),
+		bytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "buffer_bytes",
+			Help:      "Estimated size of the last filled set.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.duration, m.rows, m.bytes} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Metrics) ObserveQuery(d time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.duration.WithLabelValues(status).Observe(d.Seconds())
+}
+
+func (m *Metrics) AddRows(n int) {
+	m.rows.Add(float64(n))
+}
+
+func (m *Metrics) SetBufferBytes(n int64) {
+	m.bytes.Set(float64(n))
+}
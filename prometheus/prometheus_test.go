@@ -0,0 +1,42 @@
+package tableprom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg, "table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ObserveQuery(time.Millisecond, nil)
+	m.ObserveQuery(time.Millisecond, errors.New("failed"))
+	m.AddRows(3)
+	m.AddRows(2)
+	m.SetBufferBytes(1024)
+
+	want := `
+# HELP table_buffer_bytes Estimated size of the last filled set.
+# TYPE table_buffer_bytes gauge
+table_buffer_bytes 1024
+# HELP table_rows_fetched_total Rows fetched into buffers.
+# TYPE table_rows_fetched_total counter
+table_rows_fetched_total 5
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "table_buffer_bytes", "table_rows_fetched_total"); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := testutil.CollectAndCount(m.duration), 2; g != w {
+		t.Fatalf("duration series got %d want %d", g, w)
+	}
+	if _, err := NewMetrics(reg, "table"); err == nil {
+		t.Fatal("expected an error registering twice")
+	}
+}
@@ -0,0 +1,86 @@
+package table
+
+import (
+	"database/sql"
+)
+
+// Redacted replaces sensitive parameter values in RedactParams.
+const Redacted = "[REDACTED]"
+
+// Redactor returns the parameters of a query that are safe to log or trace.
+// The params do not include Option values.
+type Redactor interface {
+	Redact(sql string, params []any) []any
+}
+
+// RedactorFunc adapts a function to a Redactor.
+type RedactorFunc func(sql string, params []any) []any
+
+func (fn RedactorFunc) Redact(sql string, params []any) []any {
+	return fn(sql, params)
+}
+
+// RedactAll is a Redactor that replaces every parameter with Redacted.
+var RedactAll Redactor = RedactorFunc(func(sql string, params []any) []any {
+	out := make([]any, len(params))
+	for i := range out {
+		out[i] = Redacted
+	}
+	return out
+})
+
+// SensitiveParams marks query parameters by position as sensitive, so
+// RedactParams replaces them. Positions start at 1, as in "$1".
+func SensitiveParams(positions ...int) Option {
+	return func(c *fillConfig) {
+		c.sensitive = append(c.sensitive, positions...)
+	}
+}
+
+// SensitiveNames marks sql.NamedArg query parameters by name as sensitive,
+// so RedactParams replaces them.
+func SensitiveNames(names ...string) Option {
+	return func(c *fillConfig) {
+		c.sensitiveNames = append(c.sensitiveNames, names...)
+	}
+}
+
+// RedactParams returns the params of a query, as passed to a query function
+// such as NewSet, for logging. Option values are removed, parameters marked
+// with SensitiveParams or SensitiveNames are replaced with Redacted, and then
+// the Redactor is applied if it is not nil. The params are not modified.
+func RedactParams(r Redactor, text string, params []any) []any {
+	params, opts := splitOptions(params)
+	return redactParams(newFillConfig(opts), r, text, params)
+}
+
+func redactParams(c *fillConfig, r Redactor, text string, params []any) []any {
+	if len(c.sensitive) > 0 || len(c.sensitiveNames) > 0 {
+		out := make([]any, len(params))
+		copy(out, params)
+		for _, n := range c.sensitive {
+			if n >= 1 && n <= len(out) {
+				out[n-1] = redactValue(out[n-1])
+			}
+		}
+		for i, p := range out {
+			if na, ok := p.(sql.NamedArg); ok && hasColumn(c.sensitiveNames, na.Name) {
+				out[i] = redactValue(na)
+			}
+		}
+		params = out
+	}
+	if r != nil {
+		params = r.Redact(text, params)
+	}
+	return params
+}
+
+// redactValue replaces the value, keeping the name of a sql.NamedArg.
+func redactValue(v any) any {
+	if na, ok := v.(sql.NamedArg); ok {
+		na.Value = Redacted
+		return na
+	}
+	return Redacted
+}
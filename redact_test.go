@@ -0,0 +1,34 @@
+package table
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+)
+
+func TestRedactParams(t *testing.T) {
+	params := []any{"alice", "hunter2", sql.Named("token", "abc"), int64(5)}
+	list := []struct {
+		Name     string
+		Redactor Redactor
+		Params   []any
+		Want     string
+	}{
+		{Name: "none", Params: params, Want: "[alice hunter2 {{} token abc} 5]"},
+		{Name: "options-removed", Params: append([]any{MaxRows(1)}, params...), Want: "[alice hunter2 {{} token abc} 5]"},
+		{Name: "position", Params: append(params, SensitiveParams(2, 9)), Want: "[alice [REDACTED] {{} token abc} 5]"},
+		{Name: "name", Params: append(params, SensitiveNames("token")), Want: "[alice hunter2 {{} token [REDACTED]} 5]"},
+		{Name: "all", Redactor: RedactAll, Params: params, Want: "[[REDACTED] [REDACTED] [REDACTED] [REDACTED]]"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			got := RedactParams(item.Redactor, "select", item.Params)
+			if g, w := fmt.Sprint(got), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+	if g, w := fmt.Sprint(params), "[alice hunter2 {{} token abc} 5]"; g != w {
+		t.Fatalf("params modified: %s", g)
+	}
+}
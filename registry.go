@@ -0,0 +1,78 @@
+package table
+
+import (
+	"strings"
+	"sync"
+)
+
+// TypeRegistry maps database type names, as reported by
+// sql.ColumnType.DatabaseTypeName, to converters applied as rows are filled.
+// Type names are matched without regard to case. It is safe for concurrent use.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]Converter
+}
+
+// NewTypeRegistry returns an empty registry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		types: make(map[string]Converter),
+	}
+}
+
+// Register sets the converter for a database type name, replacing any
+// previous converter. A nil fn removes the type.
+func (r *TypeRegistry) Register(typeName string, fn Converter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	typeName = strings.ToUpper(typeName)
+	if fn == nil {
+		delete(r.types, typeName)
+		return
+	}
+	r.types[typeName] = fn
+}
+
+// Lookup returns the converter for a database type name.
+func (r *TypeRegistry) Lookup(typeName string) (Converter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fn, ok := r.types[strings.ToUpper(typeName)]
+	return fn, ok
+}
+
+// DefaultTypes is the global registry used by every fill.
+// Registries given with WithTypes take precedence over it.
+var DefaultTypes = NewTypeRegistry()
+
+// RegisterType sets the converter for a database type name in DefaultTypes.
+func RegisterType(typeName string, fn Converter) {
+	DefaultTypes.Register(typeName, fn)
+}
+
+// WithTypes adds a registry used for this fill. Registries are consulted in
+// the order given, then DefaultTypes. Type converters are applied before any
+// converters added by WithConverter.
+func WithTypes(r *TypeRegistry) Option {
+	return func(c *fillConfig) {
+		c.types = append(c.types, r)
+	}
+}
+
+// typeConverter returns the registered converter for a database type name.
+func (c *fillConfig) typeConverter(typeName string) Converter {
+	if len(typeName) == 0 {
+		return nil
+	}
+	for _, r := range c.types {
+		if fn, ok := r.Lookup(typeName); ok {
+			return fn
+		}
+	}
+	if fn, ok := DefaultTypes.Lookup(typeName); ok {
+		return fn
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTypeRegistry(t *testing.T) {
+	res := fakeResult{
+		Columns: []string{"Geo", "Code", "Name"},
+		Types:   []string{"GEOGRAPHY", "citext", "TEXT"},
+		Rows:    [][]driver.Value{{[]byte("POINT(1 2)"), []byte("AB"), "n"}},
+	}
+	RegisterType("GEOGRAPHY", func(col Column, v any) (any, error) {
+		return "global:" + string(v.([]byte)), nil
+	})
+	defer RegisterType("GEOGRAPHY", nil)
+
+	local := NewTypeRegistry()
+	local.Register("CITEXT", func(col Column, v any) (any, error) {
+		return strings.ToLower(string(v.([]byte))), nil
+	})
+	upper := func(col Column, v any) (any, error) {
+		if s, ok := v.(string); ok {
+			return strings.ToUpper(s), nil
+		}
+		return v, nil
+	}
+
+	set, err := FillSetOpt(context.Background(), fakeRows(t, res), WithTypes(local), WithConverter(upper))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(set[0].Rows[0].Field), "[GLOBAL:POINT(1 2) AB N]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
@@ -0,0 +1,101 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures RetryQueryer. Zero fields use the defaults.
+type RetryPolicy struct {
+	MaxAttempts    int           // Attempts including the first. Defaults to 3.
+	InitialBackoff time.Duration // Wait before the first retry. Defaults to 50ms.
+	MaxBackoff     time.Duration // Longest wait between attempts. Defaults to 2s.
+	Multiplier     float64       // Growth of the wait after each retry. Defaults to 2.
+	Jitter         float64       // Fraction of each wait that is randomized, from 0 to 1.
+
+	// Retryable reports if a query error is transient. Defaults to IsTransient.
+	// Use it to add driver specific errors, such as MySQL deadlocks.
+	Retryable func(err error) bool
+}
+
+// IsTransient reports if err is likely to succeed on retry: driver.ErrBadConn,
+// a connection reset, a serialization failure or deadlock reported through a
+// SQLState() string method (SQLSTATE 40001 or 40P01), or an error with a
+// Temporary() bool method that returns true. Context errors are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		switch state.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+	var temp interface{ Temporary() bool }
+	if errors.As(err, &temp) && temp.Temporary() {
+		return true
+	}
+	return false
+}
+
+// RetryQueryer returns a Queryer that retries QueryContext on transient
+// errors with exponential backoff. Waiting stops with the context error if
+// the context is done. Only the query is retried: an error while reading the
+// rows is returned to the caller.
+func RetryQueryer(q Queryer, policy RetryPolicy) Queryer {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = 50 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 2 * time.Second
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsTransient
+	}
+	return &retryQueryer{q: q, policy: policy}
+}
+
+type retryQueryer struct {
+	q      Queryer
+	policy RetryPolicy
+}
+
+func (rq *retryQueryer) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	p := rq.policy
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		rows, err := rq.q.QueryContext(ctx, text, params...)
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) {
+			return rows, err
+		}
+		wait := backoff
+		if p.Jitter > 0 {
+			j := time.Duration(p.Jitter * float64(wait))
+			wait = wait - j + time.Duration(rand.Int63n(int64(j)+1))
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+		backoff = min(time.Duration(float64(backoff)*p.Multiplier), p.MaxBackoff)
+	}
+}
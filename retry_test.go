@@ -0,0 +1,76 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type queryerFunc func(ctx context.Context, text string, params ...any) (*sql.Rows, error)
+
+func (fn queryerFunc) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	return fn(ctx, text, params...)
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestRetryQueryer(t *testing.T) {
+	errSyntax := errors.New("syntax error")
+	list := []struct {
+		Name      string
+		Errs      []error // Errors returned by each attempt, then success.
+		Retryable func(error) bool
+		Attempts  int
+		Error     string
+	}{
+		{Name: "success", Attempts: 1},
+		{Name: "bad-conn", Errs: []error{driver.ErrBadConn}, Attempts: 2},
+		{Name: "deadlock", Errs: []error{sqlStateError("40P01"), fmt.Errorf("wrapped: %w", sqlStateError("40001"))}, Attempts: 3},
+		{Name: "exhausted", Errs: []error{driver.ErrBadConn, driver.ErrBadConn, driver.ErrBadConn}, Attempts: 3, Error: driver.ErrBadConn.Error()},
+		{Name: "permanent", Errs: []error{errSyntax}, Attempts: 1, Error: "syntax error"},
+		{Name: "classifier", Errs: []error{errSyntax}, Retryable: func(err error) bool { return err == errSyntax }, Attempts: 2},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var attempts int
+			q := queryerFunc(func(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+				attempts++
+				if attempts <= len(item.Errs) {
+					return nil, item.Errs[attempts-1]
+				}
+				return nil, nil
+			})
+			rq := RetryQueryer(q, RetryPolicy{InitialBackoff: time.Microsecond, Jitter: 0.5, Retryable: item.Retryable})
+			_, err := rq.QueryContext(context.Background(), "select")
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if g, w := attempts, item.Attempts; g != w {
+				t.Fatalf("attempts got %d want %d", g, w)
+			}
+		})
+	}
+}
+
+func TestRetryQueryerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := queryerFunc(func(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+		cancel()
+		return nil, driver.ErrBadConn
+	})
+	_, err := RetryQueryer(q, RetryPolicy{InitialBackoff: time.Hour}).QueryContext(ctx, "select")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package table
+
+import (
+	"database/sql"
+	"io"
+	"sync"
+)
+
+// SafeBuffer guards a Buffer with a read-write mutex so it may be read and
+// modified by multiple goroutines, such as request handlers editing an
+// in memory table. Every method holds the lock for its duration.
+//
+// Values returned from a SafeBuffer do not share row storage with it, so they
+// remain valid after the lock is released. Use Read and Update to run several
+// calls under a single lock.
+type SafeBuffer struct {
+	mu sync.RWMutex
+	b  *Buffer
+}
+
+// NewSafeBuffer returns a SafeBuffer guarding b.
+// The caller must not use b directly afterwards.
+func NewSafeBuffer(b *Buffer) *SafeBuffer {
+	b.buildIndex()
+	return &SafeBuffer{b: b}
+}
+
+// Read calls fn with the buffer while holding the read lock.
+// The fn must not modify the buffer or keep references to it.
+func (s *SafeBuffer) Read(fn func(b *Buffer)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.b)
+}
+
+// Update calls fn with the buffer while holding the write lock and returns its error.
+// The fn must not keep references to the buffer.
+func (s *SafeBuffer) Update(fn func(b *Buffer) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.b.unshare()
+	return fn(s.b)
+}
+
+// Buffer returns a copy of the current buffer.
+func (s *SafeBuffer) Buffer() *Buffer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.clone()
+}
+
+// Columns returns a copy of the column names.
+func (s *SafeBuffer) Columns() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.b.Columns...)
+}
+
+// Len returns the number of rows.
+func (s *SafeBuffer) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.Len()
+}
+
+// Row returns a copy of the row at index i.
+func (s *SafeBuffer) Row(i int) (Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	row, err := s.b.Row(i)
+	if err != nil {
+		return Row{}, err
+	}
+	row.Field = append([]any(nil), row.Field...)
+	return row, nil
+}
+
+// Name returns the name of the buffer.
+func (s *SafeBuffer) Name() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.Name()
+}
+
+// Get the field from the row index and named column.
+// It panics like Buffer.Get.
+func (s *SafeBuffer) Get(rowIndex int, columnName string) any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.Get(rowIndex, columnName)
+}
+
+// At returns the field at the row and column index.
+func (s *SafeBuffer) At(rowIndex, colIndex int) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.At(rowIndex, colIndex)
+}
+
+// HasColumn reports if the buffer has the named column.
+func (s *SafeBuffer) HasColumn(columnName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.HasColumn(columnName)
+}
+
+// ColumnIndex returns the index of the named column and if it was found.
+func (s *SafeBuffer) ColumnIndex(columnName string) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.ColumnIndex(columnName)
+}
+
+// Result returns the statement result of the buffer.
+func (s *SafeBuffer) Result() sql.Result {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.Result()
+}
+
+// SetResult records the statement result of the buffer.
+func (s *SafeBuffer) SetResult(res sql.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.b.SetResult(res)
+}
+
+// Set the field at the row index and named column.
+func (s *SafeBuffer) Set(rowIndex int, columnName string, v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Set(rowIndex, columnName, v)
+}
+
+// AddRow adds a new row. It panics like Buffer.AddRow.
+// The row is copied, so the caller may reuse it.
+func (s *SafeBuffer) AddRow(row []any) {
+	row = append([]any(nil), row...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.b.AddRow(row)
+}
+
+// InsertRow inserts a new row before the row index.
+// The row is copied, so the caller may reuse it.
+func (s *SafeBuffer) InsertRow(rowIndex int, row []any) error {
+	row = append([]any(nil), row...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.InsertRow(rowIndex, row)
+}
+
+// DeleteRow removes the row at the row index.
+func (s *SafeBuffer) DeleteRow(rowIndex int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.DeleteRow(rowIndex)
+}
+
+// Changes returns the changes recorded since Buffer.TrackChanges was called
+// on the buffer. The rows of the changes are copies.
+func (s *SafeBuffer) Changes() []Change {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	changes := s.b.Changes()
+	for i := range changes {
+		changes[i].Row.Field = append([]any(nil), changes[i].Row.Field...)
+	}
+	return changes
+}
+
+// Coalesce replaces NULL values in the named column with defaultValue.
+func (s *SafeBuffer) Coalesce(columnName string, defaultValue any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Coalesce(columnName, defaultValue)
+}
+
+// CoalesceColumns replaces NULL values in each named column with the
+// column's default value.
+func (s *SafeBuffer) CoalesceColumns(defaults map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.CoalesceColumns(defaults)
+}
+
+// Mask replaces the values in each named column with the result of its MaskFunc.
+func (s *SafeBuffer) Mask(rules map[string]MaskFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Mask(rules)
+}
+
+// WriteCSV writes the buffer as CSV.
+func (s *SafeBuffer) WriteCSV(w io.Writer, opts ...TextOption) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.b.WriteCSV(w, opts...)
+}
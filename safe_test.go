@@ -0,0 +1,105 @@
+package table
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSafeBuffer(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Count"},
+	}
+	b.AddRow([]any{int64(1), int64(0)})
+	s := NewSafeBuffer(b)
+
+	const workers, adds = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < adds; i++ {
+				s.AddRow([]any{int64(w*adds + i + 2), nil})
+				err := s.Update(func(b *Buffer) error {
+					b.Rows[0].Field[1] = b.Get(0, "Count").(int64) + 1
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+				}
+				if _, err := s.Row(s.Len() - 1); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if g, w := s.Len(), workers*adds+1; g != w {
+		t.Fatalf("expected %d rows, got %d", w, g)
+	}
+	if g, w := s.Get(0, "Count"), int64(workers*adds); g != w {
+		t.Fatalf("expected count %d, got %v", w, g)
+	}
+
+	if err := s.Set(1, "Count", int64(7)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteRow(0); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := s.Get(0, "Count"), int64(7); g != w {
+		t.Fatalf("expected count %d, got %v", w, g)
+	}
+
+	list := []struct {
+		Name  string
+		Err   error
+		Error string
+	}{
+		{"Set column", s.Set(0, "Missing", nil), `Table doesn't have column named "Missing"`},
+		{"Set row", s.Set(-1, "ID", nil), "Table has 400 rows, requested index -1"},
+		{"DeleteRow", s.DeleteRow(400), "Table has 400 rows, requested index 400"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			var errs string
+			if item.Err != nil {
+				errs = item.Err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+		})
+	}
+
+	c := s.Buffer()
+	c.Rows[0].Field[1] = int64(8)
+	if g, w := s.Get(0, "Count"), int64(7); g != w {
+		t.Fatalf("copy changed buffer, expected count %d, got %v", w, g)
+	}
+}
+
+func TestSafeBufferCopyChanges(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name"},
+	}
+	b.AddRow([]any{int64(1), "R1"})
+	b.AddRow([]any{int64(2), "R2"})
+	b.TrackChanges()
+	s := NewSafeBuffer(b)
+
+	c := s.Buffer()
+	if err := c.DeleteRow(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(0, "Name", "Two"); err != nil {
+		t.Fatal(err)
+	}
+	if g := s.Changes(); len(g) != 0 {
+		t.Fatalf("copy recorded changes on buffer: %v", g)
+	}
+	if g := c.Changes(); g != nil {
+		t.Fatalf("expected copy not to track changes, got %v", g)
+	}
+}
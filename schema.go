@@ -0,0 +1,101 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Schema describes the columns of a Buffer in order.
+type Schema []SchemaColumn
+
+// SchemaColumn describes a single column.
+type SchemaColumn struct {
+	Name             string
+	Type             reflect.Type // Go type of the values, nil if unknown.
+	DatabaseTypeName string       // Empty if unknown.
+	Nullable         bool
+}
+
+// ErrSchemaMismatch is matched by errors.Is when ValidateSchema fails.
+var ErrSchemaMismatch = errors.New("schema mismatch")
+
+// Schema describes the buffer columns.
+//
+// The Go type is taken from the first non-NULL value in each column. The
+// database type and nullability are those reported by the driver when the
+// buffer was filled from a query. Otherwise a column is nullable if it has
+// a NULL value.
+//
+// Rows on disk are read in a single pass; Schema panics if they can not be read.
+func (t *Buffer) Schema() Schema {
+	s := make(Schema, len(t.Columns))
+	nullKnown := make([]bool, len(t.Columns))
+	for i, n := range t.Columns {
+		s[i] = SchemaColumn{Name: n}
+		if i < len(t.columnTypes) && t.columnTypes[i] != nil {
+			ct := t.columnTypes[i]
+			s[i].DatabaseTypeName = ct.DatabaseTypeName()
+			s[i].Nullable, nullKnown[i] = ct.Nullable()
+		}
+	}
+	t.eachRow(func(_ int, row Row) {
+		for i, v := range row.Field {
+			sc := &s[i]
+			if v == nil {
+				if !nullKnown[i] {
+					sc.Nullable = true
+				}
+				continue
+			}
+			if sc.Type == nil {
+				sc.Type = reflect.TypeOf(v)
+			}
+		}
+	})
+	return s
+}
+
+// ValidateSchema checks the buffer has the columns described by s in order.
+// Unknown types in s, a nil Type or empty DatabaseTypeName, are not checked,
+// and neither are types the buffer does not know. A column that is not
+// Nullable in s must have no NULL values. The returned error matches
+// ErrSchemaMismatch and lists every difference.
+func (t *Buffer) ValidateSchema(s Schema) error {
+	got := t.Schema()
+	var errs []error
+	mismatch := func(i int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: column %d: "+format, append([]any{ErrSchemaMismatch, i}, args...)...))
+	}
+	if len(got) != len(s) {
+		errs = append(errs, fmt.Errorf("%w: have %d columns, want %d", ErrSchemaMismatch, len(got), len(s)))
+	}
+	for i, want := range s {
+		if i >= len(got) {
+			break
+		}
+		g := got[i]
+		if g.Name != want.Name {
+			mismatch(i, "name %q, want %q", g.Name, want.Name)
+		}
+		if want.Type != nil && g.Type != nil && g.Type != want.Type {
+			mismatch(i, "%q type %v, want %v", want.Name, g.Type, want.Type)
+		}
+		if len(want.DatabaseTypeName) > 0 && len(g.DatabaseTypeName) > 0 && g.DatabaseTypeName != want.DatabaseTypeName {
+			mismatch(i, "%q database type %s, want %s", want.Name, g.DatabaseTypeName, want.DatabaseTypeName)
+		}
+		if !want.Nullable && t.hasNull(i) {
+			mismatch(i, "%q has NULL values", want.Name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hasNull reports if the column has a NULL value.
+func (t *Buffer) hasNull(col int) bool {
+	var found bool
+	t.eachRow(func(_ int, row Row) {
+		found = found || row.Field[col] == nil
+	})
+	return found
+}
@@ -0,0 +1,72 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	rows := fakeRows(t, fakeResult{
+		Columns: []string{"ID", "Name", "Note"},
+		Types:   []string{"INT", "TEXT", "TEXT"},
+		Rows: [][]driver.Value{
+			{int64(1), "R1", nil},
+			{int64(2), nil, nil},
+		},
+	})
+	set, err := FillSet(context.Background(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := set[0]
+	if g, w := fmt.Sprint(buf.Schema()), "[{ID int64 INT false} {Name string TEXT true} {Note <nil> TEXT true}]"; g != w {
+		t.Fatalf("schema got %s want %s", g, w)
+	}
+
+	tInt64, tString := reflect.TypeOf(int64(0)), reflect.TypeOf("")
+	list := []struct {
+		Name   string
+		Schema Schema
+		Error  string
+	}{
+		{
+			Name: "match",
+			Schema: Schema{
+				{Name: "ID", Type: tInt64, DatabaseTypeName: "INT"},
+				{Name: "Name", Type: tString, Nullable: true},
+				{Name: "Note", Type: tString, Nullable: true},
+			},
+		},
+		{
+			Name: "mismatch",
+			Schema: Schema{
+				{Name: "ID", Type: tString, DatabaseTypeName: "BIGINT"},
+				{Name: "Title", Type: tString},
+			},
+			Error: `schema mismatch: have 3 columns, want 2
+schema mismatch: column 0: "ID" type int64, want string
+schema mismatch: column 0: "ID" database type INT, want BIGINT
+schema mismatch: column 1: name "Name", want "Title"
+schema mismatch: column 1: "Title" has NULL values`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			err := buf.ValidateSchema(item.Schema)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+				if !errors.Is(err, ErrSchemaMismatch) {
+					t.Fatal("expected ErrSchemaMismatch")
+				}
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error:\n%s\ngot error:\n%s", w, g)
+			}
+		})
+	}
+}
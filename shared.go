@@ -0,0 +1,80 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// SharedQueryer returns a Queryer that runs concurrent identical queries,
+// with the same key as CachedQueryer, once. Queries that arrive while an
+// identical query is running wait for it and are answered with its result.
+// Every result set is read before rows are returned. Queries with parameters
+// CacheKey can not encode are always run on their own.
+//
+// A waiting query still returns when its own context is canceled, but the
+// running query is only canceled by the context of the query that started it.
+func SharedQueryer(q Queryer) Queryer {
+	return &sharedQueryer{q: q, calls: make(map[string]*sharedCall)}
+}
+
+type sharedQueryer struct {
+	q Queryer
+
+	mu    sync.Mutex
+	calls map[string]*sharedCall
+}
+
+type sharedCall struct {
+	done chan struct{}
+	cs   *cachedSet
+	err  error
+}
+
+func (sq *sharedQueryer) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	key := CacheKey(text, params...)
+	if len(key) == 0 {
+		return sq.q.QueryContext(ctx, text, params...)
+	}
+
+	sq.mu.Lock()
+	call, ok := sq.calls[key]
+	if !ok {
+		call = &sharedCall{done: make(chan struct{})}
+		sq.calls[key] = call
+	}
+	sq.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		call.cs, call.err = sq.load(ctx, text, params)
+
+		sq.mu.Lock()
+		delete(sq.calls, key)
+		sq.mu.Unlock()
+		close(call.done)
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	return cacheDB().QueryContext(ctx, "", call.cs)
+}
+
+func (sq *sharedQueryer) load(ctx context.Context, text string, params []any) (*cachedSet, error) {
+	rows, err := sq.q.QueryContext(ctx, text, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set, err := FillSetOpt(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	return newCachedSet(set), nil
+}
@@ -0,0 +1,76 @@
+package table
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+const (
+	sizeInterface = int64(unsafe.Sizeof(any(nil)))
+	sizeString    = int64(unsafe.Sizeof(""))
+	sizeSlice     = int64(unsafe.Sizeof([]byte(nil)))
+	sizeRow       = int64(unsafe.Sizeof(Row{}))
+	sizeBuffer    = int64(unsafe.Sizeof(Buffer{}))
+	sizeTime      = int64(unsafe.Sizeof(time.Time{}))
+
+	// Rough cost of a single map entry including bucket overhead.
+	sizeMapEntry = 48
+)
+
+// SizeBytes estimates the memory retained by the buffer, including the
+// contents of string and byte slice values. Memory shared between buffers,
+// such as time zone locations, and rows on disk are not counted.
+func (b *Buffer) SizeBytes() int64 {
+	if b == nil {
+		return 0
+	}
+	n := sizeBuffer
+	n += int64(cap(b.Columns)) * sizeString
+	for _, c := range b.Columns {
+		n += int64(len(c))
+	}
+	n += int64(len(b.columnNameIndex)) * sizeMapEntry
+	n += int64(cap(b.Rows)) * sizeRow
+	for _, row := range b.Rows {
+		n += rowSizeBytes(row.Field)
+	}
+	return n
+}
+
+// SizeBytes estimates the memory retained by all buffers in the set.
+func (s Set) SizeBytes() int64 {
+	n := int64(cap(s)) * int64(unsafe.Sizeof((*Buffer)(nil)))
+	for _, b := range s {
+		n += b.SizeBytes()
+	}
+	return n
+}
+
+// rowSizeBytes estimates the memory retained by a row's fields.
+func rowSizeBytes(field []any) int64 {
+	n := int64(cap(field)) * sizeInterface
+	for _, f := range field {
+		n += valueSizeBytes(f)
+	}
+	return n
+}
+
+// valueSizeBytes estimates the heap memory referenced by a field value,
+// not counting the interface value itself.
+func valueSizeBytes(v any) int64 {
+	switch v := v.(type) {
+	case nil, bool:
+		return 0
+	case int64, float64:
+		return 8
+	case string:
+		return sizeString + int64(len(v))
+	case []byte:
+		return sizeSlice + int64(cap(v))
+	case time.Time:
+		return sizeTime
+	default:
+		return int64(reflect.TypeOf(v).Size())
+	}
+}
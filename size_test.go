@@ -0,0 +1,69 @@
+package table
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestValueSizeBytes(t *testing.T) {
+	list := []struct {
+		Name  string
+		Value any
+		Want  int64
+	}{
+		{"nil", nil, 0},
+		{"bool", true, 0},
+		{"int64", int64(1), 8},
+		{"float64", 1.5, 8},
+		{"string", "abc", sizeString + 3},
+		{"string-empty", "", sizeString},
+		{"bytes", make([]byte, 2, 10), sizeSlice + 10},
+		{"bytes-nil", []byte(nil), sizeSlice},
+		{"time", time.Now(), sizeTime},
+		{"other", int32(1), 4},
+		{"struct", struct{ A, B int64 }{}, 16},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			if g, w := valueSizeBytes(item.Value), item.Want; g != w {
+				t.Fatalf("got %d want %d", g, w)
+			}
+		})
+	}
+}
+
+func TestSizeBytes(t *testing.T) {
+	var nilBuffer *Buffer
+	if g := nilBuffer.SizeBytes(); g != 0 {
+		t.Fatalf("nil buffer got %d want 0", g)
+	}
+
+	empty := &Buffer{}
+	if g, w := empty.SizeBytes(), sizeBuffer; g != w {
+		t.Fatalf("empty buffer got %d want %d", g, w)
+	}
+
+	b := &Buffer{Columns: []string{"ID", "Name"}}
+	b.AddRow([]any{int64(1), "abc"})
+	b.AddRow([]any{nil, []byte("xy")})
+	columns := 2*sizeString + int64(len("ID")+len("Name")) + 2*sizeMapEntry
+	rows := int64(cap(b.Rows))*sizeRow +
+		2*sizeInterface + 8 + sizeString + 3 +
+		2*sizeInterface + 0 + sizeSlice + int64(cap(b.Rows[1].Field[1].([]byte)))
+	if g, w := b.SizeBytes(), sizeBuffer+columns+rows; g != w {
+		t.Fatalf("buffer got %d want %d", g, w)
+	}
+
+	// Larger values are counted by their contents.
+	before := b.SizeBytes()
+	b.Rows[0].Field[1] = "abcdef"
+	if g, w := b.SizeBytes()-before, int64(3); g != w {
+		t.Fatalf("growth got %d want %d", g, w)
+	}
+
+	set := Set{b, empty}
+	if g, w := set.SizeBytes(), 2*int64(unsafe.Sizeof(b))+b.SizeBytes()+empty.SizeBytes(); g != w {
+		t.Fatalf("set got %d want %d", g, w)
+	}
+}
@@ -0,0 +1,496 @@
+package table
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// Snapshot format, all integers are varints unless noted:
+//
+//	magic    "TBLSNAP"
+//	version  byte, 1 or 2 if compressed
+//	codec    byte, only if compressed; the rest is compressed by the codec
+//	columns  count, then each name as length and bytes
+//	rows     count, then each field as a type tag and value; zero without columns
+//
+// Field values keep their exact Go type, and a NULL value, an empty string,
+// and an empty or nil byte slice all remain distinct.
+
+const (
+	snapshotMagic   = "TBLSNAP"
+	snapshotVersion = 1
+
+	snapshotVersionCompressed = 2
+)
+
+// Field type tags. Never re-number these, only add new ones.
+const (
+	tagNil byte = iota
+	tagInt64
+	tagFloat64
+	tagFalse
+	tagTrue
+	tagString
+	tagBytes
+	tagBytesNil
+	tagTime
+)
+
+// ErrSnapshotFormat is returned when reading data that is not a valid snapshot.
+var ErrSnapshotFormat = errors.New("invalid table snapshot")
+
+// SnapshotOption configures how WriteSnapshot writes a snapshot.
+type SnapshotOption func(*snapshotConfig)
+
+type snapshotConfig struct {
+	codec       Codec
+	canonical   bool
+	sortColumns bool
+}
+
+// WriteSnapshot writes the buffer in a compact binary format that can be read
+// back by ReadSnapshot with all column names, value types, and values intact.
+// Supported field types are nil, int64, float64, bool, string, []byte, and time.Time.
+// A time.Time keeps its instant and zone offset, but not the zone name.
+// Rows on disk, such as from SpillToDisk or OpenSnapshot, are included.
+func (b *Buffer) WriteSnapshot(w io.Writer, opts ...SnapshotOption) error {
+	c := &snapshotConfig{}
+	for _, o := range opts {
+		o(c)
+	}
+	if c.codec == CodecNone {
+		sw := &snapshotWriter{w: bufio.NewWriter(w)}
+		sw.raw([]byte(snapshotMagic))
+		sw.raw([]byte{snapshotVersion})
+		if err := sw.body(b, c); err != nil {
+			return err
+		}
+		return sw.w.Flush()
+	}
+
+	cd, err := c.codec.lookup()
+	if err != nil {
+		return err
+	}
+	header := append([]byte(snapshotMagic), snapshotVersionCompressed, byte(c.codec))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	cw, err := cd.newWriter(w)
+	if err != nil {
+		return err
+	}
+	sw := &snapshotWriter{w: bufio.NewWriter(cw)}
+	if err := sw.body(b, c); err != nil {
+		cw.Close()
+		return err
+	}
+	if err := sw.w.Flush(); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// body writes the columns and rows of b.
+func (sw *snapshotWriter) body(b *Buffer, c *snapshotConfig) error {
+	columns := b.Columns
+	var order []int
+	if c.sortColumns {
+		columns, order = sortedColumns(b.Columns)
+	}
+	if len(columns) == 0 && b.Len() > 0 {
+		return fmt.Errorf("%d rows without columns", b.Len())
+	}
+	sw.columns(columns)
+	sw.uvarint(uint64(b.Len()))
+	var field []any
+	err := b.Each(func(ri int, row Row) error {
+		if c.canonical || order != nil {
+			var err error
+			field, err = canonicalFields(field, columns, order, c.canonical, ri, row)
+			if err != nil {
+				return err
+			}
+			row = Row{Field: field}
+		}
+		if err := sw.row(columns, ri, row); err != nil {
+			return err
+		}
+		return sw.err
+	})
+	if err != nil {
+		return err
+	}
+	return sw.err
+}
+
+// ReadSnapshot reads a buffer written by WriteSnapshot, compressed or not.
+// A snapshot compressed as a whole by a registered codec, rather then with
+// the Compression option, is also detected and read.
+func ReadSnapshot(r io.Reader) (*Buffer, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		b := bufio.NewReader(r)
+		br, r = b, b
+	}
+	sr := &snapshotReader{r: r, br: br}
+	defer sr.close()
+	b, rowCount, err := sr.header()
+	if err != nil {
+		return nil, err
+	}
+	if err := sr.rows(b, rowCount); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// rows reads rowCount rows into b.
+func (sr *snapshotReader) rows(b *Buffer, rowCount int) error {
+	b.Rows = make([]Row, 0, min(rowCount, maxPrealloc))
+	for i := 0; i < rowCount && sr.err == nil; i++ {
+		field := make([]any, len(b.Columns))
+		for fi := range field {
+			field[fi] = sr.value()
+		}
+		b.Rows = append(b.Rows, Row{
+			columnNameIndex: b.columnNameIndex,
+			Field:           field,
+		})
+	}
+	return sr.err
+}
+
+// header reads the snapshot header, returning a buffer with the columns and
+// the number of rows that follow. For a compressed snapshot, sr is switched
+// to reading the decompressed data.
+func (sr *snapshotReader) header() (*Buffer, int, error) {
+	magic := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(sr.r, magic); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrSnapshotFormat, err)
+	}
+	if string(magic[:len(snapshotMagic)]) != snapshotMagic {
+		// Detect a snapshot compressed as a whole.
+		cd := detectCodec(magic)
+		if cd == nil || sr.rc != nil {
+			return nil, 0, ErrSnapshotFormat
+		}
+		if err := sr.decompress(cd, io.MultiReader(bytes.NewReader(magic), sr.r)); err != nil {
+			return nil, 0, err
+		}
+		return sr.header()
+	}
+	switch v := magic[len(snapshotMagic)]; v {
+	default:
+		return nil, 0, fmt.Errorf("%w: unsupported version %d", ErrSnapshotFormat, v)
+	case snapshotVersion:
+	case snapshotVersionCompressed:
+		c, err := sr.br.ReadByte()
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", ErrSnapshotFormat, err)
+		}
+		cd, err := Codec(c).lookup()
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := sr.decompress(cd, sr.r); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	colCount := sr.count()
+	b := &Buffer{
+		Columns: make([]string, 0, min(colCount, maxPrealloc)),
+	}
+	for i := 0; i < colCount && sr.err == nil; i++ {
+		b.Columns = append(b.Columns, string(sr.bytes()))
+	}
+	b.buildIndex()
+
+	rowCount := sr.count()
+	if sr.err != nil {
+		return nil, 0, sr.err
+	}
+	// Rows without columns take no space, so their count can not be trusted.
+	if colCount == 0 && rowCount > 0 {
+		return nil, 0, fmt.Errorf("%w: %d rows without columns", ErrSnapshotFormat, rowCount)
+	}
+	return b, rowCount, nil
+}
+
+// decompress switches sr to reading the data of r decompressed by cd.
+func (sr *snapshotReader) decompress(cd *codec, r io.Reader) error {
+	rc, err := cd.newReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSnapshotFormat, err)
+	}
+	br := bufio.NewReader(rc)
+	sr.r, sr.br, sr.rc = br, br, rc
+	return nil
+}
+
+// close releases the decompressor, if any.
+func (sr *snapshotReader) close() {
+	if sr.rc != nil {
+		sr.rc.Close()
+	}
+}
+
+// OpenSnapshot opens a snapshot file written by WriteSnapshot without reading
+// its rows into memory. Rows is empty and Len reports the rows in the file,
+// which are read back as described by SpillToDisk. The buffer must be closed
+// with Close to close the file.
+//
+// A compressed snapshot cannot be read in place, so its rows are read into
+// memory as by ReadSnapshot and the file is closed before returning.
+func OpenSnapshot(path string) (*Buffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	cr := &countReader{r: f}
+	br := bufio.NewReader(cr)
+	sr := &snapshotReader{r: br, br: br}
+	b, rowCount, err := sr.header()
+	if err == nil && sr.rc != nil {
+		err = sr.rows(b, rowCount)
+		sr.close()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	if err != nil {
+		sr.close()
+		f.Close()
+		return nil, err
+	}
+	b.spill = &rowFile{
+		f:       f,
+		count:   rowCount,
+		end:     fi.Size(),
+		columns: len(b.Columns),
+		offsets: []int64{cr.n - int64(br.Buffered())},
+	}
+	return b, nil
+}
+
+type snapshotWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (sw *snapshotWriter) raw(bb []byte) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = sw.w.Write(bb)
+}
+
+func (sw *snapshotWriter) uvarint(v uint64) {
+	sw.raw(sw.buf[:binary.PutUvarint(sw.buf[:], v)])
+}
+
+func (sw *snapshotWriter) varint(v int64) {
+	sw.raw(sw.buf[:binary.PutVarint(sw.buf[:], v)])
+}
+
+func (sw *snapshotWriter) bytes(bb []byte) {
+	sw.uvarint(uint64(len(bb)))
+	sw.raw(bb)
+}
+
+func (sw *snapshotWriter) columns(columns []string) {
+	sw.uvarint(uint64(len(columns)))
+	for _, n := range columns {
+		sw.bytes([]byte(n))
+	}
+}
+
+func (sw *snapshotWriter) row(columns []string, ri int, row Row) error {
+	if len(row.Field) != len(columns) {
+		return fmt.Errorf("row %d has %d fields, expected %d", ri, len(row.Field), len(columns))
+	}
+	for ci, f := range row.Field {
+		if err := sw.value(f); err != nil {
+			return fmt.Errorf("row %d, column %q: %w", ri, columns[ci], err)
+		}
+	}
+	return nil
+}
+
+func (sw *snapshotWriter) value(v any) error {
+	switch v := v.(type) {
+	default:
+		return fmt.Errorf("unsupported snapshot type %T", v)
+	case nil:
+		sw.raw([]byte{tagNil})
+	case int64:
+		sw.raw([]byte{tagInt64})
+		sw.varint(v)
+	case float64:
+		sw.raw([]byte{tagFloat64})
+		binary.LittleEndian.PutUint64(sw.buf[:8], math.Float64bits(v))
+		sw.raw(sw.buf[:8])
+	case bool:
+		if v {
+			sw.raw([]byte{tagTrue})
+		} else {
+			sw.raw([]byte{tagFalse})
+		}
+	case string:
+		sw.raw([]byte{tagString})
+		sw.bytes([]byte(v))
+	case []byte:
+		if v == nil {
+			sw.raw([]byte{tagBytesNil})
+			break
+		}
+		sw.raw([]byte{tagBytes})
+		sw.bytes(v)
+	case time.Time:
+		bb, err := v.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		sw.raw([]byte{tagTime})
+		sw.bytes(bb)
+	}
+	return nil
+}
+
+type snapshotReader struct {
+	r   io.Reader
+	br  io.ByteReader
+	rc  io.ReadCloser // Decompressor of a compressed snapshot.
+	err error
+}
+
+func (sr *snapshotReader) fail(err error) {
+	if sr.err != nil {
+		return
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	sr.err = fmt.Errorf("%w: %w", ErrSnapshotFormat, err)
+}
+
+func (sr *snapshotReader) uvarint() uint64 {
+	if sr.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(sr.br)
+	if err != nil {
+		sr.fail(err)
+	}
+	return v
+}
+
+func (sr *snapshotReader) varint() int64 {
+	if sr.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(sr.br)
+	if err != nil {
+		sr.fail(err)
+	}
+	return v
+}
+
+// maxPrealloc limits an allocation sized by a count read from the input
+// before the data is read, so a corrupt count can not exhaust memory.
+const maxPrealloc = 1 << 16
+
+// count reads a length that is used to size an allocation. Allocate at most
+// maxPrealloc up front and grow as the data is read.
+func (sr *snapshotReader) count() int {
+	v := sr.uvarint()
+	if v > math.MaxInt32 {
+		sr.fail(fmt.Errorf("count %d too large", v))
+		return 0
+	}
+	return int(v)
+}
+
+func (sr *snapshotReader) bytes() []byte {
+	n := sr.count()
+	if sr.err != nil {
+		return nil
+	}
+	if n > maxPrealloc {
+		bb, err := io.ReadAll(io.LimitReader(sr.r, int64(n)))
+		if err == nil && len(bb) != n {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			sr.fail(err)
+		}
+		return bb
+	}
+	bb := make([]byte, n)
+	if _, err := io.ReadFull(sr.r, bb); err != nil {
+		sr.fail(err)
+	}
+	return bb
+}
+
+func (sr *snapshotReader) value() any {
+	if sr.err != nil {
+		return nil
+	}
+	tag, err := sr.br.ReadByte()
+	if err != nil {
+		sr.fail(err)
+		return nil
+	}
+	switch tag {
+	default:
+		sr.fail(fmt.Errorf("unknown type tag %d", tag))
+		return nil
+	case tagNil:
+		return nil
+	case tagInt64:
+		return sr.varint()
+	case tagFloat64:
+		var bb [8]byte
+		if _, err := io.ReadFull(sr.r, bb[:]); err != nil {
+			sr.fail(err)
+			return nil
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(bb[:]))
+	case tagFalse:
+		return false
+	case tagTrue:
+		return true
+	case tagString:
+		return string(sr.bytes())
+	case tagBytes:
+		return sr.bytes()
+	case tagBytesNil:
+		return []byte(nil)
+	case tagTime:
+		var t time.Time
+		if err := t.UnmarshalBinary(sr.bytes()); err != nil {
+			sr.fail(err)
+		}
+		return t
+	}
+}
@@ -0,0 +1,180 @@
+package table
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	ts := time.Date(2024, 2, 3, 4, 5, 6, 7, time.FixedZone("", 3600))
+	b := &Buffer{
+		Columns: []string{"ID", "Name", "Data", "Price", "OK", "At"},
+	}
+	b.AddRow([]any{int64(-1), "", []byte{}, float64(1.5), true, ts})
+	b.AddRow([]any{int64(1 << 40), nil, []byte(nil), nil, false, nil})
+	b.AddRow([]any{nil, "R3", []byte("x"), float64(-0.25), nil, time.Time{}})
+
+	var buf bytes.Buffer
+	if err := b.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	got, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%#v", got.Columns), fmt.Sprintf("%#v", b.Columns); g != w {
+		t.Fatalf("columns got %s want %s", g, w)
+	}
+	for i := range b.Rows {
+		if g, w := fmt.Sprintf("%#v", got.Rows[i].Field), fmt.Sprintf("%#v", b.Rows[i].Field); g != w {
+			t.Fatalf("row %d got:\n%s\nwant:\n%s", i, g, w)
+		}
+	}
+	if g := got.Get(2, "Name"); g != "R3" {
+		t.Fatalf("get by name got %v", g)
+	}
+
+	_, err = ReadSnapshot(bytes.NewReader(encoded[:len(encoded)-3]))
+	if !errors.Is(err, ErrSnapshotFormat) {
+		t.Fatalf("expected format error for truncated input, got %v", err)
+	}
+	_, err = ReadSnapshot(bytes.NewReader([]byte("not a snapshot")))
+	if !errors.Is(err, ErrSnapshotFormat) {
+		t.Fatalf("expected format error for bad magic, got %v", err)
+	}
+
+	b.AddRow([]any{int32(1), nil, nil, nil, nil, nil})
+	err = b.WriteSnapshot(&bytes.Buffer{})
+	if g, w := fmt.Sprint(err), `row 3, column "ID": unsupported snapshot type int32`; g != w {
+		t.Fatalf("got error %q want %q", g, w)
+	}
+}
+
+// TestSnapshotCount checks that a corrupt count is an error rather then an
+// allocation of its size.
+func TestSnapshotCount(t *testing.T) {
+	huge := binary.AppendUvarint(nil, 2e9)
+	header := []byte(snapshotMagic + "\x01")
+	list := []struct {
+		Name  string
+		Data  []byte
+		Error string
+	}{
+		{Name: "columns", Data: append(append(header, huge...), 1, 'A'), Error: "invalid table snapshot: unexpected EOF"},
+		{Name: "rows", Data: append(append(header, 1, 1, 'A'), huge...), Error: "invalid table snapshot: unexpected EOF"},
+		{Name: "rows-without-columns", Data: append(append(header, 0), huge...), Error: "invalid table snapshot: 2000000000 rows without columns"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			_, err := ReadSnapshot(bytes.NewReader(item.Data))
+			if g, w := fmt.Sprint(err), item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+		})
+	}
+
+	var p Patch
+	err := p.UnmarshalBinary(append([]byte(patchMagic+"\x01"), huge...))
+	if g, w := fmt.Sprint(err), "invalid table snapshot: unexpected EOF"; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+
+	b := &Buffer{Rows: []Row{{}}}
+	err = b.WriteSnapshot(&bytes.Buffer{})
+	if g, w := fmt.Sprint(err), "1 rows without columns"; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+}
+
+func TestOpenSnapshot(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name"},
+	}
+	for i := 0; i < 5; i++ {
+		b.AddRow([]any{int64(i), fmt.Sprintf("R%d", i)})
+	}
+	var buf bytes.Buffer
+	if err := b.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "t.snap")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := OpenSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got.Columns), "[ID Name]"; g != w {
+		t.Fatalf("columns got %s want %s", g, w)
+	}
+	if g, w := fmt.Sprint(len(got.Rows), got.Len(), got.Spilled()), "0 5 5"; g != w {
+		t.Fatalf("rows, len, spilled got %s want %s", g, w)
+	}
+	for _, i := range []int{3, 1, 4, 0} {
+		if g, w := got.Get(i, "Name"), fmt.Sprintf("R%d", i); g != w {
+			t.Fatalf("row %d got %v want %s", i, g, w)
+		}
+	}
+	_, err = got.Row(5)
+	if !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected error: %s, got error: %s", ErrNoRows, err)
+	}
+	var names []string
+	err = got.Each(func(i int, row Row) error {
+		names = append(names, fmt.Sprint(i, row.Get("Name")))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := strings.Join(names, ","), "0R0,1R1,2R2,3R3,4R4"; g != w {
+		t.Fatalf("each got %s want %s", g, w)
+	}
+	var again bytes.Buffer
+	if err := got.WriteSnapshot(&again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Bytes(), buf.Bytes()) {
+		t.Fatal("snapshot of opened snapshot differs")
+	}
+	if err := got.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot file removed on close: %v", err)
+	}
+
+	truncated := filepath.Join(dir, "truncated.snap")
+	if err := os.WriteFile(truncated, buf.Bytes()[:buf.Len()-3], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = OpenSnapshot(truncated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+	_, err = got.Row(4)
+	if !errors.Is(err, ErrSnapshotFormat) {
+		t.Fatalf("expected error: %s, got error: %s", ErrSnapshotFormat, err)
+	}
+
+	bad := filepath.Join(dir, "bad.snap")
+	if err := os.WriteFile(bad, []byte("not a snapshot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = OpenSnapshot(bad)
+	if !errors.Is(err, ErrSnapshotFormat) {
+		t.Fatalf("expected error: %s, got error: %s", ErrSnapshotFormat, err)
+	}
+}
@@ -0,0 +1,261 @@
+package table
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// SpillToDisk keeps the rows of each buffer in memory until their estimated
+// size, as by SizeBytes, reaches memBytes, then writes the remaining rows of
+// the result set to a temporary file in dir, or os.TempDir if dir is empty.
+// Spilled rows are not counted by MaxBytes.
+//
+// Only the rows held in memory are in Buffer.Rows. Methods that read the
+// buffer, such as Each, Row, WriteCSV, Filter, and BufferToStruct, read the
+// spilled rows back from the file. Methods that modify the buffer or need
+// every row in memory, such as Coalesce, Diff, and Merge, return ErrSpilled.
+// Close the buffer to remove the file. Field types are limited to those
+// supported by WriteSnapshot.
+func SpillToDisk(memBytes int64, dir string) Option {
+	return func(c *fillConfig) {
+		c.spillBytes = memBytes
+		c.spillDir = dir
+	}
+}
+
+// ErrSpilled is returned, or used as the panic value, when a buffer with rows
+// on disk is modified or passed to a method that needs every row in memory.
+var ErrSpilled = errors.New("buffer has rows on disk")
+
+// rowFile holds rows of a buffer on disk in the snapshot row encoding, either
+// spilled by SpillToDisk or in a file opened by OpenSnapshot.
+type rowFile struct {
+	f       *os.File
+	temp    bool  // Remove the file on close.
+	count   int   // Rows in the file.
+	end     int64 // End of the row data.
+	columns int
+
+	mu      sync.Mutex // Guards offsets and flushing sw.
+	offsets []int64    // File offset of each row indexed so far.
+
+	// Set when writing spilled rows.
+	cw *countWriter
+	sw *snapshotWriter
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(bb []byte) (int, error) {
+	n, err := cw.w.Write(bb)
+	cw.n += int64(n)
+	return n, err
+}
+
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countReader) Read(bb []byte) (int, error) {
+	n, err := cr.r.Read(bb)
+	cr.n += int64(n)
+	return n, err
+}
+
+func newSpillFile(dir string, columns int) (*rowFile, error) {
+	f, err := os.CreateTemp(dir, "table-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	cw := &countWriter{w: f}
+	return &rowFile{
+		f:       f,
+		temp:    true,
+		columns: columns,
+		cw:      cw,
+		sw:      &snapshotWriter{w: bufio.NewWriter(cw)},
+	}, nil
+}
+
+// add writes a row to the end of a spill file.
+func (rf *rowFile) add(columns []string, ri int, row Row) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	rf.offsets = append(rf.offsets, rf.cw.n+int64(rf.sw.w.Buffered()))
+	if err := rf.sw.row(columns, ri, row); err != nil {
+		return err
+	}
+	if rf.sw.err != nil {
+		return rf.sw.err
+	}
+	rf.count++
+	rf.end = rf.cw.n + int64(rf.sw.w.Buffered())
+	return nil
+}
+
+// reader returns a reader of the rows starting at row i. Each reader reads
+// the file at its own offset, so readers may be used concurrently.
+func (rf *rowFile) reader(i int) (*snapshotReader, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.sw != nil && rf.sw.w.Buffered() > 0 {
+		if err := rf.sw.w.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	if err := rf.index(i); err != nil {
+		return nil, err
+	}
+	start := rf.offsets[i]
+	br := bufio.NewReader(io.NewSectionReader(rf.f, start, rf.end-start))
+	return &snapshotReader{r: br, br: br}, nil
+}
+
+// index finds the offsets of the rows up to row i, reading the rows after
+// the last row indexed. The caller must hold rf.mu.
+func (rf *rowFile) index(i int) error {
+	if i < len(rf.offsets) {
+		return nil
+	}
+	last := len(rf.offsets) - 1
+	start := rf.offsets[last]
+	cr := &countReader{r: io.NewSectionReader(rf.f, start, rf.end-start)}
+	br := bufio.NewReader(cr)
+	sr := &snapshotReader{r: br, br: br}
+	for len(rf.offsets) <= i {
+		if _, err := sr.fields(rf.columns); err != nil {
+			return err
+		}
+		rf.offsets = append(rf.offsets, start+cr.n-int64(br.Buffered()))
+	}
+	return nil
+}
+
+// fields reads the n fields of a row.
+func (sr *snapshotReader) fields(n int) ([]any, error) {
+	field := make([]any, n)
+	for i := range field {
+		field[i] = sr.value()
+	}
+	return field, sr.err
+}
+
+func (rf *rowFile) close() error {
+	err := rf.f.Close()
+	if rf.temp {
+		if rerr := os.Remove(rf.f.Name()); err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// Len returns the number of rows, including rows on disk.
+func (b *Buffer) Len() int {
+	return len(b.Rows) + b.Spilled()
+}
+
+// Spilled returns the number of rows read from disk rather then held in
+// memory, such as rows spilled by SpillToDisk or in a buffer from OpenSnapshot.
+func (b *Buffer) Spilled() int {
+	if b.spill == nil {
+		return 0
+	}
+	return b.spill.count
+}
+
+// Row returns the row at index i, reading it from disk if it is not in memory.
+func (b *Buffer) Row(i int) (Row, error) {
+	if i >= 0 && i < len(b.Rows) {
+		return b.Rows[i], nil
+	}
+	if i < 0 || i >= b.Len() {
+		return Row{}, &IndexError{subject: SubjectRow, length: b.Len(), requested: i}
+	}
+	sr, err := b.spill.reader(i - len(b.Rows))
+	if err != nil {
+		return Row{}, err
+	}
+	field, err := sr.fields(len(b.Columns))
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{columnNameIndex: b.columnNameIndex, Field: field}, nil
+}
+
+// Each calls fn with every row in order, including rows on disk, until fn
+// returns an error. Rows on disk are read sequentially.
+func (b *Buffer) Each(fn func(i int, row Row) error) error {
+	for i, row := range b.Rows {
+		if err := fn(i, row); err != nil {
+			return err
+		}
+	}
+	if b.Spilled() == 0 {
+		return nil
+	}
+	sr, err := b.spill.reader(0)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < b.spill.count; i++ {
+		field, err := sr.fields(len(b.Columns))
+		if err != nil {
+			return err
+		}
+		if err := fn(len(b.Rows)+i, Row{columnNameIndex: b.columnNameIndex, Field: field}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachRow calls fn with every row as Each does, for methods that do not
+// return an error. It panics if the rows on disk cannot be read.
+func (b *Buffer) eachRow(fn func(i int, row Row)) {
+	err := b.Each(func(i int, row Row) error {
+		fn(i, row)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// inMemory returns ErrSpilled if some rows of the buffer are on disk.
+func (b *Buffer) inMemory() error {
+	if b.spill != nil {
+		return ErrSpilled
+	}
+	return nil
+}
+
+// Close closes the file of rows on disk, if any, removing it if the rows
+// were spilled by SpillToDisk. The rows on disk are no longer available
+// afterwards.
+func (b *Buffer) Close() error {
+	if b.spill == nil {
+		return nil
+	}
+	err := b.spill.close()
+	b.spill = nil
+	return err
+}
+
+// close closes every buffer of the set, for callers that drop the set.
+func (s Set) close() {
+	for _, b := range s {
+		if b != nil {
+			b.Close()
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// spillResult returns a result of n rows with ID and Name columns.
+func spillResult(n int64) fakeResult {
+	res := fakeResult{Columns: []string{"ID", "Name"}}
+	for i := int64(0); i < n; i++ {
+		res.Rows = append(res.Rows, []driver.Value{i, fmt.Sprint("R", i)})
+	}
+	return res
+}
+
+// spilledBuffer returns a buffer of the result with most rows on disk.
+func spilledBuffer(t *testing.T, res fakeResult) *Buffer {
+	t.Helper()
+	b, err := FillResultSet(context.Background(), fakeRows(t, res), SpillToDisk(200, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Spilled() == 0 {
+		t.Fatal("expected rows on disk")
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestSpillToDisk(t *testing.T) {
+	res := spillResult(100)
+	dir := t.TempDir()
+	rows := fakeRows(t, res)
+	set, err := FillSetOpt(context.Background(), rows, SpillToDisk(2000, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := set[0]
+	if b.Spilled() == 0 || len(b.Rows) == 0 {
+		t.Fatalf("expected rows in memory and on disk, got %d and %d", len(b.Rows), b.Spilled())
+	}
+	if g, w := b.Len(), 100; g != w {
+		t.Fatalf("expected %d rows, got %d", w, g)
+	}
+
+	for _, i := range []int{0, len(b.Rows) - 1, len(b.Rows), 99, 42} {
+		row, err := b.Row(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, w := fmt.Sprint(row.Get("ID"), row.Get("Name")), fmt.Sprint(i, "R", i); g != w {
+			t.Fatalf("row %d: got %s, want %s", i, g, w)
+		}
+	}
+	if g := b.Get(99, "Name"); g != "R99" {
+		t.Fatalf("expected R99, got %v", g)
+	}
+	if _, err := b.At(100, 0); err == nil || err.Error() != "Table has 100 rows, requested index 100" {
+		t.Fatalf("expected index error, got error: %v", err)
+	}
+
+	var n int
+	err = b.Each(func(i int, row Row) error {
+		if row.Get("ID") != int64(i) {
+			return fmt.Errorf("row %d has ID %v", i, row.Get("ID"))
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 100 {
+		t.Fatalf("expected 100 rows, got %d", n)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected spill file to be removed, got %d files", len(files))
+	}
+}
+
+func TestSpillRead(t *testing.T) {
+	res := spillResult(100)
+	mem, err := FillResultSet(context.Background(), fakeRows(t, res))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spilled := spilledBuffer(t, res)
+
+	type S struct {
+		ID   int64
+		Name string
+	}
+	list := []struct {
+		Name string
+		Read func(b *Buffer) (any, error)
+	}{
+		{"WriteCSV", func(b *Buffer) (any, error) {
+			var buf bytes.Buffer
+			err := b.WriteCSV(&buf)
+			return buf.String(), err
+		}},
+		{"WriteTSV", func(b *Buffer) (any, error) {
+			var buf bytes.Buffer
+			err := b.WriteTSV(&buf)
+			return buf.String(), err
+		}},
+		{"WriteFixedWidth", func(b *Buffer) (any, error) {
+			var buf bytes.Buffer
+			err := b.WriteFixedWidth(&buf, map[string]int{"ID": 3, "Name": 4})
+			return buf.String(), err
+		}},
+		{"JSON", func(b *Buffer) (any, error) {
+			bb, err := json.Marshal(b)
+			return string(bb), err
+		}},
+		{"XML", func(b *Buffer) (any, error) {
+			bb, err := xml.Marshal(b)
+			return string(bb), err
+		}},
+		{"YAML", func(b *Buffer) (any, error) {
+			return YAMLBuffer{Buffer: b}.MarshalYAML()
+		}},
+		{"YAML-maps", func(b *Buffer) (any, error) {
+			return YAMLBuffer{Buffer: b, Maps: true}.MarshalYAML()
+		}},
+		{"BufferToStruct", func(b *Buffer) (any, error) {
+			return BufferToStruct[S](b)
+		}},
+		{"Hash", func(b *Buffer) (any, error) {
+			return b.Hash()
+		}},
+		{"HashUnordered", func(b *Buffer) (any, error) {
+			return b.HashUnordered()
+		}},
+		{"GoCode", func(b *Buffer) (any, error) {
+			return b.GoCode("b")
+		}},
+		{"Validate", func(b *Buffer) (any, error) {
+			return NewValidator().Range("ID", 0, 10).Validate(b)
+		}},
+		{"Filter", func(b *Buffer) (any, error) {
+			return b.Filter(func(row Row) bool { return row.Get("ID").(int64)%7 == 0 }).Rows, nil
+		}},
+		{"Sort", func(b *Buffer) (any, error) {
+			return b.Sort(func(x, y Row) bool { return x.Get("Name").(string) < y.Get("Name").(string) }).Rows, nil
+		}},
+		{"Select", func(b *Buffer) (any, error) {
+			d, err := b.Select("Name")
+			if err != nil {
+				return nil, err
+			}
+			return d.Rows, nil
+		}},
+		{"Columnar", func(b *Buffer) (any, error) {
+			return b.Columnar().Len(), nil
+		}},
+		{"Schema", func(b *Buffer) (any, error) {
+			return b.Schema(), nil
+		}},
+		{"SafeBuffer", func(b *Buffer) (any, error) {
+			s := NewSafeBuffer(b)
+			row, err := s.Row(99)
+			return []any{s.Len(), row.Field, s.Buffer().Rows}, err
+		}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			want, err := item.Read(mem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := item.Read(spilled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := fmt.Sprint(got), fmt.Sprint(want); g != w {
+				t.Fatalf("got\n%.200s\nwant\n%.200s", g, w)
+			}
+		})
+	}
+}
+
+func TestSpillInMemoryOnly(t *testing.T) {
+	b := spilledBuffer(t, spillResult(100))
+	other := spilledBuffer(t, spillResult(100))
+	list := []struct {
+		Name string
+		Call func() error
+	}{
+		{"Coalesce", func() error { return b.Coalesce("Name", "") }},
+		{"CoalesceColumns", func() error { return b.CoalesceColumns(map[string]any{"Name": ""}) }},
+		{"Mask", func() error { return b.Mask(map[string]MaskFunc{"Name": MaskFixed("x")}) }},
+		{"Set", func() error { return b.Set(0, "Name", "x") }},
+		{"DeleteRow", func() error { return b.DeleteRow(0) }},
+		{"Diff", func() error { _, err := b.Diff(other, "ID"); return err }},
+		{"Merge", func() error { _, err := Merge(nil, b, other, []string{"ID"}, nil); return err }},
+		{"HashIndex", func() error { _, err := b.HashIndex("ID"); return err }},
+		{"AddRow", func() (err error) {
+			defer func() { err, _ = recover().(error) }()
+			b.AddRow([]any{int64(100), "R100"})
+			return nil
+		}},
+		{"View", func() (err error) {
+			defer func() { err, _ = recover().(error) }()
+			b.View()
+			return nil
+		}},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			if err := item.Call(); !errors.Is(err, ErrSpilled) {
+				t.Fatalf("expected error: %v, got error: %v", ErrSpilled, err)
+			}
+			if g := b.Len(); g != 100 {
+				t.Fatalf("expected 100 rows, got %d", g)
+			}
+		})
+	}
+}
+
+func TestSpillQueryClose(t *testing.T) {
+	dir := t.TempDir()
+	db := openFake(t, fakeSet(spillResult(100), spillResult(10)))
+	type S struct {
+		ID   int64
+		Name string
+	}
+	list, err := QueryStruct[S](context.Background(), db, "select", SpillToDisk(200, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := len(list); g != 100 {
+		t.Fatalf("expected 100 items, got %d", g)
+	}
+	if g, w := fmt.Sprint(list[99]), "{99 R99}"; g != w {
+		t.Fatalf("got %s, want %s", g, w)
+	}
+	row, err := NewRow(context.Background(), db, "select", SpillToDisk(1, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(row.Field), "[0 R0]"; g != w {
+		t.Fatalf("got %s, want %s", g, w)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected spill files to be removed, got %d files", len(files))
+	}
+}
+
+func TestSpillConcurrentRead(t *testing.T) {
+	b := spilledBuffer(t, spillResult(100))
+	b.Freeze()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 99 - g; i >= 0; i -= 8 {
+				row, err := b.Row(i)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if row.Get("ID") != int64(i) {
+					errs <- fmt.Errorf("row %d has ID %v", i, row.Get("ID"))
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestSpillRowError(t *testing.T) {
+	errBad := errors.New("bad value")
+	conv := func(col Column, v any) (any, error) {
+		if v == int64(90) {
+			return nil, errBad
+		}
+		return v, nil
+	}
+	b, err := FillResultSet(context.Background(), fakeRows(t, spillResult(100)), SpillToDisk(200, t.TempDir()), WithConverter(conv))
+	if b != nil {
+		defer b.Close()
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || !errors.Is(err, errBad) {
+		t.Fatalf("expected field error, got error: %v", err)
+	}
+	if fe.Row != 90 {
+		t.Fatalf("expected an error for row 90, got %v", err)
+	}
+}
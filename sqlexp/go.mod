@@ -0,0 +1,9 @@
+module github.com/golang-sql/table/sqlexp
+
+go 1.21
+
+require github.com/golang-sql/table v0.0.0
+
+require github.com/golang-sql/sqlexp v0.1.0
+
+replace github.com/golang-sql/table => ../
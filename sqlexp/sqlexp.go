@@ -0,0 +1,133 @@
+// Package tablesqlexp reads query results with the driver messages of
+// github.com/golang-sql/sqlexp, so statements in a batch that do not return
+// rows are reported with their rows affected.
+//
+// Only github.com/microsoft/go-mssqldb sends these messages. With other
+// drivers the query fails on the message argument or NewSet returns
+// ErrNoMessages; use table.NewSet with them.
+package tablesqlexp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-sql/sqlexp"
+	"github.com/golang-sql/table"
+)
+
+// ErrNoMessages is returned by NewSet when the driver accepted the query
+// without taking the sqlexp message queue, so no messages would be sent.
+var ErrNoMessages = errors.New("driver does not send sqlexp messages")
+
+// NewSet is like table.NewSet, also recording statement results on the set
+// with Buffer.SetResult. Each statement that does not return rows, such as
+// an UPDATE, adds a buffer without columns to the set whose Result reports
+// the rows affected, and the last insert ID if the driver sends it. A result
+// set followed by a count of its rows has it recorded on its own buffer.
+//
+// SQL errors sent by the driver as messages do not stop later statements;
+// they are returned joined once every result has been read. A driver that
+// does not take the message queue returns ErrNoMessages.
+func NewSet(ctx context.Context, q table.Queryer, sql string, params ...any) (table.Set, error) {
+	var opts []table.Option
+	args := make([]any, 0, len(params)+1)
+	for _, p := range params {
+		if o, ok := p.(table.Option); ok {
+			opts = append(opts, o)
+			continue
+		}
+		args = append(args, p)
+	}
+	// A driver taking the message queue initializes it, so initialize it
+	// here first to tell if the driver did so again.
+	msgs := &sqlexp.ReturnMessage{}
+	sqlexp.ReturnMessageInit(msgs)
+	untaken := *msgs
+	rows, err := q.QueryContext(ctx, sql, append(args, msgs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if *msgs == untaken {
+		return nil, ErrNoMessages
+	}
+
+	var (
+		set     table.Set
+		errs    []error
+		pending *table.Buffer // Result set that may be followed by its count.
+		last    *result       // Result of the last statement.
+	)
+	statement := func(r *result) {
+		b := &table.Buffer{}
+		b.SetResult(r)
+		set = append(set, b)
+		last = r
+	}
+	for {
+		switch m := msgs.Message(ctx).(type) {
+		case sqlexp.MsgNext:
+			b, err := table.FillResultSet(ctx, rows, opts...)
+			set = append(set, b)
+			if err != nil {
+				return set, err
+			}
+			pending = b
+		case sqlexp.MsgRowsAffected:
+			r := &result{rowsAffected: m.Count}
+			if pending != nil {
+				pending.SetResult(r)
+				pending = nil
+				last = r
+				continue
+			}
+			statement(r)
+		case sqlexp.MsgLastInsertID:
+			pending = nil
+			if last == nil {
+				statement(&result{rowsAffected: -1})
+			}
+			last.lastInsertID, last.hasLastInsertID = m.Value, true
+		case sqlexp.MsgError:
+			errs = append(errs, m.Error)
+		case sqlexp.MsgNextResultSet:
+			pending = nil
+			last = nil
+			if !rows.NextResultSet() {
+				if err := ctx.Err(); err != nil {
+					errs = append(errs, err)
+				}
+				if err := rows.Err(); err != nil {
+					errs = append(errs, err)
+				}
+				return set, errors.Join(errs...)
+			}
+		}
+	}
+}
+
+// result is the sql.Result of a statement.
+type result struct {
+	rowsAffected    int64 // -1 if not sent.
+	lastInsertID    any
+	hasLastInsertID bool
+}
+
+func (r *result) RowsAffected() (int64, error) {
+	if r.rowsAffected < 0 {
+		return 0, errors.New("rows affected not reported")
+	}
+	return r.rowsAffected, nil
+}
+
+func (r *result) LastInsertId() (int64, error) {
+	if !r.hasLastInsertID {
+		return 0, errors.New("last insert ID not reported")
+	}
+	id, ok := r.lastInsertID.(int64)
+	if !ok {
+		return 0, fmt.Errorf("last insert ID %v is a %T, not int64", r.lastInsertID, r.lastInsertID)
+	}
+	return id, nil
+}
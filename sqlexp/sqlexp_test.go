@@ -0,0 +1,173 @@
+package tablesqlexp
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang-sql/sqlexp"
+)
+
+// fakeConnector answers every query with the result sets and messages.
+type fakeConnector struct {
+	sets [][][]driver.Value // First row of each set holds the column names.
+	msgs []sqlexp.RawMessage
+
+	ignore bool // Accept the message queue as an argument and send nothing.
+}
+
+func (fc *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{fc: fc}, nil }
+func (fc *fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	fc   *fakeConnector
+	msgs *sqlexp.ReturnMessage
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("no transactions") }
+
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if m, ok := nv.Value.(*sqlexp.ReturnMessage); ok && !c.fc.ignore {
+		sqlexp.ReturnMessageInit(m)
+		c.msgs = m
+		return driver.ErrRemoveArgument
+	}
+	return nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.msgs == nil {
+		return &fakeRows{sets: c.fc.sets}, nil
+	}
+	for _, m := range c.fc.msgs {
+		if err := sqlexp.ReturnMessageEnqueue(ctx, c.msgs, m); err != nil {
+			return nil, err
+		}
+	}
+	return &fakeRows{sets: c.fc.sets}, nil
+}
+
+type fakeRows struct {
+	sets [][][]driver.Value
+	set  int
+	row  int
+}
+
+func (r *fakeRows) Columns() []string {
+	var cols []string
+	for _, v := range r.sets[r.set][0] {
+		cols = append(cols, v.(string))
+	}
+	return cols
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	rows := r.sets[r.set][1:]
+	if r.row >= len(rows) {
+		return io.EOF
+	}
+	copy(dest, rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *fakeRows) HasNextResultSet() bool { return r.set+1 < len(r.sets) }
+
+func (r *fakeRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.row = 0
+	return nil
+}
+
+func TestNewSet(t *testing.T) {
+	list := []struct {
+		Name  string
+		Sets  [][][]driver.Value
+		Msgs  []sqlexp.RawMessage
+		Want  string
+		Error string
+	}{
+		{
+			Name: "batch",
+			Sets: [][][]driver.Value{
+				{{"ID"}, {int64(1)}, {int64(2)}},
+				{{"Name"}, {"a"}},
+			},
+			Msgs: []sqlexp.RawMessage{
+				sqlexp.MsgNext{},
+				sqlexp.MsgRowsAffected{Count: 2},
+				sqlexp.MsgRowsAffected{Count: 5},
+				sqlexp.MsgLastInsertID{Value: int64(7)},
+				sqlexp.MsgNotice{},
+				sqlexp.MsgNextResultSet{},
+				sqlexp.MsgNext{},
+				sqlexp.MsgNextResultSet{},
+			},
+			Want: "[ID] 2 rows, affected 2 | [] 0 rows, affected 5, id 7 | [Name] 1 rows",
+		},
+		{
+			Name: "error",
+			Sets: [][][]driver.Value{{{"ID"}}},
+			Msgs: []sqlexp.RawMessage{
+				sqlexp.MsgError{Error: errors.New("bad update")},
+				sqlexp.MsgRowsAffected{Count: 0},
+				sqlexp.MsgNextResultSet{},
+			},
+			Want:  "[] 0 rows, affected 0",
+			Error: "bad update",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			db := sql.OpenDB(&fakeConnector{sets: item.Sets, msgs: item.Msgs})
+			defer db.Close()
+
+			set, err := NewSet(context.Background(), db, "batch")
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			var got string
+			for i, b := range set {
+				if i > 0 {
+					got += " | "
+				}
+				got += fmt.Sprintf("%v %d rows", b.Columns, len(b.Rows))
+				if r := b.Result(); r != nil {
+					n, _ := r.RowsAffected()
+					got += fmt.Sprintf(", affected %d", n)
+					if id, err := r.LastInsertId(); err == nil {
+						got += fmt.Sprintf(", id %d", id)
+					}
+				}
+			}
+			if got != item.Want {
+				t.Fatalf("got %s want %s", got, item.Want)
+			}
+		})
+	}
+}
+
+func TestNewSetNoMessages(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{sets: [][][]driver.Value{{{"ID"}}}, ignore: true})
+	defer db.Close()
+
+	_, err := NewSet(context.Background(), db, "batch")
+	if !errors.Is(err, ErrNoMessages) {
+		t.Fatalf("expected error: %v, got error: %v", ErrNoMessages, err)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/golang-sql/table/sqlx
+
+go 1.21
+
+require (
+	github.com/golang-sql/table v0.0.0
+	github.com/jmoiron/sqlx v1.4.0
+)
+
+replace github.com/golang-sql/table => ../
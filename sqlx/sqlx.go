@@ -0,0 +1,58 @@
+// Package tablesqlx lets the table and sqlx packages be used together.
+//
+// *sqlx.DB, *sqlx.Tx, and *sqlx.Conn already satisfy table.Queryer, as they
+// embed their database/sql types. Tag maps struct fields by the sqlx "db"
+// tags, and NamedSet and NamedBuffer run queries with sqlx ":name" parameters.
+package tablesqlx
+
+import (
+	"context"
+
+	"github.com/golang-sql/table"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ table.Queryer = (*sqlx.DB)(nil)
+	_ table.Queryer = (*sqlx.Tx)(nil)
+	_ table.Queryer = (*sqlx.Conn)(nil)
+	_ NamedQueryer  = (*sqlx.DB)(nil)
+	_ NamedQueryer  = (*sqlx.Tx)(nil)
+)
+
+// Tag maps struct fields to columns by their sqlx "db" tags in BufferToStruct,
+// SetToStructs, and the other struct mapping functions, so structs tagged for
+// sqlx need not also be tagged for table.
+func Tag() table.Option {
+	return table.StructTag("db")
+}
+
+// NamedQueryer is a table.Queryer that binds sqlx named queries for its
+// driver, such as *sqlx.DB and *sqlx.Tx.
+type NamedQueryer interface {
+	table.Queryer
+	BindNamed(query string, arg any) (string, []any, error)
+}
+
+// NamedSet is like table.NewSet for a query with sqlx ":name" parameters,
+// taking the values from the fields of the struct or keys of the map arg.
+func NamedSet(ctx context.Context, q NamedQueryer, query string, arg any, opts ...table.Option) (table.Set, error) {
+	text, params, err := q.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range opts {
+		params = append(params, o)
+	}
+	return table.NewSet(ctx, q, text, params...)
+}
+
+// NamedBuffer is like table.NewBuffer for a query with sqlx ":name" parameters,
+// taking the values from the fields of the struct or keys of the map arg.
+func NamedBuffer(ctx context.Context, q NamedQueryer, query string, arg any, opts ...table.Option) (*table.Buffer, error) {
+	set, err := NamedSet(ctx, q, query, arg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return set.First()
+}
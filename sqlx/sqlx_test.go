@@ -0,0 +1,94 @@
+package tablesqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang-sql/table"
+	"github.com/jmoiron/sqlx"
+)
+
+// recordDriver records the last query and returns the same row for every query.
+type recordDriver struct{}
+
+var last struct {
+	query string
+	args  []driver.Value
+}
+
+func (recordDriver) Open(name string) (driver.Conn, error) { return conn{}, nil }
+
+type conn struct{}
+
+func (conn) Prepare(query string) (driver.Stmt, error) { return stmt{query: query}, nil }
+func (conn) Close() error                              { return nil }
+func (conn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type stmt struct{ query string }
+
+func (stmt) Close() error                                    { return nil }
+func (stmt) NumInput() int                                   { return -1 }
+func (stmt) Exec(args []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s stmt) Query(args []driver.Value) (driver.Rows, error) {
+	last.query, last.args = s.query, args
+	return &rows{}, nil
+}
+
+type rows struct{ done bool }
+
+func (r *rows) Columns() []string { return []string{"id", "full_name"} }
+func (r *rows) Close() error      { return nil }
+func (r *rows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0], dest[1] = int64(1), "R1"
+	return nil
+}
+
+func init() {
+	sql.Register("tablesqlx", recordDriver{})
+}
+
+func TestNamedBuffer(t *testing.T) {
+	db, err := sqlx.Open("tablesqlx", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	type Filter struct {
+		Region string `db:"region"`
+		Year   int    `db:"year"`
+	}
+	b, err := NamedBuffer(ctx, db, "select * from T where Region = :region and Year = :year", Filter{Region: "EU", Year: 2024}, table.MaxRows(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%s %v", last.query, last.args), "select * from T where Region = ? and Year = ? [EU 2024]"; g != w {
+		t.Fatalf("query got %q want %q", g, w)
+	}
+
+	type Row struct {
+		ID   int64  `db:"id"`
+		Name string `db:"full_name"`
+	}
+	list, err := table.BufferToStruct[Row](b, Tag())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%+v", list), "[{ID:1 Name:R1}]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	_, err = NamedBuffer(ctx, db, "select :missing", Filter{})
+	if err == nil {
+		t.Fatal("expected error for missing named parameter")
+	}
+}
@@ -0,0 +1,78 @@
+package table
+
+import (
+	"context"
+)
+
+// Number of rows StreamRows buffers in its channel.
+const streamBuffer = 64
+
+// StreamRows runs the query and sends each row of the first result set on the
+// returned row channel from a separate goroutine. Each Row is independent and
+// may be retained. The row channel is closed when the rows are read, the
+// context is canceled, or an error occurs. The error channel then receives
+// at most one error and is closed.
+// Any Option values in params configure the fill and are not sent with the query.
+//
+//	rows, errc := table.StreamRows(ctx, db, "select ID from Account;")
+//	for row := range rows {
+//		// ...
+//	}
+//	if err := <-errc; err != nil {
+//		return err
+//	}
+//
+// The caller must either read the row channel until it is closed or
+// cancel the context.
+func StreamRows(ctx context.Context, q Queryer, sql string, params ...any) (<-chan Row, <-chan error) {
+	rowc := make(chan Row, streamBuffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(rowc)
+
+		err := streamRows(ctx, q, sql, params, rowc)
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return rowc, errc
+}
+
+func streamRows(ctx context.Context, q Queryer, sql string, params []any, rowc chan<- Row) (err error) {
+	rows, opts, qi, err := query(ctx, q, sql, params)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	f := newFiller(ctx, rows, opts)
+	defer func() {
+		qi.finish(err, f.stats)
+	}()
+	table := &Buffer{}
+	first := true
+	for rows.Next() {
+		if first {
+			first = false
+			if err := f.setup(table); err != nil {
+				return err
+			}
+		}
+		out := make([]any, len(table.Columns))
+		if err := f.scan(out); err != nil {
+			return err
+		}
+		f.rowCount++
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case rowc <- Row{columnNameIndex: table.columnNameIndex, Field: out}:
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return incomplete(f.rowCount, err)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,38 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestStreamRows(t *testing.T) {
+	res := fakeResult{Columns: []string{"ID"}}
+	for i := 0; i < streamBuffer*2; i++ {
+		res.Rows = append(res.Rows, []driver.Value{int64(i)})
+	}
+	db := openFake(t, fakeSet(res))
+
+	rows, errc := StreamRows(context.Background(), db, "select")
+	var sum int64
+	for row := range rows {
+		sum += row.Get("ID").(int64)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if n := int64(len(res.Rows)); sum != n*(n-1)/2 {
+		t.Fatalf("got sum %d", sum)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, errc = StreamRows(ctx, db, "select")
+	<-rows
+	cancel()
+	for range rows {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
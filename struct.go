@@ -2,28 +2,159 @@ package table
 
 import (
 	"context"
+	"database/sql"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Copy Buffer into a slice of structs of type T.
 // Names can be provided in `sql:"Name"` field tags. If a field should be ignored, use the `sql:"-"` tag.
-// Pointer to structs or points to fields are not supported.
+// The StructTag option reads the names from another tag, such as "db", and
+// TagFallback also reads "db" and "json" tags when the "sql" tag is not set.
+// A name may be followed by options, such as `sql:"Name,nullzero"`; see ColumnsOf.
 //
-//	TODO: add option to set value converter.
-func BufferToStruct[T any](buf *Buffer) ([]T, error) {
-	list := make([]T, len(buf.Rows))
-	tp := reflect.TypeOf(list).Elem()
+// A string or []byte value is passed to the UnmarshalText method of fields that
+// implement encoding.TextUnmarshaler, such as netip.Addr, or for a []byte, to
+// UnmarshalBinary of an encoding.BinaryUnmarshaler, when it cannot be
+// assigned directly. With the TimeLayouts option, time.Time fields are
+// parsed with the layouts instead.
+// Pointer to structs are not supported.
+//
+// A NULL value sets a pointer field to nil and is passed to the Scan method of
+// fields that implement sql.Scanner, such as sql.NullString. For any other
+// field a NULL value is an error wrapping ErrNull, unless the NullAsZero
+// option is given.
+func BufferToStruct[T any](buf *Buffer, opts ...Option) ([]T, error) {
+	var list []T
+	err := bufferToSlice(buf, reflect.ValueOf(&list).Elem(), newFillConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SetToStructs copies each Buffer in set into the matching destination,
+// which must be a pointer to a slice of structs: result set N is copied into
+// dests[N]. Buffers without a destination are ignored.
+// Any Option values in dests configure the struct mapping.
+func SetToStructs(set Set, dests ...any) error {
+	dests, opts := splitOptions(dests)
+	if len(dests) > len(set) {
+		return &IndexError{subject: SubjectTable, length: len(set), requested: len(dests) - 1}
+	}
+	c := newFillConfig(opts)
+	for i, dest := range dests {
+		rv := reflect.ValueOf(dest)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+			return fmt.Errorf("result set %d: expected pointer to slice, got %T", i, dest)
+		}
+		if err := bufferToSlice(set[i], rv.Elem(), c); err != nil {
+			return fmt.Errorf("result set %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// bufferToSlice copies buf into a new slice of structs stored in the slice value sv.
+func bufferToSlice(buf *Buffer, sv reflect.Value, c *fillConfig) error {
+	tp := sv.Type().Elem()
+	if err := structKind(tp); err != nil {
+		return err
+	}
+
+	plan := getStructPlan(tp, buf.Columns, buf.columnNameIndex, c.tagNames())
+	if plan.err != nil {
+		return plan.err
+	}
+
+	// Copy values to struct.
+	list := reflect.MakeSlice(sv.Type(), buf.Len(), buf.Len())
+	err := buf.Each(func(i int, row Row) error {
+		return plan.assign(list.Index(i), row.Field, c, buf.Columns, i)
+	})
+	if err != nil {
+		return err
+	}
+	sv.Set(list)
+	return nil
+}
+
+// structKind returns an error if tp is not a struct type.
+func structKind(tp reflect.Type) error {
 	switch k := tp.Kind(); k {
 	default:
-		return nil, fmt.Errorf("invalid type kind, expected struct, got %v", k)
+		return fmt.Errorf("invalid type kind, expected struct, got %v", k)
 	case reflect.Struct:
-		// Okay.
+		return nil
+	}
+}
+
+// structPlan is the mapping from buffer columns to struct fields for
+// a struct type and column list.
+type structPlan struct {
+	fields []planField // Mapped fields in buffer column order.
+	err    error       // Mapping error, such as unused struct fields.
+}
+
+// planField maps a single buffer column to a struct field.
+type planField struct {
+	column int // Buffer column index.
+	index  int // Struct field index.
+	name   string
+	mode   fieldMode
+
+	nullZero bool // Tagged nullzero, NULL is the zero value.
+}
+
+type fieldMode byte
+
+const (
+	fieldDirect  fieldMode = iota // Value is set directly.
+	fieldPointer                  // Pointer field, NULL is a nil pointer.
+	fieldScanner                  // Field implements sql.Scanner, such as sql.NullString.
+)
+
+type structPlanKey struct {
+	tp      reflect.Type
+	columns string
+	tags    string
+	// Names in colMap, or -1 if nil. For the same columns, colMap only
+	// differs by leaving out duplicate names, as DuplicatePositional does.
+	mapped int
+}
+
+// structPlanCache holds a *structPlan for each structPlanKey.
+var structPlanCache sync.Map
+
+// getStructPlan returns the cached plan for the struct type and columns,
+// creating it if needed. colMap may be nil.
+func getStructPlan(tp reflect.Type, columns []string, colMap map[string]int, tags []string) *structPlan {
+	key := structPlanKey{tp: tp, columns: strings.Join(columns, "\x00"), tags: strings.Join(tags, "\x00"), mapped: -1}
+	if colMap != nil {
+		key.mapped = len(colMap)
+	}
+	if v, ok := structPlanCache.Load(key); ok {
+		return v.(*structPlan)
 	}
+	plan := newStructPlan(tp, columns, colMap, tags)
+	v, _ := structPlanCache.LoadOrStore(key, plan)
+	return v.(*structPlan)
+}
 
-	lookup := make([]int, len(buf.Columns)) // Map the buffer index to the struct index.
-	colMap := buf.columnNameIndex           // Buffer name map[name]index.
+func newStructPlan(tp reflect.Type, columns []string, colMap map[string]int, tags []string) *structPlan {
+	lookup := make([]int, len(columns)) // Map the buffer index to the struct index.
+	if colMap == nil {
+		colMap = make(map[string]int, len(columns))
+		for i, n := range columns {
+			colMap[n] = i
+		}
+	}
 	for i := range lookup {
 		lookup[i] = -1
 	}
@@ -37,15 +168,27 @@ func BufferToStruct[T any](buf *Buffer) ([]T, error) {
 	)
 
 	// Setup the field lookup
+	var tagErrs []error
+	nullZero := make(map[int]bool)
 	for i := 0; i < tp.NumField(); i++ {
 		sf := tp.Field(i)
+		// Unexported fields, including embedded unexported types, can not be set.
+		if !sf.IsExported() {
+			continue
+		}
 		// Look for struct tag.
-		tag, ok := sf.Tag.Lookup("sql")
-		if ok {
-			if tag == "-" {
-				continue
-			}
-			index, ok := colMap[tag]
+		name, topts, err := fieldTag(sf, tags)
+		if name == "-" {
+			continue
+		}
+		if err != nil {
+			tagErrs = append(tagErrs, fmt.Errorf("field %s: %w", sf.Name, err))
+		}
+		if topts.nullZero {
+			nullZero[i] = true
+		}
+		if len(name) > 0 {
+			index, ok := colMap[name]
 			if ok {
 				lookup[index] = i
 				continue
@@ -60,17 +203,17 @@ func BufferToStruct[T any](buf *Buffer) ([]T, error) {
 		}
 
 		if reportUnmatchedStruct {
-			name := sf.Name
-			if len(tag) > 0 {
-				name = fmt.Sprintf("%s(tag=%s)", sf.Name, tag)
+			missing := sf.Name
+			if len(name) > 0 {
+				missing = fmt.Sprintf("%s(tag=%s)", sf.Name, name)
 			}
-			missingStruct = append(missingStruct, name)
+			missingStruct = append(missingStruct, missing)
 		}
 	}
 	if reportUnmatchedBuffer {
 		for bufIndex, structIndex := range lookup {
 			if structIndex < 0 {
-				name := buf.Columns[bufIndex]
+				name := columns[bufIndex]
 				if len(name) == 0 {
 					continue
 				}
@@ -79,39 +222,293 @@ func BufferToStruct[T any](buf *Buffer) ([]T, error) {
 		}
 	}
 
-	var err error
+	err := errors.Join(tagErrs...)
 	if len(missingStruct) > 0 {
 		err = errors.Join(err, fmt.Errorf("unused fields in struct %q", missingStruct))
 	}
 	if len(missingBuffer) > 0 {
 		err = errors.Join(err, fmt.Errorf("unused fields in query %q", missingBuffer))
 	}
-	if err != nil {
-		return nil, err
+
+	plan := &structPlan{err: err}
+	for bufIndex, structIndex := range lookup {
+		if structIndex < 0 {
+			continue
+		}
+		sf := tp.Field(structIndex)
+		pf := planField{
+			column:   bufIndex,
+			index:    structIndex,
+			name:     sf.Name,
+			nullZero: nullZero[structIndex],
+		}
+		switch {
+		case reflect.PointerTo(sf.Type).Implements(scannerType):
+			pf.mode = fieldScanner
+		case sf.Type.Kind() == reflect.Pointer:
+			pf.mode = fieldPointer
+		}
+		plan.fields = append(plan.fields, pf)
 	}
+	return plan
+}
 
-	// Copy values to struct.
-	for i, row := range buf.Rows {
-		v := &list[i]
-		rv := reflect.ValueOf(v).Elem()
-		for bufIndex, structIndex := range lookup {
-			if structIndex < 0 {
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// assign copies the row fields into the struct value rv.
+// Errors are returned as a *FieldError.
+func (plan *structPlan) assign(rv reflect.Value, field []any, c *fillConfig, columns []string, row int) error {
+	for _, pf := range plan.fields {
+		rf := rv.Field(pf.index)
+		fv := field[pf.column]
+		var err error
+		switch pf.mode {
+		case fieldScanner:
+			if scanErr := rf.Addr().Interface().(sql.Scanner).Scan(fv); scanErr != nil {
+				err = fmt.Errorf("cannot scan into %s (%w)", rf.Type(), scanErr)
+			}
+		case fieldPointer:
+			if fv == nil {
+				rf.SetZero()
 				continue
 			}
-			rf := rv.Field(structIndex)
-			fv := row.Field[bufIndex]
-			rfv := reflect.ValueOf(fv)
-			rf.Set(rfv)
+			p := reflect.New(rf.Type().Elem())
+			err = assignValue(p.Elem(), fv, c)
+			if err == nil {
+				rf.Set(p)
+			}
+		default:
+			if fv != nil {
+				err = assignValue(rf, fv, c)
+			} else if c.nullZero || pf.nullZero {
+				rf.SetZero()
+			} else {
+				err = fmt.Errorf("cannot assign %w to %s", ErrNull, rf.Type())
+			}
+		}
+		if err != nil {
+			return &FieldError{
+				Row:    row,
+				Column: columns[pf.column],
+				Field:  pf.name,
+				Value:  fv,
+				Err:    err,
+			}
 		}
 	}
-	return list, nil
+	return nil
+}
+
+var uuidType = reflect.TypeOf([16]byte{})
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// assignValue sets the non-NULL value fv into rf.
+func assignValue(rf reflect.Value, fv any, c *fillConfig) error {
+	v := reflect.ValueOf(fv)
+	ft := rf.Type()
+	if v.Type().AssignableTo(ft) {
+		rf.Set(v)
+		return nil
+	}
+	switch {
+	case isNumberKind(ft.Kind()) && isNumberKind(v.Kind()):
+		if err := setNumber(rf, v, c.strictNumbers); err != nil {
+			return fmt.Errorf("%w %s", err, ft)
+		}
+		return nil
+	case ft.ConvertibleTo(uuidType) && ft.Kind() == reflect.Array:
+		// UUID types such as [16]byte from 16 raw bytes or text.
+		u, err := parseUUID(fv)
+		if err != nil {
+			break
+		}
+		rf.Set(reflect.ValueOf(u).Convert(ft))
+		return nil
+	case ft.Kind() == reflect.Bool && c.boolWords != nil:
+		b, err := coerceBool(fv, c.boolWords)
+		if err != nil {
+			return fmt.Errorf("%w into %s", err, ft)
+		}
+		rf.SetBool(b)
+		return nil
+	case ft == timeType && c.timeLayouts != nil && isText(fv):
+		s, _ := asString(fv)
+		t, err := parseTime(s, c.timeLayouts, c.timeLoc)
+		if err != nil {
+			return fmt.Errorf("%w into %s", err, ft)
+		}
+		rf.Set(reflect.ValueOf(t))
+		return nil
+	case isUnmarshalText(fv, rf):
+		if err := unmarshalText(rf, fv); err != nil {
+			return fmt.Errorf("cannot unmarshal %T (%w) into %s", fv, err, ft)
+		}
+		return nil
+	case ft.Kind() == reflect.String && v.Type().ConvertibleTo(uuidType) && v.Kind() == reflect.Array:
+		// UUID value into a string field.
+		rf.SetString(formatUUID(v.Convert(uuidType).Interface().([16]byte)))
+		return nil
+	case ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 && isPGArrayText(fv):
+		// Postgres array text into a slice.
+		s, _ := asString(fv)
+		if err := assignPGArray(rf, s); err != nil {
+			return fmt.Errorf("cannot decode array (%w) into %s", err, ft)
+		}
+		return nil
+	case ft.Kind() == reflect.Slice && v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		// Slice of another element type, such as []int64 into []int32.
+		out := reflect.MakeSlice(ft, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ev := v.Index(i).Interface()
+			if ev == nil {
+				continue
+			}
+			if err := assignValue(out.Index(i), ev, c); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		rf.Set(out)
+		return nil
+	case c.decodeJSON && isJSONKind(ft):
+		var bb []byte
+		switch x := fv.(type) {
+		case string:
+			bb = []byte(x)
+		case []byte:
+			bb = x
+		default:
+			return fmt.Errorf("cannot assign %T to %s", fv, ft)
+		}
+		if err := json.Unmarshal(bb, rf.Addr().Interface()); err != nil {
+			return fmt.Errorf("cannot decode JSON (%w) into %s", err, ft)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot assign %T to %s", fv, ft)
+}
+
+// isText reports if fv is a string or []byte.
+func isText(fv any) bool {
+	_, ok := asString(fv)
+	return ok
+}
+
+// isUnmarshalText reports if fv is a string or []byte and rf implements
+// encoding.TextUnmarshaler or, for a []byte, encoding.BinaryUnmarshaler.
+func isUnmarshalText(fv any, rf reflect.Value) bool {
+	switch fv.(type) {
+	case string:
+		_, ok := rf.Addr().Interface().(encoding.TextUnmarshaler)
+		return ok
+	case []byte:
+		switch rf.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler, encoding.BinaryUnmarshaler:
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalText sets rf from the string or []byte fv. A []byte is passed to
+// UnmarshalBinary if implemented, falling back to UnmarshalText if that fails.
+func unmarshalText(rf reflect.Value, fv any) error {
+	p := rf.Addr().Interface()
+	tu, isText := p.(encoding.TextUnmarshaler)
+	switch fv := fv.(type) {
+	case string:
+		return tu.UnmarshalText([]byte(fv))
+	case []byte:
+		if bu, ok := p.(encoding.BinaryUnmarshaler); ok {
+			err := bu.UnmarshalBinary(fv)
+			if err == nil || !isText {
+				return err
+			}
+		}
+		return tu.UnmarshalText(fv)
+	}
+	return nil
+}
+
+// isPGArrayText reports if fv is text that looks like a Postgres array.
+func isPGArrayText(fv any) bool {
+	s, ok := asString(fv)
+	return ok && strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")
+}
+
+// isJSONKind reports if a field of type ft may be decoded from JSON text.
+func isJSONKind(ft reflect.Type) bool {
+	switch ft.Kind() {
+	case reflect.Struct:
+		return ft != timeType
+	case reflect.Map, reflect.Slice:
+		return true
+	}
+	return false
 }
 
 // Query into a struct slice.
+// Any Option values in params configure the fill and struct mapping and are not sent with the query.
 func QueryStruct[T any](ctx context.Context, q Queryer, text string, params ...any) ([]T, error) {
-	buf, err := NewBuffer(ctx, q, text, params)
+	buf, err := NewBuffer(ctx, q, text, params...)
 	if err != nil {
 		return nil, err
 	}
-	return BufferToStruct[T](buf)
+	defer buf.Close()
+
+	_, opts := splitOptions(params)
+	return BufferToStruct[T](buf, opts...)
+}
+
+// ErrTooManyRows is returned by QueryStructOne when the query returns more
+// than one row.
+var ErrTooManyRows = errors.New("more than one row in result")
+
+// QueryStructOne runs the query and returns its only row as a T.
+// It returns an *IndexError matching ErrNoRows when there are no rows, and
+// ErrTooManyRows when there is more than one. At most two rows are read.
+// Any Option values in params configure the fill and struct mapping and are not sent with the query.
+func QueryStructOne[T any](ctx context.Context, q Queryer, text string, params ...any) (T, error) {
+	v, ok, err := queryStructOne[T](ctx, q, text, params)
+	if err == nil && !ok {
+		err = &IndexError{subject: SubjectRow, length: 0, requested: 0}
+	}
+	return v, err
+}
+
+// QueryStructOptional runs the query and returns its only row as a T, and
+// reports if there was a row. No rows is not an error. It returns
+// ErrTooManyRows when there is more than one row.
+// Any Option values in params configure the fill and struct mapping and are not sent with the query.
+func QueryStructOptional[T any](ctx context.Context, q Queryer, text string, params ...any) (T, bool, error) {
+	return queryStructOne[T](ctx, q, text, params)
+}
+
+// queryStructOne maps the only row of the query into a T, reporting if there
+// was a row. It returns ErrTooManyRows if there is more than one row.
+func queryStructOne[T any](ctx context.Context, q Queryer, text string, params []any) (T, bool, error) {
+	var v T
+	c, err := NewCursor(ctx, q, text, params...)
+	if err != nil {
+		return v, false, err
+	}
+	defer c.Close()
+
+	if !c.Next() {
+		return v, false, c.Err()
+	}
+	buf := &Buffer{Columns: c.Columns()}
+	buf.AddRow(c.Row().Field)
+	_, opts := splitOptions(params)
+	list, err := BufferToStruct[T](buf, opts...)
+	if err != nil {
+		return v, false, err
+	}
+	if c.Next() {
+		return v, false, ErrTooManyRows
+	}
+	if err := c.Err(); err != nil {
+		return v, false, err
+	}
+	return list[0], true, nil
 }
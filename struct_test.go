@@ -1,10 +1,49 @@
 package table
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"net/netip"
+	"reflect"
 	"testing"
 )
 
+type testLevel int
+
+func (l *testLevel) UnmarshalText(bb []byte) error {
+	switch string(bb) {
+	default:
+		return fmt.Errorf("unknown level %q", bb)
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	}
+	return nil
+}
+
+// testColor is stored as a single byte, or by name as text.
+type testColor byte
+
+func (c *testColor) UnmarshalBinary(bb []byte) error {
+	if len(bb) != 1 {
+		return fmt.Errorf("expected 1 byte, got %d", len(bb))
+	}
+	*c = testColor(bb[0])
+	return nil
+}
+
+func (c *testColor) UnmarshalText(bb []byte) error {
+	if string(bb) != "blue" {
+		return fmt.Errorf("unknown color %q", bb)
+	}
+	*c = 3
+	return nil
+}
+
 func TestBufferToStruct(t *testing.T) {
 	type runner func(buf *Buffer) (any, error)
 	list := []struct {
@@ -48,6 +87,23 @@ func TestBufferToStruct(t *testing.T) {
 				return BufferToStruct[S](buf)
 			},
 		},
+		{
+			// Unexported fields can not be set and are ignored, even if a column matches.
+			Name:    "unexported",
+			Columns: []string{"ID", "name"},
+			Data: [][]any{
+				{int64(1), "R1"},
+			},
+			Want: `[]table.S{table.S{ID:1, name:"", cache:map[string]int(nil)}}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					ID    int64
+					name  string
+					cache map[string]int
+				}
+				return BufferToStruct[S](buf)
+			},
+		},
 		{
 			// Extra struct fields are disallowed.
 			Name:    "extra-struct",
@@ -57,6 +113,7 @@ func TestBufferToStruct(t *testing.T) {
 				{int64(2), "R2"},
 			},
 			Error: `unused fields in struct ["Age"]`,
+			Want:  `[]table.S(nil)`,
 			Run: func(buf *Buffer) (any, error) {
 				type S struct {
 					ID   int64
@@ -74,7 +131,7 @@ func TestBufferToStruct(t *testing.T) {
 				{int64(1), "R1"},
 				{int64(2), "R2"},
 			},
-			Error: `unused fields in struct ["Age"]`,
+			Want: `[]table.S{table.S{ID:1, Name:"R1", Age:0}, table.S{ID:2, Name:"R2", Age:0}}`,
 			Run: func(buf *Buffer) (any, error) {
 				type S struct {
 					ID   int64
@@ -84,6 +141,241 @@ func TestBufferToStruct(t *testing.T) {
 				return BufferToStruct[S](buf)
 			},
 		},
+		{
+			Name:    "null-error",
+			Columns: []string{"ID", "Name"},
+			Data: [][]any{
+				{int64(1), nil},
+			},
+			Error: `row 0, column "Name": cannot assign NULL value to string field "Name"`,
+			Want:  `[]table.S(nil)`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					ID   int64
+					Name string
+				}
+				return BufferToStruct[S](buf)
+			},
+		},
+		{
+			Name:    "null-zero",
+			Columns: []string{"ID", "Name"},
+			Data: [][]any{
+				{int64(1), nil},
+			},
+			Want: `[]table.S{table.S{ID:1, Name:""}}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					ID   int64
+					Name string
+				}
+				return BufferToStruct[S](buf, NullAsZero())
+			},
+		},
+		{
+			Name:    "null-pointer-scanner",
+			Columns: []string{"ID", "Name", "Note"},
+			Data: [][]any{
+				{int64(1), nil, nil},
+				{int64(2), "R2", "N2"},
+			},
+			Want: `[]string{"1 <nil> {String: Valid:false}", "2 R2 {String:N2 Valid:true}"}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					ID   int64
+					Name *string
+					Note sql.NullString
+				}
+				list, err := BufferToStruct[S](buf)
+				var out []string
+				for _, v := range list {
+					name := "<nil>"
+					if v.Name != nil {
+						name = *v.Name
+					}
+					out = append(out, fmt.Sprintf("%d %s %+v", v.ID, name, v.Note))
+				}
+				return out, err
+			},
+		},
+		{
+			Name:    "uuid",
+			Columns: []string{"ID", "Ref"},
+			Data: [][]any{
+				{"12345678-9abc-def0-1234-56789abcdef0", [16]byte{0x12, 0x34}},
+			},
+			Want: `[]table.S{table.S{ID:table.UUID{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}, Ref:"12340000-0000-0000-0000-000000000000"}}`,
+			Run: func(buf *Buffer) (any, error) {
+				type UUID [16]byte
+				type S struct {
+					ID  UUID
+					Ref string
+				}
+				return BufferToStruct[S](buf)
+			},
+		},
+		{
+			Name:    "mismatch",
+			Columns: []string{"Price"},
+			Data: [][]any{
+				{[]byte("1.5")},
+			},
+			Error: `row 0, column "Price": cannot assign []uint8 to float64 field "Price"`,
+			Want:  `[]table.S(nil)`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Price float64
+				}
+				return BufferToStruct[S](buf)
+			},
+		},
+		{
+			Name:    "json",
+			Columns: []string{"Tags", "Attr", "Bad"},
+			Data: [][]any{
+				{`["a","b"]`, []byte(`{"k":1}`), nil},
+			},
+			Want: `[]table.S{table.S{Tags:[]string{"a", "b"}, Attr:map[string]int{"k":1}, Bad:(*table.Inner)(nil)}}`,
+			Run: func(buf *Buffer) (any, error) {
+				type Inner struct{ K int }
+				type S struct {
+					Tags []string
+					Attr map[string]int
+					Bad  *Inner
+				}
+				return BufferToStruct[S](buf, DecodeJSON())
+			},
+		},
+		{
+			Name:    "json-error",
+			Columns: []string{"Attr"},
+			Data: [][]any{
+				{`{`},
+			},
+			Error: `row 0, column "Attr": cannot decode JSON (unexpected end of JSON input) into map[string]int field "Attr"`,
+			Want:  `[]table.S(nil)`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Attr map[string]int
+				}
+				return BufferToStruct[S](buf, DecodeJSON())
+			},
+		},
+		{
+			Name:    "postgres-array",
+			Columns: []string{"Names", "IDs"},
+			Data: [][]any{
+				{`{a,"b,c"}`, []byte(`{1,NULL,3}`)},
+			},
+			Want: `[]string{"[a b,c] [1 <nil> 3]"}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Names []string
+					IDs   []*int32
+				}
+				list, err := BufferToStruct[S](buf)
+				var out []string
+				for _, v := range list {
+					var ids []any
+					for _, id := range v.IDs {
+						if id == nil {
+							ids = append(ids, nil)
+							continue
+						}
+						ids = append(ids, *id)
+					}
+					out = append(out, fmt.Sprint(v.Names, " ", ids))
+				}
+				return out, err
+			},
+		},
+		{
+			Name:    "numeric-lenient",
+			Columns: []string{"Small", "Whole", "Ratio"},
+			Data: [][]any{
+				{int64(300), float64(2.7), int64(3)},
+			},
+			Want: `[]table.S{table.S{Small:0x2c, Whole:2, Ratio:3}}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Small uint8
+					Whole int
+					Ratio float32
+				}
+				return BufferToStruct[S](buf)
+			},
+		},
+		{
+			Name:    "numeric-strict",
+			Columns: []string{"Small", "Whole"},
+			Data: [][]any{
+				{int64(200), float64(2)},
+				{int64(300), float64(2)},
+			},
+			Error: `row 1, column "Small": int64 value 300 overflows or loses precision in uint8 field "Small"`,
+			Want:  `[]table.S(nil)`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Small uint8
+					Whole int
+				}
+				return BufferToStruct[S](buf, StrictNumbers())
+			},
+		},
+		{
+			Name:    "struct-tag",
+			Columns: []string{"id", "full_name", "Note"},
+			Data: [][]any{
+				{int64(1), "R1", "N1"},
+			},
+			Want: `[]table.S{table.S{ID:1, Name:"R1", Note:"N1", Skip:""}}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					ID   int64  `db:"id" sql:"ignored"`
+					Name string `db:"full_name"`
+					Note string
+					Skip string `db:"-"`
+				}
+				return BufferToStruct[S](buf, StructTag("db"))
+			},
+		},
+		{
+			Name:    "unmarshal-text",
+			Columns: []string{"Addr", "Level", "Color"},
+			Data: [][]any{
+				{"10.0.0.1", []byte("high"), []byte{2}},
+				{[]byte("::1"), "low", []byte("blue")},
+			},
+			Want: `[]string{"10.0.0.1 2 2", "::1 1 3"}`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Addr  netip.Addr
+					Level testLevel
+					Color testColor
+				}
+				list, err := BufferToStruct[S](buf)
+				var out []string
+				for _, v := range list {
+					out = append(out, fmt.Sprintf("%v %d %d", v.Addr, v.Level, v.Color))
+				}
+				return out, err
+			},
+		},
+		{
+			Name:    "unmarshal-text-error",
+			Columns: []string{"Level"},
+			Data: [][]any{
+				{"medium"},
+			},
+			Error: `row 0, column "Level": cannot unmarshal string (unknown level "medium") into table.testLevel field "Level"`,
+			Want:  `[]table.S(nil)`,
+			Run: func(buf *Buffer) (any, error) {
+				type S struct {
+					Level testLevel
+				}
+				return BufferToStruct[S](buf)
+			},
+		},
 	}
 
 	for _, item := range list {
@@ -109,3 +401,206 @@ func TestBufferToStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestSetToStructs(t *testing.T) {
+	type Order struct {
+		ID   int64
+		Item string
+	}
+	type Summary struct {
+		Total float64
+	}
+	orders := &Buffer{Columns: []string{"ID", "Item"}}
+	orders.AddRow([]any{int64(1), "R1"})
+	orders.AddRow([]any{int64(2), "R2"})
+	summary := &Buffer{Columns: []string{"Total"}}
+	summary.AddRow([]any{float64(2.5)})
+	set := Set{orders, summary}
+
+	list := []struct {
+		Name  string
+		Dests func() []any
+		Want  string
+		Error string
+	}{
+		{
+			Name: "all",
+			Dests: func() []any {
+				return []any{&[]Order{}, &[]Summary{}}
+			},
+			Want: `[]interface {}{[]table.Order{table.Order{ID:1, Item:"R1"}, table.Order{ID:2, Item:"R2"}}, []table.Summary{table.Summary{Total:2.5}}}`,
+		},
+		{
+			Name: "too-many",
+			Dests: func() []any {
+				return []any{&[]Order{}, &[]Summary{}, &[]Summary{}}
+			},
+			Error: "Set has 2 tables, requested index 2",
+		},
+		{
+			Name: "not-pointer",
+			Dests: func() []any {
+				return []any{[]Order{}}
+			},
+			Error: "result set 0: expected pointer to slice, got []table.Order",
+		},
+		{
+			Name: "mismatch",
+			Dests: func() []any {
+				return []any{&[]Order{}, &[]Order{}}
+			},
+			Error: `result set 1: unused fields in struct ["ID" "Item"]`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			dests := item.Dests()
+			err := SetToStructs(set, dests...)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			var got []any
+			for _, d := range dests {
+				got = append(got, reflect.ValueOf(d).Elem().Interface())
+			}
+			if g, w := fmt.Sprintf("%#v", got), item.Want; g != w {
+				t.Fatalf("got:\n%s\n\nwant:%s\n", g, w)
+			}
+		})
+	}
+}
+
+func TestQueryStructOne(t *testing.T) {
+	type S struct {
+		ID   int64
+		Name string
+	}
+	cols := []string{"ID", "Name"}
+	list := []struct {
+		Name  string
+		Rows  [][]driver.Value
+		Want  string
+		Is    error
+		Error string
+	}{
+		{Name: "one", Rows: [][]driver.Value{{int64(1), "R1"}}, Want: `table.S{ID:1, Name:"R1"}`},
+		{Name: "none", Want: `table.S{ID:0, Name:""}`, Is: ErrNoRows, Error: "Table has 0 rows, requested index 0"},
+		{Name: "many", Rows: [][]driver.Value{{int64(1), "R1"}, {int64(2), "R2"}}, Want: `table.S{ID:0, Name:""}`, Is: ErrTooManyRows, Error: "more than one row in result"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			db := openFake(t, fakeSet(fakeResult{Columns: cols, Rows: item.Rows}))
+			v, err := QueryStructOne[S](context.Background(), db, "select")
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if item.Is != nil && !errors.Is(err, item.Is) {
+				t.Fatalf("expected error matching %v", item.Is)
+			}
+			if g, w := fmt.Sprintf("%#v", v), item.Want; g != w {
+				t.Fatalf("got:\n%s\n\nwant:%s\n", g, w)
+			}
+		})
+	}
+}
+
+func TestQueryStructOptional(t *testing.T) {
+	type S struct {
+		ID int64
+	}
+	list := []struct {
+		Name  string
+		Rows  [][]driver.Value
+		Want  string
+		Error string
+	}{
+		{Name: "one", Rows: [][]driver.Value{{int64(1)}}, Want: `table.S{ID:1} true`},
+		{Name: "none", Want: `table.S{ID:0} false`},
+		{Name: "many", Rows: [][]driver.Value{{int64(1)}, {int64(2)}}, Want: `table.S{ID:0} false`, Error: "more than one row in result"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			db := openFake(t, fakeSet(fakeResult{Columns: []string{"ID"}, Rows: item.Rows}))
+			v, ok, err := QueryStructOptional[S](context.Background(), db, "select")
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if g, w := fmt.Sprintf("%#v %t", v, ok), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
+
+func BenchmarkBufferToStruct(b *testing.B) {
+	type S struct {
+		ID   int64
+		Name string `sql:"Name"`
+	}
+	buf := &Buffer{
+		Columns: []string{"ID", "Name"},
+	}
+	buf.AddRow([]any{int64(1), "R1"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := BufferToStruct[S](buf)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestStructPlanDuplicates checks that a plan cached for one duplicate
+// policy is not used for another.
+func TestStructPlanDuplicates(t *testing.T) {
+	type S struct {
+		ID int64
+	}
+	res := fakeResult{Columns: []string{"ID", "ID"}, Rows: [][]driver.Value{{int64(1), int64(2)}}}
+	list := []struct {
+		Name   string
+		Policy DuplicatePolicy
+		Want   string
+		Error  string
+	}{
+		{Name: "last", Policy: DuplicateLast, Want: "[{2}]"},
+		{Name: "positional", Policy: DuplicatePositional, Error: `unused fields in struct ["ID"]`},
+		{Name: "last-again", Policy: DuplicateLast, Want: "[{2}]"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b, err := FillResultSet(context.Background(), fakeRows(t, res), DuplicateColumns(item.Policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := BufferToStruct[S](b)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err == nil {
+				if g, w := fmt.Sprint(got), item.Want; g != w {
+					t.Fatalf("got %s want %s", g, w)
+				}
+			}
+		})
+	}
+}
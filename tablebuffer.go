@@ -5,7 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 )
 
 type Queryer interface {
@@ -15,6 +17,7 @@ type Queryer interface {
 // Row hold field level data.
 type Row struct {
 	columnNameIndex map[string]int
+	change          *rowChange // Set when the row is changed while tracking changes.
 
 	Field []any
 }
@@ -32,25 +35,65 @@ type Buffer struct {
 	Columns []string
 	Rows    []Row
 
+	name            string
+	columnTypes     []*sql.ColumnType
 	columnNameIndex map[string]int
+	duplicates      DuplicatePolicy
+	hasDuplicates   bool
+	result          sql.Result
+	frozen          bool
+	shared          bool       // Field storage is shared with a derived buffer.
+	changes         *changeLog // Set by TrackChanges.
+	spill           *rowFile   // Rows on disk, by SpillToDisk or OpenSnapshot.
 }
 
 // Set stores a list of Buffers.
 type Set []*Buffer
 
-type indexErrorSubject byte
+// IndexSubject is what an IndexError failed to find.
+type IndexSubject byte
 
 const (
-	indexErrorTable indexErrorSubject = iota + 1
-	indexErrorColumn
-	indexErrorRow
-	indexErrorName
+	SubjectTable     IndexSubject = iota + 1 // A table in a Set, by index.
+	SubjectColumn                            // A column, by index.
+	SubjectRow                               // A row, by index.
+	SubjectName                              // A column, by name.
+	SubjectTableName                         // A table in a Set, by name.
+)
+
+func (s IndexSubject) String() string {
+	switch s {
+	default:
+		return fmt.Sprintf("IndexSubject(%d)", byte(s))
+	case SubjectTable:
+		return "table"
+	case SubjectColumn:
+		return "column"
+	case SubjectRow:
+		return "row"
+	case SubjectName:
+		return "name"
+	case SubjectTableName:
+		return "table name"
+	}
+}
+
+var (
+	// ErrNoRows is matched by errors.Is when a requested row does not exist,
+	// such as the first row of an empty result.
+	ErrNoRows = errors.New("no rows in result")
+	// ErrNoResultSets is matched by errors.Is when a requested result set
+	// does not exist.
+	ErrNoResultSets = errors.New("no result sets")
+	// ErrColumnNotFound is matched by errors.Is when a requested column name
+	// or index does not exist.
+	ErrColumnNotFound = errors.New("column not found")
 )
 
 // Error returned when attempting to access a row or column which does
-// not exist.
+// not exist. It wraps ErrNoRows, ErrNoResultSets, or ErrColumnNotFound.
 type IndexError struct {
-	subject   indexErrorSubject
+	subject   IndexSubject
 	length    int
 	requested int
 
@@ -61,38 +104,88 @@ func (tie *IndexError) Error() string {
 	switch tie.subject {
 	default:
 		return fmt.Sprintf("unknown index error: %+v", *tie)
-	case indexErrorName:
+	case SubjectName:
 		return fmt.Sprintf(`Table doesn't have column named "%s"`, tie.notFoundName)
-	case indexErrorTable:
+	case SubjectTable:
 		return fmt.Sprintf("Set has %d tables, requested index %d", tie.length, tie.requested)
-	case indexErrorColumn:
+	case SubjectTableName:
+		return fmt.Sprintf(`Set doesn't have table named "%s"`, tie.notFoundName)
+	case SubjectColumn:
 		return fmt.Sprintf("Table has %d columns, requested index %d", tie.length, tie.requested)
-	case indexErrorRow:
+	case SubjectRow:
 		return fmt.Sprintf("Table has %d rows, requested index %d", tie.length, tie.requested)
 	}
 }
 
+// Subject returns what was not found.
+func (tie *IndexError) Subject() IndexSubject {
+	return tie.subject
+}
+
+// Length returns the number of tables, columns, or rows available.
+// It is zero for SubjectName and SubjectTableName.
+func (tie *IndexError) Length() int {
+	return tie.length
+}
+
+// Requested returns the requested index.
+// It is zero for SubjectName and SubjectTableName.
+func (tie *IndexError) Requested() int {
+	return tie.requested
+}
+
+// Name returns the missing column or table name for SubjectName and
+// SubjectTableName.
+func (tie *IndexError) Name() string {
+	return tie.notFoundName
+}
+
+func (tie *IndexError) Unwrap() error {
+	switch tie.subject {
+	default:
+		return nil
+	case SubjectName, SubjectColumn:
+		return ErrColumnNotFound
+	case SubjectTable, SubjectTableName:
+		return ErrNoResultSets
+	case SubjectRow:
+		return ErrNoRows
+	}
+}
+
 // NewSet returns a set of table buffers from the given query.
+// Any Option values in params configure the fill and are not sent with the query.
 func NewSet(ctx context.Context, q Queryer, sql string, params ...any) (Set, error) {
-	rows, err := q.QueryContext(ctx, sql, params...)
+	start := time.Now()
+	rows, opts, qi, err := query(ctx, q, sql, params)
 	if err != nil {
+		newFillConfig(opts).observe(start, nil, err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	return FillSet(ctx, rows)
+	set, err := newFiller(ctx, rows, opts).fillSet(start)
+	qi.finish(err, func() FillStats {
+		stats := FillStats{ResultSets: len(set), Bytes: set.SizeBytes()}
+		for _, b := range set {
+			stats.Rows += b.Len()
+		}
+		return stats
+	})
+	return set, err
 }
 
 // NewBuffer returns a new single table buffer.
 func NewBuffer(ctx context.Context, q Queryer, sql string, params ...any) (table *Buffer, err error) {
 	set, err := NewSet(ctx, q, sql, params...)
 	if err != nil {
+		set.close()
 		return nil, err
 	}
-	if len(set) == 0 {
-		return nil, &IndexError{subject: indexErrorColumn, length: len(set), requested: 0}
+	if len(set) > 1 {
+		set[1:].close()
 	}
-	return set[0], nil
+	return set.First()
 }
 
 // NewRow returns the first row.
@@ -101,11 +194,9 @@ func NewRow(ctx context.Context, q Queryer, sql string, params ...any) (Row, err
 	if err != nil {
 		return Row{}, err
 	}
-	if len(t.Rows) == 0 {
-		return Row{}, &IndexError{subject: indexErrorRow, length: len(t.Rows), requested: 0}
-	}
-	row := t.Rows[0]
-	return row, nil
+	defer t.Close()
+
+	return t.Row(0)
 }
 
 // NewScaler returns the first field in the first row.
@@ -114,12 +205,14 @@ func NewScaler(ctx context.Context, q Queryer, sql string, params ...any) (any,
 	if err != nil {
 		return nil, err
 	}
-	if len(t.Rows) == 0 {
-		return nil, &IndexError{subject: indexErrorRow, length: len(t.Rows), requested: 0}
+	defer t.Close()
+
+	row, err := t.Row(0)
+	if err != nil {
+		return nil, err
 	}
-	row := t.Rows[0]
 	if len(row.Field) == 0 {
-		return nil, &IndexError{subject: indexErrorColumn, length: len(row.Field), requested: 0}
+		return nil, &IndexError{subject: SubjectColumn, length: len(row.Field), requested: 0}
 	}
 	return row.Field[0], nil
 }
@@ -127,80 +220,92 @@ func NewScaler(ctx context.Context, q Queryer, sql string, params ...any) (any,
 // FillSet will take a sql query result and fill the buffer with
 // the entire result set.
 func FillSet(ctx context.Context, rows *sql.Rows) (Set, error) {
-	var out []any
-	var dest []any
-	var err error
-
-	var set Set = make([]*Buffer, 0, 3)
-	table := &Buffer{
-		Rows: make([]Row, 0, 10),
-	}
-
-	for {
-		first := true
-		colCount := 0
-		for rows.Next() {
-			// Some initialization depends on knowing the column names
-			// which isn't available until the first row is fetched.
-			if first {
-				first = false
-
-				// Get the column names.
-				table.Columns, err = rows.Columns()
-				if err != nil {
-					return set, err
-				}
-				colCount = len(table.Columns)
-
-				// Create an easy lookup that should be more efficent then
-				// always looping to lookup an index from a column name.
-				table.columnNameIndex = make(map[string]int, colCount)
-				for i, n := range table.Columns {
-					table.columnNameIndex[n] = i
-				}
-
-				// Create a sized pointer slice.
-				dest = make([]any, colCount)
-			}
-			// Create a new data slice that will be appended on to the table.
-			out = make([]any, colCount)
+	return FillSetOpt(ctx, rows)
+}
 
-			// Scanning requires having a pointer to the data slice,
-			// so first make a pointer slice to each element of the data slice.
-			for i, _ := range dest {
-				dest[i] = &out[i]
-			}
+// Get returns the buffer at index i.
+func (s Set) Get(i int) (*Buffer, error) {
+	if i < 0 || i >= len(s) {
+		return nil, &IndexError{subject: SubjectTable, length: len(s), requested: i}
+	}
+	return s[i], nil
+}
 
-			// Then scan into the pointer slice.
-			err = rows.Scan(dest...)
-			if err != nil {
-				return set, err
-			}
-			table.Rows = append(table.Rows, Row{
-				columnNameIndex: table.columnNameIndex,
-				Field:           out,
-			})
-		}
-		set = append(set, table)
-		if !rows.NextResultSet() {
+// First returns the first buffer.
+func (s Set) First() (*Buffer, error) {
+	return s.Get(0)
+}
+
+// MustGet returns the buffer at index i and panics with an *IndexError
+// if it does not exist.
+func (s Set) MustGet(i int) *Buffer {
+	b, err := s.Get(i)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// SetNames names the buffers in order, so they may be found with ByName.
+// Names beyond the number of buffers are ignored.
+func (s Set) SetNames(names []string) {
+	for i, n := range names {
+		if i >= len(s) {
 			break
 		}
-		first = false
-		table = &Buffer{
-			Rows: make([]Row, 0, 10),
+		if s[i].frozen {
+			panic(ErrFrozen)
 		}
+		s[i].name = n
+	}
+}
+
+// ByName returns the first buffer with the given name.
+func (s Set) ByName(name string) (*Buffer, error) {
+	for _, b := range s {
+		if b.name == name {
+			return b, nil
+		}
+	}
+	return nil, &IndexError{subject: SubjectTableName, notFoundName: name}
+}
+
+// Name returns the name given to the buffer by Set.SetNames.
+func (t *Buffer) Name() string {
+	return t.name
+}
+
+// Result returns the statement result, such as the rows affected by an
+// UPDATE, if one was recorded with SetResult. Query helpers that report
+// statement results add a buffer without columns for each statement that
+// does not return rows.
+func (t *Buffer) Result() sql.Result {
+	return t.result
+}
+
+// SetResult records the statement result of the buffer.
+func (t *Buffer) SetResult(res sql.Result) {
+	if t.frozen {
+		panic(ErrFrozen)
 	}
-	return set, nil
+	t.result = res
 }
 
 // Get the field from the row index and named column.
 func (t *Buffer) Get(rowIndex int, columnName string) any {
 	i, ok := t.columnNameIndex[columnName]
 	if !ok {
-		panic(&IndexError{subject: indexErrorName, notFoundName: columnName})
+		panic(&IndexError{subject: SubjectName, notFoundName: columnName})
 	}
 	if len(t.Rows) <= rowIndex {
-		panic(&IndexError{subject: indexErrorRow, length: len(t.Rows), requested: rowIndex})
+		if t.spill != nil {
+			row, err := t.Row(rowIndex)
+			if err != nil {
+				panic(err)
+			}
+			return row.Field[i]
+		}
+		panic(&IndexError{subject: SubjectRow, length: len(t.Rows), requested: rowIndex})
 	}
 	return t.Rows[rowIndex].Field[i]
 }
@@ -209,28 +314,79 @@ func (t *Buffer) Get(rowIndex int, columnName string) any {
 func (r Row) Get(columnName string) any {
 	i, ok := r.columnNameIndex[columnName]
 	if !ok {
-		panic(&IndexError{subject: indexErrorName, notFoundName: columnName})
+		panic(&IndexError{subject: SubjectName, notFoundName: columnName})
 	}
 	return r.Field[i]
 }
 
+// At returns the field at the row and column index.
+func (t *Buffer) At(rowIndex, colIndex int) (any, error) {
+	row, err := t.Row(rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	return row.At(colIndex)
+}
+
+// At returns the field at the column index.
+func (r Row) At(colIndex int) (any, error) {
+	if colIndex < 0 || colIndex >= len(r.Field) {
+		return nil, &IndexError{subject: SubjectColumn, length: len(r.Field), requested: colIndex}
+	}
+	return r.Field[colIndex], nil
+}
+
 // Add a new row to an existing Buffer.
 func (b *Buffer) AddRow(row []any) {
+	if b.frozen {
+		panic(ErrFrozen)
+	}
+	if b.spill != nil {
+		panic(ErrSpilled)
+	}
 	if b.Columns == nil {
 		panic("must set Columns first in Buffer")
 	}
 	if r, c := len(row), len(b.Columns); r != c {
 		panic(fmt.Errorf("row count %d is different then column schema count %d", r, c))
 	}
-	if b.columnNameIndex == nil {
-		cni := make(map[string]int, len(b.Columns))
-		for i, n := range b.Columns {
-			cni[n] = i
-		}
-		b.columnNameIndex = cni
-	}
+	b.buildIndex()
 	b.Rows = append(b.Rows, Row{
 		Field:           row,
 		columnNameIndex: b.columnNameIndex,
+		change:          b.changes.inserted(),
+	})
+}
+
+// buildIndex creates the column name lookup if it is not already present.
+func (b *Buffer) buildIndex() {
+	if b.columnNameIndex != nil {
+		return
+	}
+	cni := make(map[string]int, len(b.Columns))
+	for i, n := range b.Columns {
+		cni[n] = i
+	}
+	b.columnNameIndex = cni
+}
+
+// clone returns a copy of the buffer whose rows may be modified without
+// changing b. Field values themselves are not copied. The copy is not frozen,
+// does not track changes, and holds any rows on disk in memory.
+func (b *Buffer) clone() *Buffer {
+	b.buildIndex()
+	c := *b
+	c.frozen = false
+	c.shared = false
+	c.spill = nil
+	c.changes = nil // Rows are copied without their change either.
+	c.Columns = append([]string(nil), b.Columns...)
+	c.Rows = make([]Row, 0, b.Len())
+	b.eachRow(func(_ int, row Row) {
+		c.Rows = append(c.Rows, Row{
+			columnNameIndex: b.columnNameIndex,
+			Field:           append([]any(nil), row.Field...),
+		})
 	})
+	return &c
 }
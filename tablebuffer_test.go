@@ -0,0 +1,227 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIndexErrorIs(t *testing.T) {
+	ctx := context.Background()
+	empty := fakeResult{Columns: []string{"ID"}}
+	one := fakeResult{Columns: []string{"ID"}, Rows: [][]driver.Value{{int64(1)}}}
+
+	list := []struct {
+		Name   string
+		Result fakeResult
+		Run    func(db Queryer) error
+		Is     error
+		Detail string
+	}{
+		{
+			Name:   "row-empty",
+			Result: empty,
+			Run: func(db Queryer) error {
+				_, err := NewRow(ctx, db, "select")
+				return err
+			},
+			Is:     ErrNoRows,
+			Detail: "row 0/0 ",
+		},
+		{
+			Name:   "scaler-empty",
+			Result: empty,
+			Run: func(db Queryer) error {
+				_, err := NewScaler(ctx, db, "select")
+				return err
+			},
+			Is:     ErrNoRows,
+			Detail: "row 0/0 ",
+		},
+		{
+			Name:   "get-name",
+			Result: one,
+			Run: func(db Queryer) (err error) {
+				buf, err := NewBuffer(ctx, db, "select")
+				if err != nil {
+					return err
+				}
+				defer func() {
+					err, _ = recover().(error)
+				}()
+				buf.Get(0, "Name")
+				return nil
+			},
+			Is:     ErrColumnNotFound,
+			Detail: "name 0/0 Name",
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			db := openFake(t, fakeSet(item.Result))
+			err := item.Run(db)
+			var ie *IndexError
+			if !errors.As(err, &ie) || !errors.Is(err, item.Is) {
+				t.Fatalf("expected IndexError matching %v, got %v", item.Is, err)
+			}
+			detail := fmt.Sprintf("%v %d/%d %s", ie.Subject(), ie.Requested(), ie.Length(), ie.Name())
+			if g, w := detail, item.Detail; g != w {
+				t.Fatalf("detail got %q want %q", g, w)
+			}
+		})
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	one := &Buffer{Columns: []string{"ID"}}
+	list := []struct {
+		Name  string
+		Set   Set
+		Index int
+		Error string
+	}{
+		{Name: "first", Set: Set{one}, Index: 0},
+		{Name: "empty", Set: nil, Index: 0, Error: "Set has 0 tables, requested index 0"},
+		{Name: "negative", Set: Set{one}, Index: -1, Error: "Set has 1 tables, requested index -1"},
+		{Name: "past-end", Set: Set{one}, Index: 1, Error: "Set has 1 tables, requested index 1"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b, err := item.Set.Get(item.Index)
+			if len(item.Error) > 0 {
+				if err == nil || err.Error() != item.Error || !errors.Is(err, ErrNoResultSets) {
+					t.Fatalf("expected error %s, got %v", item.Error, err)
+				}
+				if b != nil {
+					t.Fatal("expected nil buffer")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b != one || item.Set.MustGet(item.Index) != one {
+				t.Fatal("wrong buffer returned")
+			}
+		})
+	}
+}
+
+func TestAt(t *testing.T) {
+	buf := &Buffer{Columns: []string{"ID", "Name"}}
+	buf.AddRow([]any{int64(1), "R1"})
+
+	list := []struct {
+		Name  string
+		Row   int
+		Col   int
+		Want  any
+		Error string
+	}{
+		{Name: "ok", Row: 0, Col: 1, Want: "R1"},
+		{Name: "row", Row: 1, Col: 0, Error: "Table has 1 rows, requested index 1"},
+		{Name: "negative-row", Row: -1, Col: 0, Error: "Table has 1 rows, requested index -1"},
+		{Name: "column", Row: 0, Col: 2, Error: "Table has 2 columns, requested index 2"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			v, err := buf.At(item.Row, item.Col)
+			if len(item.Error) > 0 {
+				if err == nil || err.Error() != item.Error {
+					t.Fatalf("expected error %s, got %v", item.Error, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != item.Want {
+				t.Fatalf("got %v want %v", v, item.Want)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	buf := &Buffer{Columns: []string{"ID", "Name"}}
+	buf.AddRow([]any{int64(1), "R1"})
+
+	list := []struct {
+		Name  string
+		Index int
+		OK    bool
+	}{
+		{Name: "ID", Index: 0, OK: true},
+		{Name: "Name", Index: 1, OK: true},
+		{Name: "Other", Index: 0, OK: false},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			i, ok := buf.ColumnIndex(item.Name)
+			if i != item.Index || ok != item.OK || buf.HasColumn(item.Name) != item.OK {
+				t.Fatalf("buffer got %d, %t want %d, %t", i, ok, item.Index, item.OK)
+			}
+			i, ok = buf.Rows[0].ColumnIndex(item.Name)
+			if i != item.Index || ok != item.OK || buf.Rows[0].HasColumn(item.Name) != item.OK {
+				t.Fatalf("row got %d, %t want %d, %t", i, ok, item.Index, item.OK)
+			}
+		})
+	}
+}
+
+func TestSetByName(t *testing.T) {
+	set := Set{{Columns: []string{"ID"}}, {Columns: []string{"Total"}}}
+	set.SetNames([]string{"orders", "summary", "extra"})
+
+	list := []struct {
+		Name   string
+		Column string
+		Error  string
+	}{
+		{Name: "orders", Column: "ID"},
+		{Name: "summary", Column: "Total"},
+		{Name: "extra", Error: `Set doesn't have table named "extra"`},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b, err := set.ByName(item.Name)
+			if len(item.Error) > 0 {
+				if err == nil || err.Error() != item.Error || !errors.Is(err, ErrNoResultSets) {
+					t.Fatalf("expected error %s, got %v", item.Error, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.Columns[0] != item.Column || b.Name() != item.Name {
+				t.Fatalf("got buffer %q with %v", b.Name(), b.Columns)
+			}
+		})
+	}
+}
+
+func TestNewSetOut(t *testing.T) {
+	db := openFake(t, func(query string, args []driver.NamedValue) ([]fakeResult, error) {
+		for _, a := range args {
+			if o, ok := a.Value.(sql.Out); ok {
+				*(o.Dest.(*int64)) = int64(len(a.Name) + a.Ordinal)
+			}
+		}
+		return []fakeResult{{Columns: []string{"ID"}, Rows: [][]driver.Value{{int64(1)}}}}, nil
+	})
+	var total, count int64
+	set, out, err := NewSetOut(context.Background(), db, "exec Report", "in", sql.Named("Total", sql.Out{Dest: &total}), NullAs(0), sql.Out{Dest: &count})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 1 {
+		t.Fatalf("expected 1 result set, got %d", len(set))
+	}
+	if g, w := fmt.Sprint(out), "map[3:3 Total:7]"; g != w {
+		t.Fatalf("out params got %s want %s", g, w)
+	}
+}
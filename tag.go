@@ -0,0 +1,146 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// tagOptions are the options following the column name in a field tag,
+// such as `sql:"ID,pk,omitinsert"`.
+type tagOptions struct {
+	nullZero   bool // NULL is the zero value, as with NullAsZero for the field.
+	omitInsert bool // Not set by INSERT statements, such as identity columns.
+	pk         bool // Part of the primary key.
+}
+
+// fieldTag returns the column name and options from the first of the tags
+// set on the field, or the "sql" tag if no tags are given. The name is empty
+// if no tag sets it, and "-" if the field is skipped. Options are only read
+// from the first of the tags; the others, such as "json", have options of
+// their own that are ignored.
+func fieldTag(sf reflect.StructField, tags []string) (string, tagOptions, error) {
+	if len(tags) == 0 {
+		tags = []string{"sql"}
+	}
+	for i, t := range tags {
+		tag, ok := sf.Tag.Lookup(t)
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			name, _, _ := strings.Cut(tag, ",")
+			return name, tagOptions{}, nil
+		}
+		if tag == "-" {
+			return tag, tagOptions{}, nil
+		}
+		return parseTag(tag)
+	}
+	return "", tagOptions{}, nil
+}
+
+// tagNames returns the field tags read by struct mapping, in order.
+// Nil is the "sql" tag alone.
+func (c *fillConfig) tagNames() []string {
+	if !c.tagFallback {
+		return c.structTags
+	}
+	tags := c.structTags
+	if len(tags) == 0 {
+		tags = []string{"sql"}
+	}
+	for _, t := range []string{"db", "json"} {
+		if !slices.Contains(tags, t) {
+			tags = append(tags[:len(tags):len(tags)], t)
+		}
+	}
+	return tags
+}
+
+// parseTag splits a field tag into the column name and options.
+// The name is empty if the tag only has options, such as `sql:",pk"`.
+func parseTag(tag string) (string, tagOptions, error) {
+	name, rest, _ := strings.Cut(tag, ",")
+	var opts tagOptions
+	for len(rest) > 0 {
+		var o string
+		o, rest, _ = strings.Cut(rest, ",")
+		switch o {
+		default:
+			return name, opts, fmt.Errorf("unknown tag option %q", o)
+		case "":
+		case "nullzero":
+			opts.nullZero = true
+		case "omitinsert":
+			opts.omitInsert = true
+		case "pk":
+			opts.pk = true
+		}
+	}
+	return name, opts, nil
+}
+
+// StructColumns lists the columns a struct type maps to, for generating
+// statements such as with Buffer.Flush.
+type StructColumns struct {
+	Columns    []string // Every mapped column, in field order.
+	Key        []string // Columns of fields tagged pk.
+	OmitInsert []string // Columns of fields tagged omitinsert.
+}
+
+// ColumnsOf returns the columns the struct type T maps to with the same rules
+// as BufferToStruct. The column name in the tag may be followed by options,
+// and an empty name uses the field name:
+//
+//	ID   int64  `sql:"ID,pk,omitinsert"`
+//	Name string `sql:",nullzero"`
+//
+// The options are:
+//
+//   - pk marks a primary key column, listed in Key, such as the key columns
+//     of Buffer.Flush.
+//   - omitinsert marks a column set by the database, such as an identity
+//     column, listed in OmitInsert for the Flush option of the same name.
+//   - nullzero sets the field to its zero value for NULL, as NullAsZero
+//     does for every field.
+//
+// The StructTag and TagFallback options read other tags.
+func ColumnsOf[T any](opts ...Option) (StructColumns, error) {
+	tp := reflect.TypeOf((*T)(nil)).Elem()
+	if err := structKind(tp); err != nil {
+		return StructColumns{}, err
+	}
+	return structColumns(tp, newFillConfig(opts).tagNames())
+}
+
+// structColumns returns the columns the fields of the struct type tp map
+// to with the tags.
+func structColumns(tp reflect.Type, tags []string) (StructColumns, error) {
+	var sc StructColumns
+	for i := 0; i < tp.NumField(); i++ {
+		sf := tp.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, topts, err := fieldTag(sf, tags)
+		if name == "-" {
+			continue
+		}
+		if err != nil {
+			return StructColumns{}, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+		sc.Columns = append(sc.Columns, name)
+		if topts.pk {
+			sc.Key = append(sc.Key, name)
+		}
+		if topts.omitInsert {
+			sc.OmitInsert = append(sc.OmitInsert, name)
+		}
+	}
+	return sc, nil
+}
@@ -0,0 +1,102 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestColumnsOf(t *testing.T) {
+	type Account struct {
+		ID      int64  `sql:"AccountID,pk,omitinsert"`
+		Region  string `sql:",pk"`
+		Name    string `sql:",nullzero"`
+		Created string `sql:"CreatedAt,omitinsert" db:"created"`
+		Note    string `sql:"-"`
+		cache   map[string]int
+	}
+	sc, err := ColumnsOf[Account]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%v", sc), "{[AccountID Region Name CreatedAt] [AccountID Region] [AccountID CreatedAt]}"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+	sc, err = ColumnsOf[Account](StructTag("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%v", sc), "{[ID Region Name created Note] [] []}"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	type Bad struct {
+		ID int64 `sql:"ID,primary"`
+	}
+	_, err = ColumnsOf[Bad]()
+	if g, w := fmt.Sprint(err), `field ID: unknown tag option "primary"`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+	_, err = BufferToStruct[Bad](&Buffer{Columns: []string{"ID"}})
+	if g, w := fmt.Sprint(err), `field ID: unknown tag option "primary"`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+}
+
+func TestTagNullZero(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"AccountID", "Name", "Score"},
+	}
+	b.AddRow([]any{int64(1), nil, int64(5)})
+	type S struct {
+		ID    int64  `sql:"AccountID,pk"`
+		Name  string `sql:",nullzero"`
+		Score int64
+	}
+	list, err := BufferToStruct[S](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%+v", list), "[{ID:1 Name: Score:5}]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	b.AddRow([]any{int64(2), "R2", nil})
+	_, err = BufferToStruct[S](b)
+	if g, w := fmt.Sprint(err), `row 1, column "Score": cannot assign NULL value to int64 field "Score"`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+}
+
+func TestTagFallback(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"id", "full_name", "email", "Note"},
+	}
+	b.AddRow([]any{int64(1), "R1", "r1@example.com", "N1"})
+	type S struct {
+		ID    int64  `sql:"id" db:"ignored"`
+		Name  string `db:"full_name" json:"name"`
+		Email string `json:"email,omitempty"`
+		Note  string
+		Skip  string `db:"-" json:"skip"`
+	}
+	list, err := BufferToStruct[S](b, TagFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%+v", list), "[{ID:1 Name:R1 Email:r1@example.com Note:N1 Skip:}]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	_, err = BufferToStruct[S](b)
+	if g, w := fmt.Sprint(err), `unused fields in struct ["Name" "Email" "Skip"]`; g != w {
+		t.Fatalf("expected error: %s, got error: %s", w, g)
+	}
+
+	sc, err := ColumnsOf[S](StructTag("db"), TagFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(sc.Columns), "[ignored full_name email Note]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}
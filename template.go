@@ -0,0 +1,74 @@
+package table
+
+import (
+	"bufio"
+	"html/template"
+	"strings"
+)
+
+// Cell is a single field paired with its column name, for templates that
+// render rows without knowing the columns ahead of time.
+type Cell struct {
+	Column string
+	Value  any
+}
+
+// Cells returns the fields of row paired with the buffer column names.
+func (b *Buffer) Cells(row Row) []Cell {
+	cells := make([]Cell, len(row.Field))
+	for i, f := range row.Field {
+		cells[i] = Cell{Value: f}
+		if i < len(b.Columns) {
+			cells[i].Column = b.Columns[i]
+		}
+	}
+	return cells
+}
+
+// FuncMap returns template functions for rendering buffers in html/template
+// and text/template, so buffers can be used directly rather then first being
+// converted to maps. The options configure how field values are formatted
+// as text. The functions are:
+//
+//	columns BUFFER        the column names
+//	cell ROW COLUMN       the field of the named column
+//	cells BUFFER ROW      the fields of the row as []Cell
+//	text VALUE            the field formatted as text, NULL as NullText
+//	tableHTML BUFFER      the buffer as an escaped HTML table
+//
+// For example:
+//
+//	{{range .Rows}}<li>{{cell . "Name"}}: {{text (cell . "Total")}}</li>{{end}}
+//
+// The cell function returns an error, stopping the template, if the column
+// does not exist.
+func FuncMap(opts ...TextOption) template.FuncMap {
+	c := newTextConfig(opts)
+	return template.FuncMap{
+		"columns": func(b *Buffer) []string {
+			return b.Columns
+		},
+		"cell": func(r Row, column string) (any, error) {
+			i, ok := r.columnNameIndex[column]
+			if !ok {
+				return nil, &IndexError{subject: SubjectName, notFoundName: column}
+			}
+			return r.Field[i], nil
+		},
+		"cells": func(b *Buffer, r Row) []Cell {
+			return b.Cells(r)
+		},
+		"text": c.format,
+		"tableHTML": func(b *Buffer) (template.HTML, error) {
+			var sb strings.Builder
+			bw := bufio.NewWriter(&sb)
+			if err := b.writeHTMLTable(bw, c); err != nil {
+				return "", err
+			}
+			if err := bw.Flush(); err != nil {
+				return "", err
+			}
+			return template.HTML(sb.String()), nil
+		},
+	}
+}
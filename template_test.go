@@ -0,0 +1,85 @@
+package table
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name"},
+	}
+	b.AddRow([]any{int64(1), "<b>"})
+	b.AddRow([]any{int64(2), nil})
+
+	list := []struct {
+		Name  string
+		HTML  bool
+		Opts  []TextOption
+		Text  string
+		Want  string
+		Error string
+	}{
+		{
+			Name: "cell",
+			Text: `{{range .Rows}}{{cell . "ID"}}={{text (cell . "Name")}};{{end}}`,
+			Want: "1=<b>;2=;",
+		},
+		{
+			Name: "cells",
+			Opts: []TextOption{NullText("NULL")},
+			Text: `{{range $r := .Rows}}{{range cells $ $r}}{{.Column}}:{{text .Value}} {{end}}{{end}}`,
+			Want: "ID:1 Name:<b> ID:2 Name:NULL ",
+		},
+		{
+			Name: "columns",
+			Text: `{{range columns .}}[{{.}}]{{end}}`,
+			Want: "[ID][Name]",
+		},
+		{
+			Name: "escaped",
+			HTML: true,
+			Text: `{{range .Rows}}{{cell . "Name"}}{{end}}`,
+			Want: "&lt;b&gt;",
+		},
+		{
+			Name: "tableHTML",
+			HTML: true,
+			Text: `{{tableHTML .}}`,
+			Want: "<table>\n<thead><tr><th>ID</th><th>Name</th></tr></thead>\n<tbody>\n<tr><td>1</td><td>&lt;b&gt;</td></tr>\n<tr><td>2</td><td></td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			Name:  "missing",
+			Text:  `{{range .Rows}}{{cell . "Total"}}{{end}}`,
+			Error: `template: t:1:17: executing "t" at <cell . "Total">: error calling cell: Table doesn't have column named "Total"`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			var err error
+			if item.HTML {
+				tmpl := htmltemplate.Must(htmltemplate.New("t").Funcs(FuncMap(item.Opts...)).Parse(item.Text))
+				err = tmpl.Execute(sb, b)
+			} else {
+				tmpl := texttemplate.Must(texttemplate.New("t").Funcs(FuncMap(item.Opts...)).Parse(item.Text))
+				err = tmpl.Execute(sb, b)
+			}
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := sb.String(), item.Want; g != w {
+				t.Fatalf("got:\n%q\n\nwant:\n%q\n", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is matched by errors.Is when a query is stopped by the
+// deadline of a TimeoutQueryer.
+var ErrTimeout = errors.New("query timeout")
+
+// TimeoutError is returned by TimeoutQueryer when the query did not start
+// returning rows before its deadline. It also matches context.DeadlineExceeded.
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (te *TimeoutError) Error() string {
+	return fmt.Sprintf("query timeout after %v: %v", te.Timeout, te.Err)
+}
+
+func (te *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+func (te *TimeoutError) Unwrap() error {
+	return te.Err
+}
+
+// TimeoutQueryer returns a Queryer that gives each query a deadline of d,
+// unless the context already has a deadline. The deadline also applies to
+// reading the rows. When the query is run by this package, such as by NewSet,
+// the deadline is released once the fill ends; otherwise it is released when
+// it passes.
+//
+// An error from the query caused by the deadline is returned as a
+// *TimeoutError. Errors while reading the rows wrap context.DeadlineExceeded.
+func TimeoutQueryer(q Queryer, d time.Duration) Queryer {
+	return &timeoutQueryer{q: q, d: d}
+}
+
+type timeoutQueryer struct {
+	q Queryer
+	d time.Duration
+}
+
+func (tq *timeoutQueryer) QueryContext(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+	if _, ok := ctx.Deadline(); ok {
+		return tq.q.QueryContext(ctx, text, params...)
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, tq.d)
+	rows, err := tq.q.QueryContext(ctx, text, params...)
+	if err != nil {
+		cancel()
+		if parent.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return nil, &TimeoutError{Timeout: tq.d, Err: err}
+		}
+		return nil, err
+	}
+	// Without a QueryInfo the end of the rows is not known, so the
+	// context is only released at the deadline.
+	if qi, ok := QueryInfoFrom(ctx); ok {
+		qi.OnDone(func(FillStats, error) { cancel() })
+	} else {
+		time.AfterFunc(tq.d, cancel)
+	}
+	return rows, nil
+}
@@ -0,0 +1,80 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeoutQueryer(t *testing.T) {
+	db := openFake(t, fakeSet(fakeResult{Columns: []string{"ID"}}))
+	parentDeadline := time.Now().Add(time.Hour)
+	list := []struct {
+		Name     string
+		Deadline bool // Parent context has a deadline.
+		Block    bool // Query waits for the context.
+		Error    string
+		Check    func(t *testing.T, ctx context.Context)
+	}{
+		{
+			Name: "released",
+			Check: func(t *testing.T, ctx context.Context) {
+				if _, ok := ctx.Deadline(); !ok {
+					t.Fatal("expected deadline")
+				}
+				if !errors.Is(ctx.Err(), context.Canceled) {
+					t.Fatalf("expected context released after fill, got %v", ctx.Err())
+				}
+			},
+		},
+		{
+			Name:     "existing",
+			Deadline: true,
+			Check: func(t *testing.T, ctx context.Context) {
+				if d, _ := ctx.Deadline(); !d.Equal(parentDeadline) {
+					t.Fatalf("deadline got %v want %v", d, parentDeadline)
+				}
+			},
+		},
+		{Name: "timeout", Block: true, Error: "query timeout after 1ms: context deadline exceeded"},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			ctx := context.Background()
+			if item.Deadline {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, parentDeadline)
+				defer cancel()
+			}
+			var queryCtx context.Context
+			q := queryerFunc(func(ctx context.Context, text string, params ...any) (*sql.Rows, error) {
+				queryCtx = ctx
+				if item.Block {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				return db.QueryContext(ctx, text, params...)
+			})
+			d := time.Minute
+			if item.Block {
+				d = time.Millisecond
+			}
+			_, err := NewSet(ctx, TimeoutQueryer(q, d), "select ID")
+			var errs string
+			if err != nil {
+				errs = err.Error()
+				if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("expected ErrTimeout and DeadlineExceeded, got %v", err)
+				}
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if item.Check != nil {
+				item.Check(t, queryCtx)
+			}
+		})
+	}
+}
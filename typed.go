@@ -0,0 +1,93 @@
+package table
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+)
+
+// TypedBuffer is a result stored directly as a slice of structs, using the
+// same mapping rules as BufferToStruct. Rows are copied into T as they are
+// scanned, without keeping an intermediate []any per row.
+type TypedBuffer[T any] struct {
+	Columns []string
+	Rows    []T
+}
+
+// NewTypedBuffer returns the first result set of the query as a TypedBuffer.
+// Any Option values in params configure the fill and are not sent with the query.
+func NewTypedBuffer[T any](ctx context.Context, q Queryer, sql string, params ...any) (*TypedBuffer[T], error) {
+	rows, opts, qi, err := query(ctx, q, sql, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tb, err := FillTyped[T](ctx, rows, opts...)
+	qi.finish(err, func() FillStats {
+		if tb == nil {
+			return FillStats{}
+		}
+		return FillStats{Rows: len(tb.Rows), ResultSets: 1}
+	})
+	return tb, err
+}
+
+// FillTyped reads the current result set of rows into a TypedBuffer.
+// The MaxBytes option does not apply to typed buffers.
+// When an error is returned, the rows read up to that point are also returned.
+func FillTyped[T any](ctx context.Context, rows *sql.Rows, opts ...Option) (*TypedBuffer[T], error) {
+	tp := reflect.TypeOf((*T)(nil)).Elem()
+	if err := structKind(tp); err != nil {
+		return nil, err
+	}
+	f := newFiller(ctx, rows, opts)
+	c := f.c
+	tb := &TypedBuffer[T]{
+		Rows: make([]T, 0, c.capacity),
+	}
+	table := &Buffer{}
+	var plan *structPlan
+	var out []any
+	first := true
+	for rows.Next() {
+		if first {
+			first = false
+			if err := f.setup(table); err != nil {
+				return tb, err
+			}
+			tb.Columns = table.Columns
+			plan = getStructPlan(tp, table.Columns, table.columnNameIndex, c.tagNames())
+			if plan.err != nil {
+				return tb, plan.err
+			}
+			out = make([]any, len(table.Columns))
+		}
+		if c.maxRows > 0 && f.rowCount >= c.maxRows {
+			return tb, c.truncated(f.rowCount, 0, false)
+		}
+		if f.rowCount%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return tb, err
+			}
+		}
+		clear(out)
+		if err := f.scan(out); err != nil {
+			return tb, rowError(len(tb.Rows), err)
+		}
+		var v T
+		if err := plan.assign(reflect.ValueOf(&v).Elem(), out, c, tb.Columns, len(tb.Rows)); err != nil {
+			return tb, err
+		}
+		tb.Rows = append(tb.Rows, v)
+		f.rowCount++
+		if c.progress != nil && f.rowCount%c.progressEvery == 0 {
+			c.progress(f.rowCount, f.resultSet)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return tb, incomplete(f.rowCount, err)
+	}
+	f.done()
+	return tb, nil
+}
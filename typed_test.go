@@ -0,0 +1,37 @@
+package table
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestTypedBuffer(t *testing.T) {
+	type Account struct {
+		ID   int64
+		Name string `sql:"AccountName"`
+	}
+	db := openFake(t, fakeSet(fakeResult{
+		Columns: []string{"ID", "AccountName", "Extra"},
+		Rows: [][]driver.Value{
+			{int64(1), "R1", "x"},
+			{int64(2), "R2", "y"},
+		},
+	}))
+	tb, err := NewTypedBuffer[Account](context.Background(), db, "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprintf("%v %+v", tb.Columns, tb.Rows), "[ID AccountName Extra] [{ID:1 Name:R1} {ID:2 Name:R2}]"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	type Missing struct {
+		Age int64
+	}
+	_, err = NewTypedBuffer[Missing](context.Background(), db, "select")
+	if g, w := fmt.Sprint(err), `unused fields in struct ["Age"]`; g != w {
+		t.Fatalf("got error %s want %s", g, w)
+	}
+}
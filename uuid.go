@@ -0,0 +1,97 @@
+package table
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Database type names treated as UUID columns when no names are given to UUIDAs.
+var uuidTypeNames = []string{"UUID", "UNIQUEIDENTIFIER"}
+
+// UUIDAs converts UUID values with fn. Values in columns with one of the given
+// database type names, or UUID and UNIQUEIDENTIFIER if none are given, may be
+// 16 raw bytes or text in the canonical, braced, or plain hex forms.
+// Raw UNIQUEIDENTIFIER bytes are read in SQL Server byte order.
+// Use UUIDString for the canonical text form, or a func returning a UUID type
+// of your choice. NULL values are not converted.
+func UUIDAs(fn func(u [16]byte) (any, error), typeNames ...string) Option {
+	if len(typeNames) == 0 {
+		typeNames = uuidTypeNames
+	}
+	match := make(map[string]bool, len(typeNames))
+	for _, n := range typeNames {
+		match[strings.ToUpper(n)] = true
+	}
+	return WithConverter(func(col Column, v any) (any, error) {
+		typeName := strings.ToUpper(col.DatabaseTypeName)
+		if v == nil || !match[typeName] {
+			return v, nil
+		}
+		u, err := parseUUID(v)
+		if err != nil {
+			return nil, err
+		}
+		if _, raw := v.([]byte); raw && len(v.([]byte)) == 16 && typeName == "UNIQUEIDENTIFIER" {
+			u = swapUUID(u)
+		}
+		return fn(u)
+	})
+}
+
+// UUIDString returns the canonical lower case text form of the UUID.
+func UUIDString(u [16]byte) (any, error) {
+	return formatUUID(u), nil
+}
+
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// parseUUID reads a UUID from 16 raw bytes or from text.
+func parseUUID(v any) ([16]byte, error) {
+	var u [16]byte
+	var s string
+	switch x := v.(type) {
+	case [16]byte:
+		return x, nil
+	case []byte:
+		if len(x) == 16 {
+			copy(u[:], x)
+			return u, nil
+		}
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return u, fmt.Errorf("cannot convert %T to a UUID", v)
+	}
+	t := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	t = strings.ReplaceAll(t, "-", "")
+	if len(t) != 32 {
+		return u, fmt.Errorf("cannot parse %q as a UUID", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(t)); err != nil {
+		return u, fmt.Errorf("cannot parse %q as a UUID", s)
+	}
+	return u, nil
+}
+
+// swapUUID converts between SQL Server byte order, where the first three
+// groups are little endian, and the standard big endian order.
+func swapUUID(u [16]byte) [16]byte {
+	u[0], u[1], u[2], u[3] = u[3], u[2], u[1], u[0]
+	u[4], u[5] = u[5], u[4]
+	u[6], u[7] = u[7], u[6]
+	return u
+}
@@ -0,0 +1,166 @@
+package table
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Validator checks column rules against a Buffer.
+// Rules other than NotNull pass NULL values.
+type Validator struct {
+	rules []validatorRule
+}
+
+type validatorRule struct {
+	column string
+	name   string
+	// check returns the function that checks each value of a column,
+	// returning a message when the value fails the rule.
+	check func() func(v any) string
+}
+
+// Violation is a single value that failed a Validator rule.
+type Violation struct {
+	Row     int    // Row index.
+	Column  string // Column name.
+	Rule    string // Rule name, such as "not null" or "unique".
+	Value   any    // Field value.
+	Message string // Reason the value failed the rule.
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("row %d, column %q: %s", v.Row, v.Column, v.Message)
+}
+
+// NewValidator returns an empty Validator.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+func (v *Validator) add(column, name string, check func() func(v any) string) *Validator {
+	v.rules = append(v.rules, validatorRule{column: column, name: name, check: check})
+	return v
+}
+
+// NotNull requires the column to have no NULL values.
+func (v *Validator) NotNull(column string) *Validator {
+	return v.add(column, "not null", func() func(any) string {
+		return func(f any) string {
+			if f == nil {
+				return "value is NULL"
+			}
+			return ""
+		}
+	})
+}
+
+// Unique requires the non-NULL values in the column to be distinct.
+func (v *Validator) Unique(column string) *Validator {
+	return v.add(column, "unique", func() func(any) string {
+		seen := make(map[any]bool)
+		return func(f any) string {
+			k := nestKey(f)
+			if k == nil {
+				return ""
+			}
+			if seen[k] {
+				return fmt.Sprintf("duplicate value %v", f)
+			}
+			seen[k] = true
+			return ""
+		}
+	})
+}
+
+// Range requires the column values to be numbers within min and max, inclusive.
+func (v *Validator) Range(column string, min, max float64) *Validator {
+	return v.add(column, "range", func() func(any) string {
+		return func(f any) string {
+			if f == nil {
+				return ""
+			}
+			n, ok := asFloat64(f)
+			if !ok {
+				return fmt.Sprintf("%T is not a number", f)
+			}
+			if n < min || n > max {
+				return fmt.Sprintf("value %v is out of range [%v, %v]", f, min, max)
+			}
+			return ""
+		}
+	})
+}
+
+// MaxLength requires the column values to be text of at most n characters.
+func (v *Validator) MaxLength(column string, n int) *Validator {
+	return v.add(column, "max length", func() func(any) string {
+		return func(f any) string {
+			if f == nil {
+				return ""
+			}
+			s, ok := asString(f)
+			if !ok {
+				return fmt.Sprintf("%T is not text", f)
+			}
+			if c := utf8.RuneCountInString(s); c > n {
+				return fmt.Sprintf("length %d exceeds %d", c, n)
+			}
+			return ""
+		}
+	})
+}
+
+// Match requires the column values to be text matching re.
+func (v *Validator) Match(column string, re *regexp.Regexp) *Validator {
+	return v.add(column, "match", func() func(any) string {
+		return func(f any) string {
+			if f == nil {
+				return ""
+			}
+			s, ok := asString(f)
+			if !ok {
+				return fmt.Sprintf("%T is not text", f)
+			}
+			if !re.MatchString(s) {
+				return fmt.Sprintf("value %q does not match %s", s, re)
+			}
+			return ""
+		}
+	})
+}
+
+// Validate checks every rule against b, returning the violations in row order.
+// An *IndexError is returned if a rule names a column b does not have.
+func (v *Validator) Validate(b *Buffer) ([]Violation, error) {
+	index := make([]int, len(v.rules))
+	checks := make([]func(any) string, len(v.rules))
+	for i, r := range v.rules {
+		x, err := b.lookupColumn(r.column)
+		if err != nil {
+			return nil, err
+		}
+		index[i] = x
+		checks[i] = r.check()
+	}
+	var list []Violation
+	err := b.Each(func(ri int, row Row) error {
+		for i, r := range v.rules {
+			f := row.Field[index[i]]
+			if msg := checks[i](f); len(msg) > 0 {
+				list = append(list, Violation{
+					Row:     ri,
+					Column:  r.column,
+					Rule:    r.name,
+					Value:   f,
+					Message: msg,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,79 @@
+package table
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	buf := &Buffer{Columns: []string{"ID", "Code", "Price"}}
+	buf.AddRow([]any{int64(1), "AB-1", float64(9.5)})
+	buf.AddRow([]any{int64(2), []byte("ab-2"), float64(-1)})
+	buf.AddRow([]any{int64(1), nil, nil})
+	buf.AddRow([]any{nil, "AB-12345", "cheap"})
+
+	list := []struct {
+		Name      string
+		Validator *Validator
+		Want      string
+		Error     string
+	}{
+		{
+			Name:      "pass",
+			Validator: NewValidator().MaxLength("Code", 8).Range("ID", 0, 10),
+		},
+		{
+			Name:      "not-null",
+			Validator: NewValidator().NotNull("ID").NotNull("Code"),
+			Want: `row 2, column "Code": value is NULL
+row 3, column "ID": value is NULL`,
+		},
+		{
+			Name:      "unique",
+			Validator: NewValidator().Unique("ID"),
+			Want:      `row 2, column "ID": duplicate value 1`,
+		},
+		{
+			Name:      "range",
+			Validator: NewValidator().Range("Price", 0, 100),
+			Want: `row 1, column "Price": value -1 is out of range [0, 100]
+row 3, column "Price": string is not a number`,
+		},
+		{
+			Name:      "max-length",
+			Validator: NewValidator().MaxLength("Code", 4),
+			Want:      `row 3, column "Code": length 8 exceeds 4`,
+		},
+		{
+			Name:      "match",
+			Validator: NewValidator().Match("Code", regexp.MustCompile(`^AB-\d$`)),
+			Want: `row 1, column "Code": value "ab-2" does not match ^AB-\d$
+row 3, column "Code": value "AB-12345" does not match ^AB-\d$`,
+		},
+		{
+			Name:      "missing-column",
+			Validator: NewValidator().NotNull("Name"),
+			Error:     `Table doesn't have column named "Name"`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			vl, err := item.Validator.Validate(buf)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			var got []string
+			for _, v := range vl {
+				got = append(got, v.Error())
+			}
+			if g, w := strings.Join(got, "\n"), item.Want; g != w {
+				t.Fatalf("got:\n%s\n\nwant:\n%s", g, w)
+			}
+		})
+	}
+}
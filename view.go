@@ -0,0 +1,232 @@
+package table
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// View is a read only window onto a Buffer, limited to a list of rows and a
+// subset of columns, that does not copy the parent's rows. Views are cheap to
+// create and narrow, such as to render one page of a filtered very large buffer.
+//
+// A view reads the parent buffer each time it is used, so changes to the
+// parent fields are seen by the view. Adding or removing parent rows, or
+// changing its columns, invalidates the view.
+type View struct {
+	parent *Buffer
+	rows   []int // Parent row index for each view row, nil for every parent row.
+	cols   []int // Parent column index for each view column, nil for every column.
+
+	columns         []string
+	columnNameIndex map[string]int
+}
+
+// View returns a view of every row and column of the buffer.
+// It panics with ErrSpilled if rows of the buffer are on disk.
+func (b *Buffer) View() *View {
+	if b.spill != nil {
+		panic(ErrSpilled)
+	}
+	b.buildIndex()
+	return &View{
+		parent:          b,
+		columns:         b.Columns,
+		columnNameIndex: b.columnNameIndex,
+	}
+}
+
+// Len returns the number of rows in the view.
+func (v *View) Len() int {
+	if v.rows == nil {
+		return len(v.parent.Rows)
+	}
+	return len(v.rows)
+}
+
+// Columns returns the column names of the view. The slice must not be modified.
+func (v *View) Columns() []string {
+	return v.columns
+}
+
+// HasColumn reports if the view has the named column.
+func (v *View) HasColumn(columnName string) bool {
+	_, ok := v.columnNameIndex[columnName]
+	return ok
+}
+
+// ColumnIndex returns the index of the named column in the view and if it was found.
+func (v *View) ColumnIndex(columnName string) (int, bool) {
+	i, ok := v.columnNameIndex[columnName]
+	return i, ok
+}
+
+// parentRow returns the parent row index of view row i.
+func (v *View) parentRow(i int) int {
+	if v.rows == nil {
+		return i
+	}
+	return v.rows[i]
+}
+
+// parentColumn returns the parent column index of view column i.
+func (v *View) parentColumn(i int) int {
+	if v.cols == nil {
+		return i
+	}
+	return v.cols[i]
+}
+
+// Row returns the row at index i. When the view has every parent column the
+// parent row is returned without copying; otherwise the selected fields are
+// copied into a new row.
+func (v *View) Row(i int) (Row, error) {
+	if i < 0 || i >= v.Len() {
+		return Row{}, &IndexError{subject: SubjectRow, length: v.Len(), requested: i}
+	}
+	return v.row(i), nil
+}
+
+func (v *View) row(i int) Row {
+	row := v.parent.Rows[v.parentRow(i)]
+	if v.cols == nil {
+		return row
+	}
+	field := make([]any, len(v.cols))
+	for ci, pi := range v.cols {
+		field[ci] = row.Field[pi]
+	}
+	return Row{columnNameIndex: v.columnNameIndex, Field: field}
+}
+
+// At returns the field at the row and column index of the view.
+func (v *View) At(rowIndex, colIndex int) (any, error) {
+	if rowIndex < 0 || rowIndex >= v.Len() {
+		return nil, &IndexError{subject: SubjectRow, length: v.Len(), requested: rowIndex}
+	}
+	if colIndex < 0 || colIndex >= len(v.columns) {
+		return nil, &IndexError{subject: SubjectColumn, length: len(v.columns), requested: colIndex}
+	}
+	return v.parent.Rows[v.parentRow(rowIndex)].Field[v.parentColumn(colIndex)], nil
+}
+
+// Get the field from the row index and named column.
+// It panics like Buffer.Get.
+func (v *View) Get(rowIndex int, columnName string) any {
+	i, ok := v.columnNameIndex[columnName]
+	if !ok {
+		panic(&IndexError{subject: SubjectName, notFoundName: columnName})
+	}
+	if rowIndex < 0 || rowIndex >= v.Len() {
+		panic(&IndexError{subject: SubjectRow, length: v.Len(), requested: rowIndex})
+	}
+	return v.parent.Rows[v.parentRow(rowIndex)].Field[v.parentColumn(i)]
+}
+
+// Filter returns a view of the rows where keep returns true.
+func (v *View) Filter(keep func(row Row) bool) *View {
+	rows := make([]int, 0, v.Len())
+	for i, n := 0, v.Len(); i < n; i++ {
+		if keep(v.row(i)) {
+			rows = append(rows, v.parentRow(i))
+		}
+	}
+	return v.withRows(rows)
+}
+
+// Sort returns a view with the rows ordered by less, keeping the original
+// order of equal rows.
+func (v *View) Sort(less func(a, b Row) bool) *View {
+	n := v.Len()
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return less(v.row(order[i]), v.row(order[j]))
+	})
+	rows := make([]int, n)
+	for i, vi := range order {
+		rows[i] = v.parentRow(vi)
+	}
+	return v.withRows(rows)
+}
+
+// Slice returns a view of the rows from start up to but not including end.
+// The bounds are limited to the rows of the view.
+func (v *View) Slice(start, end int) *View {
+	n := v.Len()
+	start = min(max(start, 0), n)
+	end = min(max(end, start), n)
+	rows := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		rows = append(rows, v.parentRow(i))
+	}
+	return v.withRows(rows)
+}
+
+// Select returns a view with only the named columns, in the order given.
+func (v *View) Select(columns ...string) (*View, error) {
+	cols := make([]int, len(columns))
+	index := make(map[string]int, len(columns))
+	for i, n := range columns {
+		ci, ok := v.columnNameIndex[n]
+		if !ok {
+			return nil, &IndexError{subject: SubjectName, notFoundName: n}
+		}
+		cols[i] = v.parentColumn(ci)
+		index[n] = i
+	}
+	return &View{
+		parent:          v.parent,
+		rows:            v.rows,
+		cols:            cols,
+		columns:         append([]string(nil), columns...),
+		columnNameIndex: index,
+	}, nil
+}
+
+func (v *View) withRows(rows []int) *View {
+	nv := *v
+	nv.rows = rows
+	return &nv
+}
+
+// Buffer copies the rows and columns of the view into a new buffer.
+func (v *View) Buffer() *Buffer {
+	b := &Buffer{
+		Columns: append([]string(nil), v.columns...),
+		Rows:    make([]Row, 0, v.Len()),
+		name:    v.parent.name,
+	}
+	b.buildIndex()
+	for i, n := 0, v.Len(); i < n; i++ {
+		row := v.row(i)
+		field := row.Field
+		if v.cols == nil {
+			field = append([]any(nil), field...)
+		}
+		b.Rows = append(b.Rows, Row{columnNameIndex: b.columnNameIndex, Field: field})
+	}
+	return b
+}
+
+// MarshalJSON encodes the view like a Buffer, without copying it first.
+func (v *View) MarshalJSON() ([]byte, error) {
+	columns, err := json.Marshal(v.columns)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(`{"Columns":`), columns...)
+	out = append(out, `,"Rows":[`...)
+	for i, n := 0, v.Len(); i < n; i++ {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		bb, err := json.Marshal(v.row(i).Field)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, bb...)
+	}
+	return append(out, "]}"...), nil
+}
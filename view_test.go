@@ -0,0 +1,96 @@
+package table
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	b := &Buffer{
+		Columns: []string{"ID", "Name", "Score"},
+	}
+	for i := int64(1); i <= 6; i++ {
+		b.AddRow([]any{i, "R" + string(rune('0'+i)), i % 3})
+	}
+
+	sel, err := b.View().
+		Filter(func(row Row) bool { return row.Get("ID").(int64) > 1 }).
+		Sort(func(a, b Row) bool { return a.Get("Score").(int64) < b.Get("Score").(int64) }).
+		Select("Name", "ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := sel.Slice(1, 4)
+
+	list := []struct {
+		Name string
+		View *View
+		Want string
+	}{
+		{"all", b.View().Slice(4, 10), `{"Columns":["ID","Name","Score"],"Rows":[[5,"R5",2],[6,"R6",0]]}`},
+		{"select", sel, `{"Columns":["Name","ID"],"Rows":[["R3",3],["R6",6],["R4",4],["R2",2],["R5",5]]}`},
+		{"page", page, `{"Columns":["Name","ID"],"Rows":[["R6",6],["R4",4],["R2",2]]}`},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			bb, err := json.Marshal(item.View)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := string(bb), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+			bb, err = json.Marshal(item.View.Buffer())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := string(bb), item.Want; g != w {
+				t.Fatalf("buffer got:\n%s\nwant:\n%s", g, w)
+			}
+		})
+	}
+
+	if g, w := page.Get(0, "ID"), int64(6); g != w {
+		t.Fatalf("expected %v, got %v", w, g)
+	}
+	if g, _ := page.At(2, 0); g != "R2" {
+		t.Fatalf("expected R2, got %v", g)
+	}
+	if page.HasColumn("Score") {
+		t.Fatal("expected Score to not be in view")
+	}
+	row, err := page.Row(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := row.Get("Name"); g != "R4" {
+		t.Fatalf("expected R4, got %v", g)
+	}
+
+	// Views read the parent fields.
+	b.Rows[5].Field[1] = "Six"
+	if g := page.Get(0, "Name"); g != "Six" {
+		t.Fatalf("expected Six, got %v", g)
+	}
+
+	errList := []struct {
+		Name  string
+		Err   error
+		Error string
+	}{
+		{"row", func() error { _, err := page.Row(3); return err }(), "Table has 3 rows, requested index 3"},
+		{"column", func() error { _, err := page.At(0, 2); return err }(), "Table has 2 columns, requested index 2"},
+		{"select", func() error { _, err := page.Select("Score"); return err }(), `Table doesn't have column named "Score"`},
+	}
+	for _, item := range errList {
+		t.Run(item.Name, func(t *testing.T) {
+			var errs string
+			if item.Err != nil {
+				errs = item.Err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+		})
+	}
+}
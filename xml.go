@@ -0,0 +1,165 @@
+package table
+
+import (
+	"encoding/xml"
+	"fmt"
+	"unicode"
+)
+
+// XMLBuffer encodes and decodes a Buffer as XML using the given element names.
+// A Buffer on its own encodes as:
+//
+//	<rows><row><Col>v</Col>...</row></rows>
+//
+// NULL values are encoded as an empty element with a null="true" attribute.
+// When decoding, values are read as strings and any element name is accepted
+// for the root and row elements.
+type XMLBuffer struct {
+	*Buffer
+
+	RootElement string // Defaults to the start element name, or "rows".
+	RowElement  string // Defaults to "row".
+}
+
+const (
+	xmlDefaultRoot = "rows"
+	xmlDefaultRow  = "row"
+	xmlNullAttr    = "null"
+)
+
+// MarshalXML encodes the buffer as XML using the default element names.
+func (b *Buffer) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return XMLBuffer{Buffer: b}.MarshalXML(e, start)
+}
+
+// UnmarshalXML decodes the buffer from XML.
+func (b *Buffer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return XMLBuffer{Buffer: b}.UnmarshalXML(d, start)
+}
+
+func (xb XMLBuffer) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	b := xb.Buffer
+	root := start
+	switch {
+	case len(xb.RootElement) > 0:
+		root = xml.StartElement{Name: xml.Name{Local: xb.RootElement}}
+	case root.Name.Local == "Buffer" || root.Name.Local == "XMLBuffer" || len(root.Name.Local) == 0:
+		root = xml.StartElement{Name: xml.Name{Local: xmlDefaultRoot}}
+	}
+	rowName := xml.Name{Local: xb.RowElement}
+	if len(rowName.Local) == 0 {
+		rowName.Local = xmlDefaultRow
+	}
+
+	colNames := make([]xml.Name, len(b.Columns))
+	for i, n := range b.Columns {
+		if !isXMLName(n) {
+			return fmt.Errorf("column %q is not a valid XML element name", n)
+		}
+		colNames[i] = xml.Name{Local: n}
+	}
+
+	tc := newTextConfig(nil)
+	if err := e.EncodeToken(root); err != nil {
+		return err
+	}
+	err := b.Each(func(_ int, row Row) error {
+		if err := e.EncodeToken(xml.StartElement{Name: rowName}); err != nil {
+			return err
+		}
+		for i, f := range row.Field {
+			col := xml.StartElement{Name: colNames[i]}
+			if f == nil {
+				col.Attr = []xml.Attr{{Name: xml.Name{Local: xmlNullAttr}, Value: "true"}}
+			}
+			if err := e.EncodeToken(col); err != nil {
+				return err
+			}
+			if f != nil {
+				if err := e.EncodeToken(xml.CharData(tc.format(f))); err != nil {
+					return err
+				}
+			}
+			if err := e.EncodeToken(col.End()); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(xml.EndElement{Name: rowName})
+	})
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(root.End())
+}
+
+func (xb XMLBuffer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	b := xb.Buffer
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	b.Columns = nil
+	b.Rows = nil
+	b.columnNameIndex = map[string]int{}
+
+	type xmlField struct {
+		XMLName xml.Name
+		Null    bool   `xml:"null,attr"`
+		Value   string `xml:",chardata"`
+	}
+	type xmlRow struct {
+		Fields []xmlField `xml:",any"`
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			for i := range b.Rows {
+				b.Rows[i].columnNameIndex = b.columnNameIndex
+				for len(b.Rows[i].Field) < len(b.Columns) {
+					b.Rows[i].Field = append(b.Rows[i].Field, nil)
+				}
+			}
+			return nil
+		case xml.StartElement:
+			var xr xmlRow
+			if err := d.DecodeElement(&xr, &tok); err != nil {
+				return err
+			}
+			field := make([]any, len(b.Columns))
+			for _, xf := range xr.Fields {
+				n := xf.XMLName.Local
+				i, ok := b.columnNameIndex[n]
+				if !ok {
+					i = len(b.Columns)
+					b.Columns = append(b.Columns, n)
+					b.columnNameIndex[n] = i
+					field = append(field, nil)
+				}
+				if !xf.Null {
+					field[i] = xf.Value
+				}
+			}
+			b.Rows = append(b.Rows, Row{Field: field})
+		}
+	}
+}
+
+// isXMLName reports if s may be used as an XML element name.
+func isXMLName(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
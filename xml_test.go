@@ -0,0 +1,151 @@
+package table
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+)
+
+func TestXML(t *testing.T) {
+	list := []struct {
+		Name    string
+		Columns []string
+		Data    [][]any
+		Root    string
+		Row     string
+		Want    string
+		Error   string
+	}{
+		{
+			Name:    "default",
+			Columns: []string{"ID", "Name"},
+			Data:    [][]any{{int64(1), "R1"}, {int64(2), "R2"}},
+			Want:    `<rows><row><ID>1</ID><Name>R1</Name></row><row><ID>2</ID><Name>R2</Name></row></rows>`,
+		},
+		{
+			Name:    "null",
+			Columns: []string{"ID", "Name"},
+			Data:    [][]any{{int64(1), nil}, {nil, ""}},
+			Want:    `<rows><row><ID>1</ID><Name null="true"></Name></row><row><ID null="true"></ID><Name></Name></row></rows>`,
+		},
+		{
+			Name:    "element-names",
+			Columns: []string{"ID"},
+			Data:    [][]any{{int64(1)}},
+			Root:    "accounts",
+			Row:     "account",
+			Want:    `<accounts><account><ID>1</ID></account></accounts>`,
+		},
+		{
+			Name:    "escape",
+			Columns: []string{"Note"},
+			Data:    [][]any{{`<a href="x">&amp;</a>`}},
+			Want:    `<rows><row><Note>&lt;a href=&#34;x&#34;&gt;&amp;amp;&lt;/a&gt;</Note></row></rows>`,
+		},
+		{
+			Name:    "invalid-column",
+			Columns: []string{"Total Due"},
+			Data:    [][]any{{int64(1)}},
+			Error:   `column "Total Due" is not a valid XML element name`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := &Buffer{Columns: item.Columns}
+			for _, row := range item.Data {
+				b.AddRow(row)
+			}
+			bb, err := xml.Marshal(XMLBuffer{Buffer: b, RootElement: item.Root, RowElement: item.Row})
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			if g, w := string(bb), item.Want; g != w {
+				t.Fatalf("got:\n%s\nwant:\n%s", g, w)
+			}
+
+			// Values are read back as text.
+			got := &Buffer{}
+			if err := xml.Unmarshal(bb, got); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := fmt.Sprint(got.Columns), fmt.Sprint(b.Columns); g != w {
+				t.Fatalf("columns got %s want %s", g, w)
+			}
+			for ri, row := range b.Rows {
+				for ci, v := range row.Field {
+					var want any
+					if v != nil {
+						want = fmt.Sprint(v)
+					}
+					if g := got.Rows[ri].Field[ci]; g != want {
+						t.Fatalf("row %d column %d got %#v want %#v", ri, ci, g, want)
+					}
+				}
+			}
+			if g, w := got.Rows[0].Get(b.Columns[0]), got.Rows[0].Field[0]; g != w {
+				t.Fatalf("row get got %#v want %#v", g, w)
+			}
+		})
+	}
+}
+
+func TestXMLUnmarshal(t *testing.T) {
+	list := []struct {
+		Name  string
+		XML   string
+		Want  string
+		Error string
+	}{
+		{
+			// Rows missing a column are padded with NULL.
+			Name: "ragged",
+			XML:  `<list><item><A>1</A></item><item><B>2</B><A>3</A></item></list>`,
+			Want: `[A B] [[1 <nil>] [3 2]]`,
+		},
+		{
+			Name: "empty",
+			XML:  `<rows></rows>`,
+			Want: `[] []`,
+		},
+		{
+			Name:  "malformed",
+			XML:   `<rows><row><A>1</B></row></rows>`,
+			Error: `XML syntax error on line 1: element <A> closed by </B>`,
+		},
+		{
+			Name:  "truncated",
+			XML:   `<rows><row><A>1</A></row>`,
+			Error: `XML syntax error on line 1: unexpected EOF`,
+		},
+	}
+	for _, item := range list {
+		t.Run(item.Name, func(t *testing.T) {
+			b := &Buffer{}
+			err := xml.Unmarshal([]byte(item.XML), b)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if g, w := errs, item.Error; g != w {
+				t.Fatalf("expected error: %s, got error: %s", w, g)
+			}
+			if err != nil {
+				return
+			}
+			var rows [][]any
+			for _, row := range b.Rows {
+				rows = append(rows, row.Field)
+			}
+			if g, w := fmt.Sprint(b.Columns, " ", rows), item.Want; g != w {
+				t.Fatalf("got %s want %s", g, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// The YAML methods use the value based marshaler interfaces understood by the
+// common YAML packages (gopkg.in/yaml.v2 and gopkg.in/yaml.v3), so this package
+// does not need to depend on any of them.
+//
+// A Buffer encodes as a mapping of columns and rows, shown here in flow style:
+//
+//	columns: [ID, Name]
+//	rows:
+//	  - [1, R1]
+//	  - [2, R2]
+//
+// When decoding, a list of column name to value maps is also accepted:
+//
+//	- {ID: 1, Name: R1}
+//	- {ID: 2, Name: R2}
+//
+// Columns decoded from maps are sorted by name, as maps are unordered.
+
+type yamlTable struct {
+	Columns []string `yaml:"columns,flow"`
+	Rows    [][]any  `yaml:"rows"`
+}
+
+// YAMLBuffer encodes a Buffer as YAML in the chosen style.
+type YAMLBuffer struct {
+	*Buffer
+
+	Maps bool // Encode rows as a list of column name to value maps.
+}
+
+// MarshalYAML encodes the buffer as columns and rows.
+func (b *Buffer) MarshalYAML() (any, error) {
+	return YAMLBuffer{Buffer: b}.MarshalYAML()
+}
+
+// UnmarshalYAML decodes the buffer from either columns and rows or a list of maps.
+func (b *Buffer) UnmarshalYAML(unmarshal func(any) error) error {
+	if err := b.mutable(); err != nil {
+		return err
+	}
+	var yt yamlTable
+	err := unmarshal(&yt)
+	if err == nil {
+		b.Columns = yt.Columns
+		b.Rows = nil
+		b.columnNameIndex = nil
+		b.buildIndex()
+		for i, r := range yt.Rows {
+			if len(r) != len(b.Columns) {
+				return fmt.Errorf("row %d has %d fields, expected %d", i, len(r), len(b.Columns))
+			}
+			for fi, f := range r {
+				v, err := fromYAML(f)
+				if err != nil {
+					return fmt.Errorf("row %d, column %q: %w", i, b.Columns[fi], err)
+				}
+				r[fi] = v
+			}
+			b.AddRow(r)
+		}
+		return nil
+	}
+	var maps []map[string]any
+	if mapErr := unmarshal(&maps); mapErr != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	var cols []string
+	for _, m := range maps {
+		for n := range m {
+			if !seen[n] {
+				seen[n] = true
+				cols = append(cols, n)
+			}
+		}
+	}
+	sort.Strings(cols)
+	b.Columns = cols
+	b.Rows = nil
+	b.columnNameIndex = nil
+	b.buildIndex()
+	for ri, m := range maps {
+		r := make([]any, len(cols))
+		for i, n := range cols {
+			v, err := fromYAML(m[n])
+			if err != nil {
+				return fmt.Errorf("row %d, column %q: %w", ri, n, err)
+			}
+			r[i] = v
+		}
+		b.AddRow(r)
+	}
+	return nil
+}
+
+func (yb YAMLBuffer) MarshalYAML() (any, error) {
+	b := yb.Buffer
+	if yb.Maps {
+		list := make([]map[string]any, 0, b.Len())
+		err := b.Each(func(_ int, row Row) error {
+			m := make(map[string]any, len(b.Columns))
+			for i, n := range b.Columns {
+				m[n] = toYAML(row.Field[i])
+			}
+			list = append(list, m)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	yt := yamlTable{
+		Columns: b.Columns,
+		Rows:    make([][]any, 0, b.Len()),
+	}
+	err := b.Each(func(_ int, row Row) error {
+		r := make([]any, len(row.Field))
+		for i, f := range row.Field {
+			r[i] = toYAML(f)
+		}
+		yt.Rows = append(yt.Rows, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return yt, nil
+}
+
+// MarshalYAML encodes the set as a list of buffers.
+func (s Set) MarshalYAML() (any, error) {
+	list := make([]any, len(s))
+	for i, b := range s {
+		v, err := b.MarshalYAML()
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+// UnmarshalYAML decodes the set from a list of buffers.
+func (s *Set) UnmarshalYAML(unmarshal func(any) error) error {
+	var list []*Buffer
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// toYAML converts values that YAML packages would otherwise encode poorly.
+func toYAML(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	default:
+		return v
+	}
+}
+
+// fromYAML converts decoded values to the types database/sql would produce.
+// Integers too large for int64 are an error.
+func fromYAML(v any) (any, error) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("value %d overflows int64", v)
+		}
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/golang-sql/table/zstd
+
+go 1.21
+
+require (
+	github.com/golang-sql/table v0.0.0
+	github.com/klauspost/compress v1.17.11
+)
+
+replace github.com/golang-sql/table => ../
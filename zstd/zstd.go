@@ -0,0 +1,31 @@
+// Package tablezstd registers Zstandard compression for table snapshots as
+// table.CodecZstd. Import it for its side effect:
+//
+//	import _ "github.com/golang-sql/table/zstd"
+//
+// Snapshots are then written with table.Compression(table.CodecZstd), and
+// ReadSnapshot detects them by their frame header.
+package tablezstd
+
+import (
+	"io"
+
+	"github.com/golang-sql/table"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	table.RegisterCodec(table.CodecZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}, newWriter, newReader)
+}
+
+func newWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func newReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
@@ -0,0 +1,52 @@
+package tablezstd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang-sql/table"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestZstd(t *testing.T) {
+	b := &table.Buffer{
+		Columns: []string{"ID", "Text"},
+	}
+	for i := 0; i < 100; i++ {
+		b.AddRow([]any{int64(i), strings.Repeat("text ", 20)})
+	}
+
+	var buf bytes.Buffer
+	if err := b.WriteSnapshot(&buf, table.Compression(table.CodecZstd)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := table.ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got.Len(), got.Get(99, "ID")), "100 99"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+
+	// A snapshot compressed as a whole is detected.
+	buf.Reset()
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteSnapshot(zw); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err = table.ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(got.Len(), got.Get(7, "ID")), "100 7"; g != w {
+		t.Fatalf("got %s want %s", g, w)
+	}
+}